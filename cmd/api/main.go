@@ -9,6 +9,7 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/joho/godotenv"
+	"github.com/kevinbrivio/batako-backend/internal/blob"
 	"github.com/kevinbrivio/batako-backend/internal/handlers"
 	"github.com/kevinbrivio/batako-backend/internal/store"
 	_ "github.com/lib/pq"
@@ -42,9 +43,41 @@ func main() {
     }
     log.Println("Schema set to my_schema")
 
+	// Uploaded files, on local disk unless another blob.Storage is wired in
+	uploadDir := os.Getenv("UPLOAD_DIR")
+	if uploadDir == "" {
+		uploadDir = "uploads"
+	}
+	uploads, err := blob.NewLocalStorage(uploadDir)
+	if err != nil {
+		log.Fatal("Upload storage failed: ", err.Error())
+	}
+
 	storage := store.NewStorage(db)
 	prodHandler := handlers.NewProductionHandler(storage)
 	transactionHandler := handlers.NewTransactionHandler(storage)
+	paymentHandler := handlers.NewPaymentHandler(storage)
+	priceHandler := handlers.NewPriceListHandler(storage)
+	taxRateHandler := handlers.NewTaxRateHandler(storage)
+	customerHandler := handlers.NewCustomerHandler(storage)
+	stockHandler := handlers.NewStockHandler(storage)
+	materialHandler := handlers.NewMaterialHandler(storage)
+	supplierHandler := handlers.NewSupplierHandler(storage)
+	purchaseOrderHandler := handlers.NewPurchaseOrderHandler(storage)
+	reportHandler := handlers.NewReportHandler(storage)
+	invoiceHandler := handlers.NewInvoiceHandler(storage)
+	deliveryHandler := handlers.NewDeliveryHandler(storage)
+	proofHandler := handlers.NewDeliveryProofHandler(storage, uploads)
+	productHandler := handlers.NewProductHandler(storage)
+	qualityHandler := handlers.NewQualityHandler(storage)
+	employeeHandler := handlers.NewEmployeeHandler(storage)
+	payrollHandler := handlers.NewPayrollHandler(storage)
+	attendanceHandler := handlers.NewAttendanceHandler(storage)
+	equipmentHandler := handlers.NewEquipmentHandler(storage)
+	expenseHandler := handlers.NewExpenseHandler(storage, uploads)
+	ledgerHandler := handlers.NewLedgerHandler(storage)
+	cashHandler := handlers.NewCashHandler(storage)
+	bankHandler := handlers.NewBankHandler(storage)
 
 	r := chi.NewRouter()
 	r.Use(middleware.Recoverer)
@@ -60,9 +93,17 @@ func main() {
 		r.Post("/", prodHandler.CreateProduction)
 		r.Get("/", prodHandler.GetAllProductions)
 		r.Get("/monthly", prodHandler.GetProductionMonthly)
+		r.Get("/curing", prodHandler.GetCuringSchedule)
+		r.Get("/yield", prodHandler.GetProductionYield)
 		r.Get("/{id}", prodHandler.GetProduction)
 		r.Put("/{id}", prodHandler.UpdateProduction)
 		r.Delete("/{id}", prodHandler.DeleteProduction)
+		r.Post("/{id}/rejects", prodHandler.AddReject)
+		r.Delete("/{id}/rejects/{rejectID}", prodHandler.DeleteReject)
+		r.Post("/{id}/tests", qualityHandler.CreateTest)
+		r.Get("/{id}/tests", qualityHandler.GetTests)
+		r.Delete("/{id}/tests/{testID}", qualityHandler.DeleteTest)
+		r.Get("/{id}/certificate.pdf", qualityHandler.GetCertificatePDF)
 	})
 	
 	r.Route("/transactions", func(r chi.Router) {
@@ -74,6 +115,208 @@ func main() {
 		r.Get("/{id}", transactionHandler.GetTransaction)
 		r.Put("/{id}", transactionHandler.UpdateTransaction)
 		r.Delete("/{id}", transactionHandler.DeleteTransaction)
+		r.Post("/{id}/payments", paymentHandler.CreatePayment)
+		r.Get("/{id}/payments", paymentHandler.GetPayments)
+		r.Delete("/{id}/payments/{paymentID}", paymentHandler.DeletePayment)
+		r.Post("/{id}/invoice", invoiceHandler.IssueInvoice)
+		r.Get("/{id}/invoice", invoiceHandler.GetInvoice)
+		r.Get("/{id}/invoice.pdf", invoiceHandler.GetInvoicePDF)
+		r.Post("/{id}/deliveries", deliveryHandler.CreateDelivery)
+		r.Get("/{id}/deliveries", deliveryHandler.GetTransactionDeliveries)
+		r.Post("/{id}/proofs", proofHandler.UploadProof)
+		r.Get("/{id}/proofs", proofHandler.GetProofs)
+		r.Get("/{id}/proofs/{proofID}", proofHandler.DownloadProof)
+		r.Delete("/{id}/proofs/{proofID}", proofHandler.DeleteProof)
+		r.Post("/{id}/certificates", qualityHandler.AttachCertificate)
+		r.Get("/{id}/certificates", qualityHandler.GetTransactionCertificates)
+		r.Delete("/{id}/certificates/{productionID}", qualityHandler.DetachCertificate)
+	})
+
+	r.Route("/deliveries", func(r chi.Router) {
+		r.Get("/", deliveryHandler.GetAllDeliveries)
+		r.Get("/{id}", deliveryHandler.GetDelivery)
+		r.Put("/{id}", deliveryHandler.UpdateDelivery)
+		r.Post("/{id}/status", deliveryHandler.UpdateDeliveryStatus)
+		r.Delete("/{id}", deliveryHandler.DeleteDelivery)
+		r.Get("/{id}/note.pdf", deliveryHandler.GetDeliveryNotePDF)
+	})
+
+	r.Route("/products", func(r chi.Router) {
+		r.Post("/", productHandler.CreateProduct)
+		r.Get("/", productHandler.GetAllProducts)
+		r.Get("/{id}", productHandler.GetProduct)
+		r.Put("/{id}", productHandler.UpdateProduct)
+		r.Delete("/{id}", productHandler.DeleteProduct)
+		r.Post("/{id}/piece-rates", productHandler.CreatePieceRate)
+		r.Get("/{id}/piece-rates", productHandler.GetPieceRates)
+		r.Delete("/{id}/piece-rates/{rateID}", productHandler.DeletePieceRate)
+	})
+
+	r.Route("/employees", func(r chi.Router) {
+		r.Post("/", employeeHandler.CreateEmployee)
+		r.Get("/", employeeHandler.GetAllEmployees)
+		r.Get("/{id}", employeeHandler.GetEmployee)
+		r.Put("/{id}", employeeHandler.UpdateEmployee)
+		r.Delete("/{id}", employeeHandler.DeleteEmployee)
+	})
+
+	r.Route("/expenses", func(r chi.Router) {
+		r.Post("/", expenseHandler.CreateExpense)
+		r.Get("/", expenseHandler.GetAllExpenses)
+		r.Get("/daily", expenseHandler.GetExpensesDaily)
+		r.Get("/weekly", expenseHandler.GetExpensesWeekly)
+		r.Get("/monthly", expenseHandler.GetExpensesMonthly)
+		r.Post("/categories", expenseHandler.CreateCategory)
+		r.Get("/categories", expenseHandler.GetCategories)
+		r.Put("/categories/{categoryID}", expenseHandler.UpdateCategory)
+		r.Delete("/categories/{categoryID}", expenseHandler.DeleteCategory)
+		r.Get("/{id}", expenseHandler.GetExpense)
+		r.Put("/{id}", expenseHandler.UpdateExpense)
+		r.Delete("/{id}", expenseHandler.DeleteExpense)
+		r.Put("/{id}/receipt", expenseHandler.UploadReceipt)
+		r.Get("/{id}/receipt", expenseHandler.DownloadReceipt)
+		r.Delete("/{id}/receipt", expenseHandler.DeleteReceipt)
+	})
+
+	r.Route("/equipment", func(r chi.Router) {
+		r.Post("/", equipmentHandler.CreateEquipment)
+		r.Get("/", equipmentHandler.GetAllEquipment)
+		r.Get("/maintenance", equipmentHandler.GetMaintenanceDue)
+		r.Get("/{id}", equipmentHandler.GetEquipment)
+		r.Put("/{id}", equipmentHandler.UpdateEquipment)
+		r.Delete("/{id}", equipmentHandler.DeleteEquipment)
+		r.Post("/{id}/schedules", equipmentHandler.CreateSchedule)
+		r.Put("/{id}/schedules/{scheduleID}", equipmentHandler.UpdateSchedule)
+		r.Delete("/{id}/schedules/{scheduleID}", equipmentHandler.DeleteSchedule)
+		r.Post("/{id}/maintenance", equipmentHandler.LogMaintenance)
+		r.Get("/{id}/maintenance", equipmentHandler.GetMaintenanceLogs)
+	})
+
+	r.Route("/attendance", func(r chi.Router) {
+		r.Post("/check-in", attendanceHandler.CheckIn)
+		r.Get("/daily", attendanceHandler.GetAttendanceDaily)
+		r.Get("/monthly", attendanceHandler.GetAttendanceMonthly)
+		r.Post("/{id}/check-out", attendanceHandler.CheckOut)
+		r.Put("/{id}", attendanceHandler.UpdateAttendance)
+		r.Delete("/{id}", attendanceHandler.DeleteAttendance)
+	})
+
+	r.Route("/payroll", func(r chi.Router) {
+		r.Get("/periods/{period}", payrollHandler.GetPayrollPeriod)
+		r.Post("/periods/{period}/approve", payrollHandler.ApprovePayroll)
+		r.Post("/periods/{period}/pay", payrollHandler.PayPayroll)
+		r.Delete("/periods/{period}", payrollHandler.ReopenPayroll)
+	})
+
+	r.Route("/prices", func(r chi.Router) {
+		r.Post("/", priceHandler.CreatePrice)
+		r.Get("/", priceHandler.GetAllPrices)
+		r.Get("/current", priceHandler.GetCurrentPrice)
+		r.Get("/{id}", priceHandler.GetPrice)
+		r.Put("/{id}", priceHandler.UpdatePrice)
+		r.Delete("/{id}", priceHandler.DeletePrice)
+	})
+
+	r.Route("/tax-rates", func(r chi.Router) {
+		r.Post("/", taxRateHandler.CreateTaxRate)
+		r.Get("/", taxRateHandler.GetAllTaxRates)
+		r.Get("/current", taxRateHandler.GetCurrentTaxRate)
+		r.Get("/{id}", taxRateHandler.GetTaxRate)
+		r.Put("/{id}", taxRateHandler.UpdateTaxRate)
+		r.Delete("/{id}", taxRateHandler.DeleteTaxRate)
+	})
+
+	r.Route("/customers", func(r chi.Router) {
+		r.Post("/", customerHandler.CreateCustomer)
+		r.Get("/", customerHandler.GetAllCustomers)
+		r.Get("/{id}", customerHandler.GetCustomer)
+		r.Put("/{id}", customerHandler.UpdateCustomer)
+		r.Delete("/{id}", customerHandler.DeleteCustomer)
+		r.Post("/{id}/addresses", customerHandler.AddAddress)
+		r.Put("/{id}/addresses/{addressID}", customerHandler.UpdateAddress)
+		r.Delete("/{id}/addresses/{addressID}", customerHandler.DeleteAddress)
+		r.Post("/{id}/phones", customerHandler.AddPhone)
+		r.Delete("/{id}/phones/{phoneID}", customerHandler.DeletePhone)
+	})
+
+	r.Route("/stock", func(r chi.Router) {
+		r.Get("/", stockHandler.GetStock)
+		r.Get("/movements", stockHandler.GetStockMovements)
+	})
+
+	r.Route("/materials", func(r chi.Router) {
+		r.Post("/", materialHandler.CreateMaterial)
+		r.Get("/", materialHandler.GetAllMaterials)
+		r.Get("/low-stock", materialHandler.GetLowStockMaterials)
+		r.Get("/{id}", materialHandler.GetMaterial)
+		r.Put("/{id}", materialHandler.UpdateMaterial)
+		r.Delete("/{id}", materialHandler.DeleteMaterial)
+		r.Post("/{id}/receipts", materialHandler.CreateReceipt)
+		r.Get("/{id}/receipts", materialHandler.GetReceipts)
+		r.Get("/{id}/movements", materialHandler.GetMovements)
+	})
+
+	r.Route("/suppliers", func(r chi.Router) {
+		r.Post("/", supplierHandler.CreateSupplier)
+		r.Get("/", supplierHandler.GetAllSuppliers)
+		r.Get("/{id}", supplierHandler.GetSupplier)
+		r.Put("/{id}", supplierHandler.UpdateSupplier)
+		r.Delete("/{id}", supplierHandler.DeleteSupplier)
+	})
+
+	r.Route("/purchase-orders", func(r chi.Router) {
+		r.Post("/", purchaseOrderHandler.CreatePurchaseOrder)
+		r.Get("/", purchaseOrderHandler.GetAllPurchaseOrders)
+		r.Get("/monthly", purchaseOrderHandler.GetSupplierSpendMonthly)
+		r.Get("/{id}", purchaseOrderHandler.GetPurchaseOrder)
+		r.Put("/{id}", purchaseOrderHandler.UpdatePurchaseOrder)
+		r.Delete("/{id}", purchaseOrderHandler.DeletePurchaseOrder)
+		r.Post("/{id}/cancel", purchaseOrderHandler.CancelPurchaseOrder)
+		r.Post("/{id}/receipts", purchaseOrderHandler.ReceiveGoods)
+	})
+
+	r.Route("/reports", func(r chi.Router) {
+		r.Get("/receivables-aging", reportHandler.GetReceivablesAging)
+		r.Get("/receivables-aging/{customerID}", reportHandler.GetCustomerReceivables)
+		r.Get("/material-variance", reportHandler.GetMaterialVariance)
+		r.Get("/profit-loss", reportHandler.GetProfitLoss)
+	})
+
+	r.Route("/ledger", func(r chi.Router) {
+		r.Get("/accounts", ledgerHandler.GetAccounts)
+		r.Post("/accounts", ledgerHandler.CreateAccount)
+		r.Put("/accounts/{id}", ledgerHandler.UpdateAccount)
+		r.Delete("/accounts/{id}", ledgerHandler.DeleteAccount)
+		r.Get("/accounts/{id}/entries", ledgerHandler.GetAccountLedger)
+		r.Post("/journal", ledgerHandler.CreateEntry)
+		r.Get("/journal", ledgerHandler.GetEntries)
+		r.Get("/journal/{id}", ledgerHandler.GetEntry)
+		r.Post("/journal/{id}/reverse", ledgerHandler.ReverseEntry)
+		r.Get("/trial-balance", ledgerHandler.GetTrialBalance)
+		r.Get("/balance-sheet", ledgerHandler.GetBalanceSheet)
+	})
+
+	r.Route("/cash", func(r chi.Router) {
+		r.Post("/sessions", cashHandler.OpenSession)
+		r.Get("/sessions", cashHandler.GetAllSessions)
+		r.Get("/sessions/{date}", cashHandler.GetSession)
+		r.Delete("/sessions/{date}", cashHandler.DeleteSession)
+		r.Post("/sessions/{date}/close", cashHandler.CloseSession)
+		r.Post("/sessions/{date}/reopen", cashHandler.ReopenSession)
+	})
+
+	r.Route("/bank", func(r chi.Router) {
+		r.Get("/formats", bankHandler.GetFormats)
+		r.Post("/statements", bankHandler.ImportStatement)
+		r.Get("/statements", bankHandler.GetStatements)
+		r.Get("/statements/{id}", bankHandler.GetStatement)
+		r.Delete("/statements/{id}", bankHandler.DeleteStatement)
+		r.Get("/lines", bankHandler.GetLines)
+		r.Get("/lines/{id}/suggestions", bankHandler.GetSuggestions)
+		r.Post("/lines/{id}/confirm", bankHandler.ConfirmMatch)
+		r.Post("/lines/{id}/reject", bankHandler.RejectMatch)
+		r.Post("/lines/{id}/ignore", bankHandler.IgnoreLine)
+		r.Post("/lines/{id}/unmatch", bankHandler.UnmatchLine)
 	})
 	
 	log.Println("Server running at :8080")
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS price_lists(
+    id VARCHAR(36) PRIMARY KEY,
+    unit_price DOUBLE PRECISION NOT NULL,
+    effective_from DATE NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO price_lists (id, unit_price, effective_from)
+VALUES (gen_random_uuid()::text, 1600, COALESCE((SELECT MIN(purchase_date) FROM transactions), CURRENT_DATE));
\ No newline at end of file
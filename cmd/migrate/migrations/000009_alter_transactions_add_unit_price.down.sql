@@ -0,0 +1,2 @@
+ALTER TABLE transactions
+DROP COLUMN unit_price;
\ No newline at end of file
@@ -0,0 +1,8 @@
+ALTER TABLE transactions
+ADD COLUMN unit_price DOUBLE PRECISION;
+
+UPDATE transactions
+SET unit_price = COALESCE(total_price / NULLIF(quantity, 0), 1600);
+
+ALTER TABLE transactions
+ALTER COLUMN unit_price SET NOT NULL;
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS customer_phones;
+DROP TABLE IF EXISTS customer_addresses;
+DROP TABLE IF EXISTS customers;
\ No newline at end of file
@@ -0,0 +1,5 @@
+ALTER TABLE transactions
+DROP COLUMN address_id,
+DROP COLUMN customer_id,
+ALTER COLUMN address TYPE VARCHAR(50) USING left(address, 50),
+ALTER COLUMN customer TYPE VARCHAR(50) USING left(customer, 50);
\ No newline at end of file
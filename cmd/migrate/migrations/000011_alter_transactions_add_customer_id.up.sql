@@ -0,0 +1,43 @@
+ALTER TABLE transactions
+ALTER COLUMN customer TYPE VARCHAR(100),
+ALTER COLUMN address TYPE TEXT,
+ADD COLUMN customer_id VARCHAR(36) REFERENCES customers(id),
+ADD COLUMN address_id VARCHAR(36) REFERENCES customer_addresses(id) ON DELETE SET NULL;
+
+INSERT INTO customers (id, name, normalized_name)
+SELECT DISTINCT ON (normalized_name) gen_random_uuid()::text, name, normalized_name
+FROM (
+    SELECT
+        regexp_replace(btrim(customer), '\s+', ' ', 'g') AS name,
+        lower(regexp_replace(btrim(customer), '\s+', ' ', 'g')) AS normalized_name,
+        purchase_date
+    FROM transactions
+) t
+ORDER BY normalized_name, purchase_date DESC;
+
+UPDATE transactions t
+SET customer_id = c.id, customer = c.name
+FROM customers c
+WHERE c.normalized_name = lower(regexp_replace(btrim(t.customer), '\s+', ' ', 'g'));
+
+INSERT INTO customer_addresses (id, customer_id, address)
+SELECT DISTINCT ON (customer_id, lower(btrim(address))) gen_random_uuid()::text, customer_id, btrim(address)
+FROM transactions
+ORDER BY customer_id, lower(btrim(address)), purchase_date DESC;
+
+UPDATE transactions t
+SET address_id = a.id, address = a.address
+FROM customer_addresses a
+WHERE a.customer_id = t.customer_id AND lower(a.address) = lower(btrim(t.address));
+
+UPDATE customer_addresses a
+SET is_default = TRUE
+FROM (
+    SELECT DISTINCT ON (customer_id) customer_id, address_id
+    FROM transactions
+    ORDER BY customer_id, purchase_date DESC
+) latest
+WHERE a.id = latest.address_id;
+
+ALTER TABLE transactions
+ALTER COLUMN customer_id SET NOT NULL;
\ No newline at end of file
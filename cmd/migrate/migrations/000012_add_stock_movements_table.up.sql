@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS stock_movements(
+    id VARCHAR(36) PRIMARY KEY,
+    movement_date DATE NOT NULL,
+    movement_type VARCHAR(10) NOT NULL,
+    source_type VARCHAR(20) NOT NULL,
+    source_id VARCHAR(36) NOT NULL,
+    quantity INTEGER NOT NULL,
+    note VARCHAR(100) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS stock_movements_source_idx
+ON stock_movements (source_type, source_id);
+
+INSERT INTO stock_movements (id, movement_date, movement_type, source_type, source_id, quantity, note)
+SELECT gen_random_uuid()::text, production_date, 'in', 'production', id, quantity, 'Opening balance'
+FROM productions;
+
+INSERT INTO stock_movements (id, movement_date, movement_type, source_type, source_id, quantity, note)
+SELECT gen_random_uuid()::text, purchase_date, 'out', 'transaction', id, -quantity, 'Opening balance'
+FROM transactions;
\ No newline at end of file
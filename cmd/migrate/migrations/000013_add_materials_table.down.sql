@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS material_movements;
+DROP TABLE IF EXISTS material_receipts;
+DROP TABLE IF EXISTS materials;
\ No newline at end of file
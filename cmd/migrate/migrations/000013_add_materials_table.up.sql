@@ -0,0 +1,40 @@
+CREATE TABLE IF NOT EXISTS materials(
+    id VARCHAR(36) PRIMARY KEY,
+    code VARCHAR(30) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    unit VARCHAR(20) NOT NULL,
+    on_hand DOUBLE PRECISION NOT NULL DEFAULT 0,
+    low_stock_threshold DOUBLE PRECISION NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS material_receipts(
+    id VARCHAR(36) PRIMARY KEY,
+    material_id VARCHAR(36) NOT NULL REFERENCES materials(id),
+    quantity DOUBLE PRECISION NOT NULL,
+    unit_cost DOUBLE PRECISION NOT NULL DEFAULT 0,
+    received_date DATE NOT NULL,
+    note VARCHAR(100) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS material_movements(
+    id VARCHAR(36) PRIMARY KEY,
+    material_id VARCHAR(36) NOT NULL REFERENCES materials(id),
+    movement_date DATE NOT NULL,
+    source_type VARCHAR(20) NOT NULL,
+    source_id VARCHAR(36) NOT NULL,
+    quantity DOUBLE PRECISION NOT NULL,
+    note VARCHAR(100) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS material_movements_source_idx
+ON material_movements (source_type, source_id);
+
+INSERT INTO materials (id, code, name, unit)
+VALUES
+    (gen_random_uuid()::text, 'cement', 'Cement', 'kg'),
+    (gen_random_uuid()::text, 'sand', 'Sand', 'm3');
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS production_materials;
\ No newline at end of file
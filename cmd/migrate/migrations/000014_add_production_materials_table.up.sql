@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS production_materials(
+    id VARCHAR(36) PRIMARY KEY,
+    production_id VARCHAR(36) NOT NULL REFERENCES productions(id) ON DELETE CASCADE,
+    material_id VARCHAR(36) NOT NULL REFERENCES materials(id),
+    quantity DOUBLE PRECISION NOT NULL
+);
\ No newline at end of file
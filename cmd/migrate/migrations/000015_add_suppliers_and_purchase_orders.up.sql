@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS suppliers(
+    id VARCHAR(36) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    normalized_name VARCHAR(100) NOT NULL UNIQUE,
+    phone VARCHAR(30) NOT NULL DEFAULT '',
+    address TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS purchase_orders(
+    id VARCHAR(36) PRIMARY KEY,
+    supplier_id VARCHAR(36) NOT NULL REFERENCES suppliers(id),
+    order_date DATE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    note VARCHAR(255) NOT NULL DEFAULT '',
+    total_amount DOUBLE PRECISION NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS purchase_order_items(
+    id VARCHAR(36) PRIMARY KEY,
+    purchase_order_id VARCHAR(36) NOT NULL REFERENCES purchase_orders(id) ON DELETE CASCADE,
+    material_id VARCHAR(36) NOT NULL REFERENCES materials(id),
+    quantity DOUBLE PRECISION NOT NULL,
+    unit_cost DOUBLE PRECISION NOT NULL,
+    received_quantity DOUBLE PRECISION NOT NULL DEFAULT 0
+);
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE material_receipts
+DROP COLUMN purchase_order_item_id,
+DROP COLUMN supplier_id;
\ No newline at end of file
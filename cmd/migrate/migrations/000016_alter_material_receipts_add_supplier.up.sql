@@ -0,0 +1,3 @@
+ALTER TABLE material_receipts
+ADD COLUMN supplier_id VARCHAR(36) REFERENCES suppliers(id),
+ADD COLUMN purchase_order_item_id VARCHAR(36) REFERENCES purchase_order_items(id);
\ No newline at end of file
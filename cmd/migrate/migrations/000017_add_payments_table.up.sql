@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS payments(
+    id VARCHAR(36) PRIMARY KEY,
+    transaction_id VARCHAR(36) NOT NULL REFERENCES transactions(id),
+    amount DOUBLE PRECISION NOT NULL,
+    payment_date DATE NOT NULL,
+    method VARCHAR(20) NOT NULL,
+    reference VARCHAR(100) NOT NULL DEFAULT '',
+    note VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS payments_transaction_id_idx
+ON payments (transaction_id);
\ No newline at end of file
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS invoice_sequences(
+    period CHAR(7) PRIMARY KEY,
+    last_number INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS invoices(
+    id VARCHAR(36) PRIMARY KEY,
+    transaction_id VARCHAR(36) NOT NULL UNIQUE REFERENCES transactions(id),
+    invoice_number VARCHAR(30) NOT NULL UNIQUE,
+    issue_date DATE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
\ No newline at end of file
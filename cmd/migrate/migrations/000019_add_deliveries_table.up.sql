@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS delivery_sequences(
+    period CHAR(7) PRIMARY KEY,
+    last_number INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS deliveries(
+    id VARCHAR(36) PRIMARY KEY,
+    delivery_number VARCHAR(30) NOT NULL UNIQUE,
+    transaction_id VARCHAR(36) NOT NULL REFERENCES transactions(id),
+    scheduled_date DATE NOT NULL,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    vehicle VARCHAR(50) NOT NULL DEFAULT '',
+    driver VARCHAR(100) NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'scheduled',
+    note VARCHAR(255) NOT NULL DEFAULT '',
+    loaded_at TIMESTAMP WITH TIME ZONE,
+    delivered_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS deliveries_transaction_id_idx
+ON deliveries (transaction_id);
+
+CREATE INDEX IF NOT EXISTS deliveries_scheduled_date_idx
+ON deliveries (scheduled_date);
\ No newline at end of file
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS delivery_proofs(
+    id VARCHAR(36) PRIMARY KEY,
+    transaction_id VARCHAR(36) NOT NULL REFERENCES transactions(id),
+    delivery_id VARCHAR(36) REFERENCES deliveries(id),
+    kind VARCHAR(20) NOT NULL,
+    file_name VARCHAR(255) NOT NULL DEFAULT '',
+    content_type VARCHAR(50) NOT NULL,
+    size BIGINT NOT NULL,
+    storage_key VARCHAR(255) NOT NULL,
+    thumbnail_key VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS delivery_proofs_transaction_id_idx
+ON delivery_proofs (transaction_id);
\ No newline at end of file
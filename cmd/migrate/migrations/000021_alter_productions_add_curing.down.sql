@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS productions_ready_date_idx;
+
+ALTER TABLE productions
+DROP COLUMN IF EXISTS ready_date;
+
+ALTER TABLE productions
+DROP COLUMN IF EXISTS curing_days;
\ No newline at end of file
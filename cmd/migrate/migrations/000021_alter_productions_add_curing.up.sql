@@ -0,0 +1,8 @@
+ALTER TABLE productions
+ADD COLUMN curing_days INTEGER NOT NULL DEFAULT 7 CHECK (curing_days >= 0);
+
+ALTER TABLE productions
+ADD COLUMN ready_date DATE GENERATED ALWAYS AS (production_date + curing_days) STORED;
+
+CREATE INDEX IF NOT EXISTS productions_ready_date_idx
+ON productions (ready_date);
\ No newline at end of file
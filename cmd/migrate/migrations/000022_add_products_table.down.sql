@@ -0,0 +1,25 @@
+ALTER TABLE price_lists
+DROP CONSTRAINT IF EXISTS price_lists_product_id_effective_from_key;
+
+DELETE FROM price_lists
+WHERE product_id <> (SELECT id FROM products WHERE is_default);
+
+ALTER TABLE price_lists
+ADD CONSTRAINT price_lists_effective_from_key UNIQUE (effective_from);
+
+ALTER TABLE price_lists
+DROP COLUMN IF EXISTS product_id;
+
+DROP INDEX IF EXISTS stock_movements_product_id_idx;
+
+ALTER TABLE stock_movements
+DROP COLUMN IF EXISTS product_id;
+
+ALTER TABLE transactions
+DROP COLUMN IF EXISTS product_id;
+
+ALTER TABLE productions
+DROP COLUMN IF EXISTS product_id;
+
+DROP TABLE IF EXISTS product_recipes;
+DROP TABLE IF EXISTS products;
\ No newline at end of file
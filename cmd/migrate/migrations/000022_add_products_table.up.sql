@@ -0,0 +1,74 @@
+CREATE TABLE IF NOT EXISTS products(
+    id VARCHAR(36) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    length_mm INTEGER NOT NULL DEFAULT 0,
+    width_mm INTEGER NOT NULL DEFAULT 0,
+    height_mm INTEGER NOT NULL DEFAULT 0,
+    unit VARCHAR(20) NOT NULL DEFAULT 'pcs',
+    default_price DOUBLE PRECISION NOT NULL DEFAULT 0,
+    curing_days INTEGER NOT NULL DEFAULT 7 CHECK (curing_days >= 0),
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS products_is_default_idx
+ON products (is_default) WHERE is_default;
+
+CREATE TABLE IF NOT EXISTS product_recipes(
+    product_id VARCHAR(36) NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    material_id VARCHAR(36) NOT NULL REFERENCES materials(id),
+    quantity_per_unit DOUBLE PRECISION NOT NULL,
+    PRIMARY KEY (product_id, material_id)
+);
+
+INSERT INTO products (id, name, unit, default_price, curing_days, is_default)
+VALUES (
+    gen_random_uuid()::text,
+    'Batako',
+    'pcs',
+    COALESCE((SELECT unit_price FROM price_lists ORDER BY effective_from DESC LIMIT 1), 0),
+    7,
+    TRUE
+);
+
+ALTER TABLE productions
+ADD COLUMN product_id VARCHAR(36) REFERENCES products(id);
+
+UPDATE productions SET product_id = (SELECT id FROM products WHERE is_default);
+
+ALTER TABLE productions
+ALTER COLUMN product_id SET NOT NULL;
+
+ALTER TABLE transactions
+ADD COLUMN product_id VARCHAR(36) REFERENCES products(id);
+
+UPDATE transactions SET product_id = (SELECT id FROM products WHERE is_default);
+
+ALTER TABLE transactions
+ALTER COLUMN product_id SET NOT NULL;
+
+ALTER TABLE stock_movements
+ADD COLUMN product_id VARCHAR(36) REFERENCES products(id);
+
+UPDATE stock_movements SET product_id = (SELECT id FROM products WHERE is_default);
+
+ALTER TABLE stock_movements
+ALTER COLUMN product_id SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS stock_movements_product_id_idx
+ON stock_movements (product_id);
+
+ALTER TABLE price_lists
+ADD COLUMN product_id VARCHAR(36) REFERENCES products(id) ON DELETE CASCADE;
+
+UPDATE price_lists SET product_id = (SELECT id FROM products WHERE is_default);
+
+ALTER TABLE price_lists
+ALTER COLUMN product_id SET NOT NULL;
+
+ALTER TABLE price_lists
+DROP CONSTRAINT IF EXISTS price_lists_effective_from_key;
+
+ALTER TABLE price_lists
+ADD CONSTRAINT price_lists_product_id_effective_from_key UNIQUE (product_id, effective_from);
\ No newline at end of file
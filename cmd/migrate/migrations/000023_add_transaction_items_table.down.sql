@@ -0,0 +1,33 @@
+ALTER TABLE transactions
+ADD COLUMN product_id VARCHAR(36) REFERENCES products(id);
+
+ALTER TABLE transactions
+ADD COLUMN unit_price DOUBLE PRECISION;
+
+UPDATE transactions t
+SET product_id = i.product_id, unit_price = i.unit_price
+FROM (
+    SELECT DISTINCT ON (transaction_id) transaction_id, product_id, unit_price
+    FROM transaction_items
+    WHERE product_id IS NOT NULL
+    ORDER BY transaction_id, line_number
+) i
+WHERE i.transaction_id = t.id;
+
+UPDATE transactions
+SET product_id = (SELECT id FROM products WHERE is_default), unit_price = 0
+WHERE product_id IS NULL;
+
+ALTER TABLE transactions
+ALTER COLUMN product_id SET NOT NULL;
+
+ALTER TABLE transactions
+ALTER COLUMN unit_price SET NOT NULL;
+
+DROP INDEX IF EXISTS deliveries_transaction_item_id_idx;
+
+ALTER TABLE deliveries
+DROP COLUMN IF EXISTS transaction_item_id;
+
+DROP INDEX IF EXISTS transaction_items_product_id_idx;
+DROP TABLE IF EXISTS transaction_items;
\ No newline at end of file
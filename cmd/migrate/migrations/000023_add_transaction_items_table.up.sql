@@ -0,0 +1,41 @@
+CREATE TABLE IF NOT EXISTS transaction_items(
+    id VARCHAR(36) PRIMARY KEY,
+    transaction_id VARCHAR(36) NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    line_number INTEGER NOT NULL,
+    product_id VARCHAR(36) REFERENCES products(id),
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    unit_price DOUBLE PRECISION NOT NULL,
+    discount DOUBLE PRECISION NOT NULL DEFAULT 0 CHECK (discount >= 0),
+    line_total DOUBLE PRECISION NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (transaction_id, line_number)
+);
+
+CREATE INDEX IF NOT EXISTS transaction_items_product_id_idx
+ON transaction_items (product_id);
+
+INSERT INTO transaction_items (id, transaction_id, line_number, product_id, quantity, unit_price, line_total)
+SELECT gen_random_uuid()::text, id, 1, product_id, quantity, unit_price, total_price
+FROM transactions;
+
+ALTER TABLE deliveries
+ADD COLUMN transaction_item_id VARCHAR(36) REFERENCES transaction_items(id);
+
+UPDATE deliveries d
+SET transaction_item_id = i.id
+FROM transaction_items i
+WHERE i.transaction_id = d.transaction_id;
+
+ALTER TABLE deliveries
+ALTER COLUMN transaction_item_id SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS deliveries_transaction_item_id_idx
+ON deliveries (transaction_item_id);
+
+ALTER TABLE transactions
+DROP COLUMN product_id;
+
+ALTER TABLE transactions
+DROP COLUMN unit_price;
\ No newline at end of file
@@ -0,0 +1,4 @@
+DELETE FROM materials m
+WHERE m.code = 'water'
+    AND NOT EXISTS (SELECT 1 FROM material_movements WHERE material_id = m.id)
+    AND NOT EXISTS (SELECT 1 FROM product_recipes WHERE material_id = m.id);
\ No newline at end of file
@@ -0,0 +1,3 @@
+INSERT INTO materials (id, code, name, unit)
+VALUES (gen_random_uuid()::text, 'water', 'Water', 'l')
+ON CONFLICT (code) DO NOTHING;
\ No newline at end of file
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS production_rejects_production_id_idx;
+DROP TABLE IF EXISTS production_rejects;
\ No newline at end of file
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS production_rejects(
+    id VARCHAR(36) PRIMARY KEY,
+    production_id VARCHAR(36) NOT NULL REFERENCES productions(id) ON DELETE CASCADE,
+    reason VARCHAR(20) NOT NULL CHECK (reason IN ('cracked', 'underweight', 'malformed', 'other')),
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    reject_date DATE NOT NULL,
+    note VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS production_rejects_production_id_idx
+ON production_rejects (production_id);
\ No newline at end of file
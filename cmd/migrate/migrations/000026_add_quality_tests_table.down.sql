@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS transaction_certificates;
+
+DROP INDEX IF EXISTS quality_tests_production_id_idx;
+DROP TABLE IF EXISTS quality_tests;
+
+ALTER TABLE products
+DROP COLUMN IF EXISTS min_strength;
+
+ALTER TABLE products
+DROP COLUMN IF EXISTS strength_class;
\ No newline at end of file
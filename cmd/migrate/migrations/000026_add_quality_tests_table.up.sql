@@ -0,0 +1,32 @@
+ALTER TABLE products
+ADD COLUMN strength_class VARCHAR(50) NOT NULL DEFAULT '';
+
+ALTER TABLE products
+ADD COLUMN min_strength DOUBLE PRECISION NOT NULL DEFAULT 0 CHECK (min_strength >= 0);
+
+CREATE TABLE IF NOT EXISTS quality_tests(
+    id VARCHAR(36) PRIMARY KEY,
+    production_id VARCHAR(36) NOT NULL REFERENCES productions(id),
+    sample_code VARCHAR(50) NOT NULL DEFAULT '',
+    test_date DATE NOT NULL,
+    age_days INTEGER NOT NULL,
+    load_kn DOUBLE PRECISION NOT NULL CHECK (load_kn > 0),
+    area_mm2 DOUBLE PRECISION NOT NULL CHECK (area_mm2 > 0),
+    strength_mpa DOUBLE PRECISION NOT NULL,
+    strength_class VARCHAR(50) NOT NULL,
+    min_strength DOUBLE PRECISION NOT NULL,
+    passed BOOLEAN NOT NULL,
+    tested_by VARCHAR(100) NOT NULL DEFAULT '',
+    note VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS quality_tests_production_id_idx
+ON quality_tests (production_id);
+
+CREATE TABLE IF NOT EXISTS transaction_certificates(
+    transaction_id VARCHAR(36) NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    production_id VARCHAR(36) NOT NULL REFERENCES productions(id),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (transaction_id, production_id)
+);
\ No newline at end of file
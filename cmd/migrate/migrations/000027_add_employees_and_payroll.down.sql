@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS payroll_lines;
+DROP TABLE IF EXISTS payroll_runs;
+
+DROP INDEX IF EXISTS production_crew_employee_id_idx;
+DROP TABLE IF EXISTS production_crew;
+
+DROP TABLE IF EXISTS piece_rates;
+DROP TABLE IF EXISTS employees;
\ No newline at end of file
@@ -0,0 +1,49 @@
+CREATE TABLE IF NOT EXISTS employees(
+    id VARCHAR(36) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    phone VARCHAR(30) NOT NULL DEFAULT '',
+    role VARCHAR(50) NOT NULL DEFAULT '',
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS piece_rates(
+    id VARCHAR(36) PRIMARY KEY,
+    product_id VARCHAR(36) NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    rate DOUBLE PRECISION NOT NULL CHECK (rate >= 0),
+    effective_from DATE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (product_id, effective_from)
+);
+
+CREATE TABLE IF NOT EXISTS production_crew(
+    production_id VARCHAR(36) NOT NULL REFERENCES productions(id) ON DELETE CASCADE,
+    employee_id VARCHAR(36) NOT NULL REFERENCES employees(id),
+    PRIMARY KEY (production_id, employee_id)
+);
+
+CREATE INDEX IF NOT EXISTS production_crew_employee_id_idx
+ON production_crew (employee_id);
+
+CREATE TABLE IF NOT EXISTS payroll_runs(
+    id VARCHAR(36) PRIMARY KEY,
+    period VARCHAR(10) NOT NULL UNIQUE,
+    period_start DATE NOT NULL,
+    period_end DATE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'approved',
+    total DOUBLE PRECISION NOT NULL,
+    unassigned_batches INTEGER NOT NULL DEFAULT 0,
+    approved_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    paid_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE TABLE IF NOT EXISTS payroll_lines(
+    run_id VARCHAR(36) NOT NULL REFERENCES payroll_runs(id) ON DELETE CASCADE,
+    employee_id VARCHAR(36) NOT NULL REFERENCES employees(id),
+    employee VARCHAR(100) NOT NULL,
+    batches INTEGER NOT NULL,
+    quantity DOUBLE PRECISION NOT NULL,
+    amount DOUBLE PRECISION NOT NULL,
+    PRIMARY KEY (run_id, employee_id)
+);
\ No newline at end of file
@@ -0,0 +1,12 @@
+ALTER TABLE payroll_lines
+DROP COLUMN IF EXISTS wage_amount,
+DROP COLUMN IF EXISTS overtime_hours,
+DROP COLUMN IF EXISTS days,
+DROP COLUMN IF EXISTS piece_amount;
+
+DROP INDEX IF EXISTS attendance_work_date_idx;
+DROP TABLE IF EXISTS attendance;
+
+ALTER TABLE employees
+DROP COLUMN IF EXISTS overtime_rate,
+DROP COLUMN IF EXISTS daily_wage;
\ No newline at end of file
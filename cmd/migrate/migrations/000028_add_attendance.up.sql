@@ -0,0 +1,29 @@
+ALTER TABLE employees
+ADD COLUMN daily_wage DOUBLE PRECISION NOT NULL DEFAULT 0 CHECK (daily_wage >= 0),
+ADD COLUMN overtime_rate DOUBLE PRECISION NOT NULL DEFAULT 0 CHECK (overtime_rate >= 0);
+
+CREATE TABLE IF NOT EXISTS attendance(
+    id VARCHAR(36) PRIMARY KEY,
+    employee_id VARCHAR(36) NOT NULL REFERENCES employees(id),
+    work_date DATE NOT NULL,
+    shift VARCHAR(20) NOT NULL CHECK (shift IN ('day', 'night', 'half')),
+    check_in TIMESTAMP WITH TIME ZONE NOT NULL,
+    check_out TIMESTAMP WITH TIME ZONE,
+    overtime_hours DOUBLE PRECISION NOT NULL DEFAULT 0 CHECK (overtime_hours >= 0),
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (employee_id, work_date),
+    CHECK (check_out IS NULL OR check_out > check_in)
+);
+
+CREATE INDEX IF NOT EXISTS attendance_work_date_idx
+ON attendance (work_date);
+
+ALTER TABLE payroll_lines
+ADD COLUMN piece_amount DOUBLE PRECISION NOT NULL DEFAULT 0,
+ADD COLUMN days DOUBLE PRECISION NOT NULL DEFAULT 0,
+ADD COLUMN overtime_hours DOUBLE PRECISION NOT NULL DEFAULT 0,
+ADD COLUMN wage_amount DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+UPDATE payroll_lines SET piece_amount = amount;
\ No newline at end of file
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS maintenance_logs_equipment_id_idx;
+DROP TABLE IF EXISTS maintenance_logs;
+
+DROP TABLE IF EXISTS maintenance_schedules;
+
+DROP INDEX IF EXISTS production_equipment_equipment_id_idx;
+DROP TABLE IF EXISTS production_equipment;
+
+DROP TABLE IF EXISTS equipment;
\ No newline at end of file
@@ -0,0 +1,49 @@
+CREATE TABLE IF NOT EXISTS equipment(
+    id VARCHAR(36) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('press', 'mold', 'mixer', 'other')),
+    blocks_per_cycle INTEGER NOT NULL DEFAULT 1 CHECK (blocks_per_cycle > 0),
+    initial_blocks INTEGER NOT NULL DEFAULT 0 CHECK (initial_blocks >= 0),
+    commissioned_date DATE NOT NULL DEFAULT CURRENT_DATE,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS production_equipment(
+    production_id VARCHAR(36) NOT NULL REFERENCES productions(id) ON DELETE CASCADE,
+    equipment_id VARCHAR(36) NOT NULL REFERENCES equipment(id),
+    PRIMARY KEY (production_id, equipment_id)
+);
+
+CREATE INDEX IF NOT EXISTS production_equipment_equipment_id_idx
+ON production_equipment (equipment_id);
+
+CREATE TABLE IF NOT EXISTS maintenance_schedules(
+    id VARCHAR(36) PRIMARY KEY,
+    equipment_id VARCHAR(36) NOT NULL REFERENCES equipment(id) ON DELETE CASCADE,
+    task VARCHAR(200) NOT NULL,
+    interval_blocks INTEGER NOT NULL DEFAULT 0 CHECK (interval_blocks >= 0),
+    interval_days INTEGER NOT NULL DEFAULT 0 CHECK (interval_days >= 0),
+    last_done_date DATE NOT NULL,
+    last_done_blocks INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CHECK (interval_blocks > 0 OR interval_days > 0)
+);
+
+CREATE TABLE IF NOT EXISTS maintenance_logs(
+    id VARCHAR(36) PRIMARY KEY,
+    equipment_id VARCHAR(36) NOT NULL REFERENCES equipment(id) ON DELETE CASCADE,
+    schedule_id VARCHAR(36) REFERENCES maintenance_schedules(id) ON DELETE SET NULL,
+    task VARCHAR(200) NOT NULL,
+    performed_date DATE NOT NULL,
+    blocks_at INTEGER NOT NULL,
+    cost DOUBLE PRECISION NOT NULL DEFAULT 0 CHECK (cost >= 0),
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS maintenance_logs_equipment_id_idx
+ON maintenance_logs (equipment_id, performed_date);
\ No newline at end of file
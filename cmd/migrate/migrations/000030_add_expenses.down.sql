@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS expenses_expense_date_idx;
+DROP TABLE IF EXISTS expenses;
+
+DROP TABLE IF EXISTS expense_categories;
\ No newline at end of file
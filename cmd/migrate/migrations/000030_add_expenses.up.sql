@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS expense_categories(
+    id VARCHAR(36) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO expense_categories (id, name)
+VALUES
+    (gen_random_uuid()::text, 'Diesel'),
+    (gen_random_uuid()::text, 'Electricity'),
+    (gen_random_uuid()::text, 'Wages'),
+    (gen_random_uuid()::text, 'Repairs'),
+    (gen_random_uuid()::text, 'Other')
+ON CONFLICT (name) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS expenses(
+    id VARCHAR(36) PRIMARY KEY,
+    category_id VARCHAR(36) NOT NULL REFERENCES expense_categories(id),
+    supplier_id VARCHAR(36) REFERENCES suppliers(id),
+    expense_date DATE NOT NULL,
+    amount DOUBLE PRECISION NOT NULL CHECK (amount > 0),
+    description TEXT NOT NULL DEFAULT '',
+    receipt_file_name VARCHAR(255) NOT NULL DEFAULT '',
+    receipt_content_type VARCHAR(100) NOT NULL DEFAULT '',
+    receipt_key VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS expenses_expense_date_idx
+ON expenses (expense_date);
\ No newline at end of file
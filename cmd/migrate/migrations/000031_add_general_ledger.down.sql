@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS journal_lines_account_id_idx;
+DROP INDEX IF EXISTS journal_lines_entry_id_idx;
+DROP TABLE IF EXISTS journal_lines;
+
+DROP INDEX IF EXISTS journal_entries_entry_date_idx;
+DROP INDEX IF EXISTS journal_entries_source_idx;
+DROP TABLE IF EXISTS journal_entries;
+
+ALTER TABLE expense_categories
+DROP COLUMN IF EXISTS account_id;
+
+DROP TABLE IF EXISTS accounts;
\ No newline at end of file
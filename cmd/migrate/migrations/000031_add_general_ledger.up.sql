@@ -0,0 +1,139 @@
+CREATE TABLE IF NOT EXISTS accounts(
+    id VARCHAR(36) PRIMARY KEY,
+    code VARCHAR(10) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('asset', 'liability', 'equity', 'revenue', 'expense')),
+    is_system BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO accounts (id, code, name, type, is_system)
+VALUES
+    (gen_random_uuid()::text, '1100', 'Cash', 'asset', TRUE),
+    (gen_random_uuid()::text, '1110', 'Bank', 'asset', TRUE),
+    (gen_random_uuid()::text, '1200', 'Accounts Receivable', 'asset', TRUE),
+    (gen_random_uuid()::text, '1300', 'Raw Materials Inventory', 'asset', TRUE),
+    (gen_random_uuid()::text, '2100', 'Accounts Payable', 'liability', TRUE),
+    (gen_random_uuid()::text, '3100', 'Owner''s Capital', 'equity', FALSE),
+    (gen_random_uuid()::text, '3200', 'Retained Earnings', 'equity', TRUE),
+    (gen_random_uuid()::text, '4100', 'Sales Revenue', 'revenue', TRUE),
+    (gen_random_uuid()::text, '6100', 'Fuel', 'expense', FALSE),
+    (gen_random_uuid()::text, '6200', 'Electricity', 'expense', FALSE),
+    (gen_random_uuid()::text, '6300', 'Wages', 'expense', FALSE),
+    (gen_random_uuid()::text, '6400', 'Repairs and Maintenance', 'expense', FALSE),
+    (gen_random_uuid()::text, '6900', 'Other Expenses', 'expense', TRUE)
+ON CONFLICT (code) DO NOTHING;
+
+ALTER TABLE expense_categories
+ADD COLUMN account_id VARCHAR(36) REFERENCES accounts(id);
+
+UPDATE expense_categories c
+SET account_id = a.id
+FROM accounts a
+WHERE a.code = CASE c.name
+    WHEN 'Diesel' THEN '6100'
+    WHEN 'Electricity' THEN '6200'
+    WHEN 'Wages' THEN '6300'
+    WHEN 'Repairs' THEN '6400'
+    ELSE '6900'
+END;
+
+CREATE TABLE IF NOT EXISTS journal_entries(
+    id VARCHAR(36) PRIMARY KEY,
+    entry_date DATE NOT NULL,
+    source_type VARCHAR(20) NOT NULL,
+    source_id VARCHAR(36) NOT NULL DEFAULT '',
+    description VARCHAR(200) NOT NULL DEFAULT '',
+    reversal_of VARCHAR(36) UNIQUE REFERENCES journal_entries(id),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS journal_entries_source_idx
+ON journal_entries (source_type, source_id);
+
+CREATE INDEX IF NOT EXISTS journal_entries_entry_date_idx
+ON journal_entries (entry_date);
+
+CREATE TABLE IF NOT EXISTS journal_lines(
+    id VARCHAR(36) PRIMARY KEY,
+    entry_id VARCHAR(36) NOT NULL REFERENCES journal_entries(id) ON DELETE CASCADE,
+    account_id VARCHAR(36) NOT NULL REFERENCES accounts(id),
+    debit DOUBLE PRECISION NOT NULL DEFAULT 0 CHECK (debit >= 0),
+    credit DOUBLE PRECISION NOT NULL DEFAULT 0 CHECK (credit >= 0),
+    CHECK (debit = 0 OR credit = 0)
+);
+
+CREATE INDEX IF NOT EXISTS journal_lines_entry_id_idx
+ON journal_lines (entry_id);
+
+CREATE INDEX IF NOT EXISTS journal_lines_account_id_idx
+ON journal_lines (account_id);
+
+INSERT INTO journal_entries (id, entry_date, source_type, source_id, description)
+SELECT gen_random_uuid()::text, purchase_date, 'transaction', id, 'Sale to ' || customer
+FROM transactions
+WHERE total_price > 0;
+
+INSERT INTO journal_entries (id, entry_date, source_type, source_id, description)
+SELECT gen_random_uuid()::text, payment_date, 'payment', id, 'Payment received'
+FROM payments;
+
+INSERT INTO journal_entries (id, entry_date, source_type, source_id, description)
+SELECT gen_random_uuid()::text, r.received_date, 'receipt', r.id, 'Received ' || m.name
+FROM material_receipts r
+JOIN materials m ON m.id = r.material_id
+WHERE r.quantity * r.unit_cost > 0;
+
+INSERT INTO journal_entries (id, entry_date, source_type, source_id, description)
+SELECT gen_random_uuid()::text, x.expense_date, 'expense', x.id, c.name || ' expense'
+FROM expenses x
+JOIN expense_categories c ON c.id = x.category_id;
+
+INSERT INTO journal_lines (id, entry_id, account_id, debit, credit)
+SELECT gen_random_uuid()::text, e.id, a.id, t.total_price, 0
+FROM journal_entries e
+JOIN transactions t ON e.source_type = 'transaction' AND t.id = e.source_id
+JOIN accounts a ON a.code = '1200';
+
+INSERT INTO journal_lines (id, entry_id, account_id, debit, credit)
+SELECT gen_random_uuid()::text, e.id, a.id, 0, t.total_price
+FROM journal_entries e
+JOIN transactions t ON e.source_type = 'transaction' AND t.id = e.source_id
+JOIN accounts a ON a.code = '4100';
+
+INSERT INTO journal_lines (id, entry_id, account_id, debit, credit)
+SELECT gen_random_uuid()::text, e.id, a.id, p.amount, 0
+FROM journal_entries e
+JOIN payments p ON e.source_type = 'payment' AND p.id = e.source_id
+JOIN accounts a ON a.code = CASE WHEN p.method = 'transfer' THEN '1110' ELSE '1100' END;
+
+INSERT INTO journal_lines (id, entry_id, account_id, debit, credit)
+SELECT gen_random_uuid()::text, e.id, a.id, 0, p.amount
+FROM journal_entries e
+JOIN payments p ON e.source_type = 'payment' AND p.id = e.source_id
+JOIN accounts a ON a.code = '1200';
+
+INSERT INTO journal_lines (id, entry_id, account_id, debit, credit)
+SELECT gen_random_uuid()::text, e.id, a.id, r.quantity * r.unit_cost, 0
+FROM journal_entries e
+JOIN material_receipts r ON e.source_type = 'receipt' AND r.id = e.source_id
+JOIN accounts a ON a.code = '1300';
+
+INSERT INTO journal_lines (id, entry_id, account_id, debit, credit)
+SELECT gen_random_uuid()::text, e.id, a.id, 0, r.quantity * r.unit_cost
+FROM journal_entries e
+JOIN material_receipts r ON e.source_type = 'receipt' AND r.id = e.source_id
+JOIN accounts a ON a.code = CASE WHEN r.supplier_id IS NULL THEN '1100' ELSE '2100' END;
+
+INSERT INTO journal_lines (id, entry_id, account_id, debit, credit)
+SELECT gen_random_uuid()::text, e.id, c.account_id, x.amount, 0
+FROM journal_entries e
+JOIN expenses x ON e.source_type = 'expense' AND x.id = e.source_id
+JOIN expense_categories c ON c.id = x.category_id;
+
+INSERT INTO journal_lines (id, entry_id, account_id, debit, credit)
+SELECT gen_random_uuid()::text, e.id, a.id, 0, x.amount
+FROM journal_entries e
+JOIN expenses x ON e.source_type = 'expense' AND x.id = e.source_id
+JOIN accounts a ON a.code = '1100';
\ No newline at end of file
@@ -0,0 +1,8 @@
+DELETE FROM accounts a
+WHERE a.code = '6950' AND NOT EXISTS (SELECT 1 FROM journal_lines l WHERE l.account_id = a.id);
+
+DROP INDEX IF EXISTS cash_movements_source_idx;
+DROP INDEX IF EXISTS cash_movements_movement_date_idx;
+DROP TABLE IF EXISTS cash_movements;
+
+DROP TABLE IF EXISTS cash_sessions;
\ No newline at end of file
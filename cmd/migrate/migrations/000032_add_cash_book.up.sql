@@ -0,0 +1,44 @@
+CREATE TABLE IF NOT EXISTS cash_sessions(
+    id VARCHAR(36) PRIMARY KEY,
+    session_date DATE NOT NULL UNIQUE,
+    status VARCHAR(10) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'closed')),
+    opening_float DOUBLE PRECISION NOT NULL DEFAULT 0 CHECK (opening_float >= 0),
+    expected_cash DOUBLE PRECISION NOT NULL DEFAULT 0,
+    counted_cash DOUBLE PRECISION NOT NULL DEFAULT 0,
+    discrepancy DOUBLE PRECISION NOT NULL DEFAULT 0,
+    note VARCHAR(200) NOT NULL DEFAULT '',
+    closed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS cash_movements(
+    id VARCHAR(36) PRIMARY KEY,
+    movement_date DATE NOT NULL,
+    source_type VARCHAR(20) NOT NULL,
+    source_id VARCHAR(36) NOT NULL,
+    amount DOUBLE PRECISION NOT NULL,
+    description VARCHAR(200) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS cash_movements_movement_date_idx
+ON cash_movements (movement_date);
+
+CREATE INDEX IF NOT EXISTS cash_movements_source_idx
+ON cash_movements (source_type, source_id);
+
+INSERT INTO cash_movements (id, movement_date, source_type, source_id, amount, description)
+SELECT gen_random_uuid()::text, p.payment_date, 'payment', p.id, p.amount, 'Payment from ' || t.customer
+FROM payments p
+JOIN transactions t ON t.id = p.transaction_id
+WHERE p.method = 'cash';
+
+INSERT INTO cash_movements (id, movement_date, source_type, source_id, amount, description)
+SELECT gen_random_uuid()::text, x.expense_date, 'expense', x.id, -x.amount, c.name || ' expense'
+FROM expenses x
+JOIN expense_categories c ON c.id = x.category_id;
+
+INSERT INTO accounts (id, code, name, type, is_system)
+VALUES (gen_random_uuid()::text, '6950', 'Cash Over and Short', 'expense', TRUE)
+ON CONFLICT (code) DO NOTHING;
\ No newline at end of file
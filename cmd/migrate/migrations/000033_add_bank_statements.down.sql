@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS bank_match_rejections;
+
+DROP INDEX IF EXISTS bank_statement_lines_status_idx;
+DROP INDEX IF EXISTS bank_statement_lines_statement_id_idx;
+DROP TABLE IF EXISTS bank_statement_lines;
+
+DROP TABLE IF EXISTS bank_statements;
\ No newline at end of file
@@ -0,0 +1,36 @@
+CREATE TABLE IF NOT EXISTS bank_statements(
+    id VARCHAR(36) PRIMARY KEY,
+    bank_format VARCHAR(20) NOT NULL,
+    file_name VARCHAR(255) NOT NULL DEFAULT '',
+    note VARCHAR(200) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS bank_statement_lines(
+    id VARCHAR(36) PRIMARY KEY,
+    statement_id VARCHAR(36) NOT NULL REFERENCES bank_statements(id) ON DELETE CASCADE,
+    line_number INTEGER NOT NULL,
+    txn_date DATE NOT NULL,
+    description VARCHAR(500) NOT NULL DEFAULT '',
+    reference VARCHAR(100) NOT NULL DEFAULT '',
+    amount DOUBLE PRECISION NOT NULL,
+    fingerprint VARCHAR(64) NOT NULL UNIQUE,
+    status VARCHAR(20) NOT NULL DEFAULT 'unmatched' CHECK (status IN ('unmatched', 'matched', 'ignored')),
+    transaction_id VARCHAR(36) REFERENCES transactions(id),
+    payment_id VARCHAR(36) REFERENCES payments(id),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS bank_statement_lines_statement_id_idx
+ON bank_statement_lines (statement_id);
+
+CREATE INDEX IF NOT EXISTS bank_statement_lines_status_idx
+ON bank_statement_lines (status, txn_date);
+
+CREATE TABLE IF NOT EXISTS bank_match_rejections(
+    line_id VARCHAR(36) NOT NULL REFERENCES bank_statement_lines(id) ON DELETE CASCADE,
+    transaction_id VARCHAR(36) NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (line_id, transaction_id)
+);
\ No newline at end of file
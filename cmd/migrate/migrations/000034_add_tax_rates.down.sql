@@ -0,0 +1,14 @@
+DELETE FROM accounts a
+WHERE a.code = '2200' AND NOT EXISTS (SELECT 1 FROM journal_lines l WHERE l.account_id = a.id);
+
+ALTER TABLE transactions
+DROP COLUMN IF EXISTS tax_exempt,
+DROP COLUMN IF EXISTS tax_inclusive,
+DROP COLUMN IF EXISTS tax_amount,
+DROP COLUMN IF EXISTS tax_rate,
+DROP COLUMN IF EXISTS subtotal;
+
+ALTER TABLE customers
+DROP COLUMN IF EXISTS tax_exempt;
+
+DROP TABLE IF EXISTS tax_rates;
\ No newline at end of file
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS tax_rates(
+    id VARCHAR(36) PRIMARY KEY,
+    name VARCHAR(50) NOT NULL DEFAULT 'PPN',
+    rate DOUBLE PRECISION NOT NULL CHECK (rate >= 0 AND rate < 100),
+    prices_include_tax BOOLEAN NOT NULL DEFAULT FALSE,
+    effective_from DATE NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE customers
+ADD COLUMN tax_exempt BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE transactions
+ADD COLUMN subtotal DOUBLE PRECISION NOT NULL DEFAULT 0,
+ADD COLUMN tax_rate DOUBLE PRECISION NOT NULL DEFAULT 0,
+ADD COLUMN tax_amount DOUBLE PRECISION NOT NULL DEFAULT 0,
+ADD COLUMN tax_inclusive BOOLEAN NOT NULL DEFAULT FALSE,
+ADD COLUMN tax_exempt BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE transactions SET subtotal = total_price;
+
+INSERT INTO accounts (id, code, name, type, is_system)
+VALUES (gen_random_uuid()::text, '2200', 'PPN Payable', 'liability', TRUE)
+ON CONFLICT (code) DO NOTHING;
\ No newline at end of file
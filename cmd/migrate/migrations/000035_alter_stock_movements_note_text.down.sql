@@ -0,0 +1,2 @@
+ALTER TABLE stock_movements
+ALTER COLUMN note TYPE VARCHAR(100) USING left(note, 100);
\ No newline at end of file
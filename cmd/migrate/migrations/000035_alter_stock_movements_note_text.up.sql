@@ -0,0 +1,2 @@
+ALTER TABLE stock_movements
+ALTER COLUMN note TYPE TEXT;
\ No newline at end of file
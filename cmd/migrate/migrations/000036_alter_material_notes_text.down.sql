@@ -0,0 +1,5 @@
+ALTER TABLE material_movements
+ALTER COLUMN note TYPE VARCHAR(100) USING left(note, 100);
+
+ALTER TABLE material_receipts
+ALTER COLUMN note TYPE VARCHAR(100) USING left(note, 100);
\ No newline at end of file
@@ -0,0 +1,5 @@
+ALTER TABLE material_receipts
+ALTER COLUMN note TYPE TEXT;
+
+ALTER TABLE material_movements
+ALTER COLUMN note TYPE TEXT;
\ No newline at end of file
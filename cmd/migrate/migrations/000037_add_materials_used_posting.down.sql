@@ -0,0 +1,5 @@
+DELETE FROM journal_entries
+WHERE source_type = 'production';
+
+DELETE FROM accounts a
+WHERE a.code = '5100' AND NOT EXISTS (SELECT 1 FROM journal_lines l WHERE l.account_id = a.id);
\ No newline at end of file
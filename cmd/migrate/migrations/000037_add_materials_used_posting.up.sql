@@ -0,0 +1,34 @@
+INSERT INTO accounts (id, code, name, type, is_system)
+VALUES (gen_random_uuid()::text, '5100', 'Materials Used', 'expense', TRUE)
+ON CONFLICT (code) DO NOTHING;
+
+CREATE TEMPORARY TABLE production_material_costs AS
+SELECT p.id as production_id, p.production_date, ROUND(SUM(-mv.quantity * COALESCE(cost.unit_cost, 0))::numeric, 2)::double precision as amount
+FROM productions p
+JOIN material_movements mv ON mv.source_type = 'production' AND mv.source_id = p.id
+LEFT JOIN LATERAL (
+    SELECT SUM(r.quantity * r.unit_cost) / NULLIF(SUM(r.quantity), 0) as unit_cost
+    FROM material_receipts r
+    WHERE r.material_id = mv.material_id AND r.unit_cost > 0
+        AND r.received_date <= p.production_date
+) cost ON TRUE
+GROUP BY p.id, p.production_date;
+
+INSERT INTO journal_entries (id, entry_date, source_type, source_id, description)
+SELECT gen_random_uuid()::text, production_date, 'production', production_id, 'Materials used in production'
+FROM production_material_costs
+WHERE amount > 0;
+
+INSERT INTO journal_lines (id, entry_id, account_id, debit, credit)
+SELECT gen_random_uuid()::text, e.id, a.id, c.amount, 0
+FROM journal_entries e
+JOIN production_material_costs c ON e.source_type = 'production' AND c.production_id = e.source_id
+JOIN accounts a ON a.code = '5100';
+
+INSERT INTO journal_lines (id, entry_id, account_id, debit, credit)
+SELECT gen_random_uuid()::text, e.id, a.id, 0, c.amount
+FROM journal_entries e
+JOIN production_material_costs c ON e.source_type = 'production' AND c.production_id = e.source_id
+JOIN accounts a ON a.code = '1300';
+
+DROP TABLE production_material_costs;
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE bank_statements
+ALTER COLUMN note TYPE VARCHAR(200) USING left(note, 200);
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE bank_statements
+ALTER COLUMN note TYPE TEXT;
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE expenses
+DROP COLUMN IF EXISTS unpaid;
\ No newline at end of file
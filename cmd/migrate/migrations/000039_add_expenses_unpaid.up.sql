@@ -0,0 +1,2 @@
+ALTER TABLE expenses
+ADD COLUMN unpaid BOOLEAN NOT NULL DEFAULT FALSE;
\ No newline at end of file
@@ -0,0 +1,286 @@
+package bankstatement
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kevinbrivio/batako-backend/internal/models"
+)
+
+// Column layouts of the internet banking CSV exports of the banks our
+// customers use. They drift between versions of the banks' sites, so any of
+// them can be overridden per upload.
+var Formats = map[string]models.BankFormat{
+	"bca": {
+		Code: "bca",
+		Name: "BCA (KlikBCA)",
+		Delimiter: ",",
+		DateColumn: 1,
+		DateLayout: "02/01/2006",
+		DescriptionColumn: 2,
+		AmountColumn: 4,
+	},
+	"mandiri": {
+		Code: "mandiri",
+		Name: "Bank Mandiri",
+		Delimiter: ",",
+		DateColumn: 2,
+		DateLayout: "02/01/2006",
+		DescriptionColumn: 5,
+		ReferenceColumn: 6,
+		DebitColumn: 7,
+		CreditColumn: 8,
+	},
+	"bni": {
+		Code: "bni",
+		Name: "BNI",
+		Delimiter: ",",
+		DateColumn: 1,
+		DateLayout: "02/01/06",
+		DescriptionColumn: 5,
+		ReferenceColumn: 4,
+		DebitColumn: 6,
+		CreditColumn: 7,
+	},
+	"bri": {
+		Code: "bri",
+		Name: "BRI",
+		Delimiter: ";",
+		DateColumn: 1,
+		DateLayout: "02/01/06",
+		DescriptionColumn: 2,
+		DebitColumn: 4,
+		CreditColumn: 5,
+	},
+}
+
+func FormatList() []models.BankFormat {
+	list := make([]models.BankFormat, 0, len(Formats))
+	for _, f := range Formats {
+		list = append(list, f)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Code < list[j].Code })
+	return list
+}
+
+func Validate(f models.BankFormat) error {
+	if len(f.Delimiter) != 1 {
+		return errors.New("delimiter must be a single character")
+	}
+	if f.DateColumn < 1 || f.DateLayout == "" {
+		return errors.New("date column and date layout are required")
+	}
+	if f.DescriptionColumn < 1 {
+		return errors.New("description column is required")
+	}
+	if (f.AmountColumn > 0) == (f.DebitColumn > 0 || f.CreditColumn > 0) {
+		return errors.New("either an amount column or debit and credit columns are required")
+	}
+	for _, c := range []int{f.ReferenceColumn, f.AmountColumn, f.DebitColumn, f.CreditColumn} {
+		if c < 0 {
+			return errors.New("column numbers cannot be negative")
+		}
+	}
+	return nil
+}
+
+// Read the statement lines out of a CSV export. Rows whose date column doesn't
+// parse, such as the account header and balance footer banks put around the
+// transactions, are skipped and counted. A row with a date but an amount that
+// can't be read fails the whole file, since dropping it would go unnoticed.
+func Parse(r io.Reader, f models.BankFormat) ([]models.BankStatementLine, int, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = rune(f.Delimiter[0])
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	lines := []models.BankStatementLine{}
+	skipped := 0
+
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, 0, fmt.Errorf("row %d: %w", row, err)
+		}
+
+		date, err := parseDate(column(record, f.DateColumn), f.DateLayout)
+		if err != nil {
+			skipped++
+			continue
+		}
+
+		amount, err := parseLineAmount(record, f)
+		if err != nil {
+			return nil, 0, fmt.Errorf("row %d: %w", row, err)
+		}
+		if amount == 0 {
+			skipped++
+			continue
+		}
+
+		lines = append(lines, models.BankStatementLine{
+			LineNumber: row,
+			TxnDate: date,
+			Description: truncate(column(record, f.DescriptionColumn), 500),
+			Reference: truncate(column(record, f.ReferenceColumn), 100),
+			Amount: amount,
+		})
+	}
+
+	return lines, skipped, nil
+}
+
+// Identifies a line across overlapping imports. Identical lines within one
+// file (two equal transfers on the same day) are told apart by occurrence.
+func Fingerprint(l models.BankStatementLine, occurrence int) string {
+	key := fmt.Sprintf("%s|%.2f|%s|%s|%d", l.TxnDate.Format("2006-01-02"), l.Amount, strings.ToLower(l.Description), l.Reference, occurrence)
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func column(record []string, n int) string {
+	if n < 1 || n > len(record) {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(record[n - 1]), "'"))
+}
+
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n])
+	}
+	return s
+}
+
+// Some banks append the time to the date
+func parseDate(s, layout string) (time.Time, error) {
+	if i := strings.IndexByte(s, ' '); i > 0 && !strings.Contains(layout, " ") {
+		s = s[:i]
+	}
+	return time.Parse(layout, s)
+}
+
+func parseLineAmount(record []string, f models.BankFormat) (float64, error) {
+	if f.AmountColumn > 0 {
+		return ParseAmount(column(record, f.AmountColumn))
+	}
+
+	debit, err := ParseAmount(column(record, f.DebitColumn))
+	if err != nil {
+		return 0, err
+	}
+	credit, err := ParseAmount(column(record, f.CreditColumn))
+	if err != nil {
+		return 0, err
+	}
+	return abs(credit) - abs(debit), nil
+}
+
+// Read an amount the way Indonesian banks write them: "1.500.000,00",
+// "1,500,000.00 CR", "-250000" or "(250.000)". CR/DB suffixes and brackets
+// set the sign. An empty cell is zero.
+func ParseAmount(s string) (float64, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if s == "" || s == "-" {
+		return 0, nil
+	}
+
+	sign := 1.0
+	switch {
+	case strings.HasSuffix(s, "CR"):
+		s = strings.TrimSuffix(s, "CR")
+	case strings.HasSuffix(s, "DB"):
+		s = strings.TrimSuffix(s, "DB")
+		sign = -1
+	}
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		s = s[1:len(s) - 1]
+		sign = -sign
+	}
+
+	s = strings.NewReplacer("RP", "", "IDR", "", " ", "").Replace(s)
+	switch {
+	case strings.HasPrefix(s, "-"):
+		s = s[1:]
+		sign = -sign
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+
+	plain := normalizeSeparators(s)
+	if !isPlainNumber(plain) {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+
+	value, err := strconv.ParseFloat(plain, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	return sign * value, nil
+}
+
+// Turn thousands and decimal separators of either convention into a plain
+// number. With both present the last one is the decimal separator; with one,
+// it is a decimal separator only when at most two digits follow it.
+func normalizeSeparators(s string) string {
+	dot, comma := strings.LastIndex(s, "."), strings.LastIndex(s, ",")
+
+	decimal := -1
+	switch {
+	case dot >= 0 && comma >= 0:
+		decimal = max(dot, comma)
+	case dot >= 0 && strings.Count(s, ".") == 1 && len(s) - dot - 1 <= 2:
+		decimal = dot
+	case comma >= 0 && strings.Count(s, ",") == 1 && len(s) - comma - 1 <= 2:
+		decimal = comma
+	}
+
+	var b strings.Builder
+	for i, c := range s {
+		switch {
+		case i == decimal:
+			b.WriteByte('.')
+		case c == '.' || c == ',':
+		default:
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
+
+// Only digits with at most one decimal point, so ParseFloat never sees
+// exponents, hex, Inf or NaN
+func isPlainNumber(s string) bool {
+	digits, points := 0, 0
+	for _, c := range s {
+		switch {
+		case c >= '0' && c <= '9':
+			digits++
+		case c == '.':
+			points++
+		default:
+			return false
+		}
+	}
+	return digits > 0 && points <= 1
+}
+
+func abs(v float64) float64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
\ No newline at end of file
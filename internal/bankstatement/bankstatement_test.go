@@ -0,0 +1,72 @@
+package bankstatement
+
+import "testing"
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		in   string
+		want float64
+	}{
+		{"", 0},
+		{"-", 0},
+		{"1.500", 1500},
+		{"1,500", 1500},
+		{"1.5", 1.5},
+		{"1.500.000", 1500000},
+		{"1.500.000,00", 1500000},
+		{"1,500,000.00", 1500000},
+		{"1,500,000.00 CR", 1500000},
+		{"1.500.000,50 DB", -1500000.5},
+		{"250000.00DB", -250000},
+		{"(250.000)", -250000},
+		{"(250.000) DB", 250000},
+		{"-250000", -250000},
+		{"+250000", 250000},
+		{"Rp 75.000", 75000},
+		{"IDR 1.250.000,25", 1250000.25},
+		{"  12,5  ", 12.5},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseAmount(tt.in)
+		if err != nil {
+			t.Errorf("ParseAmount(%q) returned error: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseAmount(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseAmountInvalid(t *testing.T) {
+	for _, in := range []string{"abc", "12a", "CR", "()", "Inf", "-Infinity", "NaN", "1e9", "0x1p3", "1_000", "--5", "."} {
+		if _, err := ParseAmount(in); err == nil {
+			t.Errorf("ParseAmount(%q) returned no error", in)
+		}
+	}
+}
+
+func TestNormalizeSeparators(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"1500", "1500"},
+		{"1.500", "1500"},
+		{"1,500", "1500"},
+		{"1.50", "1.50"},
+		{"1,50", "1.50"},
+		{"1.500.000", "1500000"},
+		{"1,500,000", "1500000"},
+		{"1.500.000,00", "1500000.00"},
+		{"1,500,000.00", "1500000.00"},
+		{"1.500,5", "1500.5"},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeSeparators(tt.in); got != tt.want {
+			t.Errorf("normalizeSeparators(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/kevinbrivio/batako-backend/internal/models"
+	"github.com/kevinbrivio/batako-backend/internal/store"
+	"github.com/kevinbrivio/batako-backend/internal/utils"
+)
+
+type AttendanceHandler struct {
+	Store store.Storage
+}
+
+func NewAttendanceHandler(s store.Storage) *AttendanceHandler {
+	return &AttendanceHandler{Store: s}
+}
+
+// Start a shift; check_in defaults to now and shift to a day shift
+func (h *AttendanceHandler) CheckIn(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req models.Attendance
+	if err := utils.ReadJSON(r, &req); err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("Invalid JSON format"))
+		return
+	}
+
+	if req.EmployeeID == "" {
+		utils.WriteError(w, utils.NewBadRequestError("Employee cannot be empty"))
+		return
+	}
+
+	if req.CheckIn.IsZero() {
+		req.CheckIn = time.Now()
+	}
+
+	if err := validateAttendance(&req); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	if err := h.Store.Attendance.CheckIn(ctx, &req); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, "Checked in successfully", req)
+}
+
+// End a shift; the body is optional and check_out defaults to now
+func (h *AttendanceHandler) CheckOut(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req struct {
+		CheckOut time.Time `json:"check_out"`
+	}
+	if err := utils.ReadJSON(r, &req); err != nil && err != io.EOF {
+		utils.WriteError(w, utils.NewBadRequestError("Invalid JSON format"))
+		return
+	}
+
+	now := time.Now()
+	if req.CheckOut.IsZero() {
+		req.CheckOut = now
+	}
+
+	if req.CheckOut.After(now) {
+		utils.WriteError(w, utils.NewBadRequestError("Date cannot be in the future"))
+		return
+	}
+
+	a, err := h.Store.Attendance.CheckOut(ctx, chi.URLParam(r, "id"), req.CheckOut)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Checked out successfully", a)
+}
+
+func (h *AttendanceHandler) UpdateAttendance(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var a models.Attendance
+	if err := utils.ReadJSON(r, &a); err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("Invalid JSON Format"))
+		return
+	}
+
+	if a.CheckIn.IsZero() {
+		utils.WriteError(w, utils.NewBadRequestError("Check-in cannot be empty"))
+		return
+	}
+
+	if err := validateAttendance(&a); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	if a.CheckOut != nil && a.CheckOut.After(time.Now()) {
+		utils.WriteError(w, utils.NewBadRequestError("Date cannot be in the future"))
+		return
+	}
+
+	a.ID = chi.URLParam(r, "id")
+
+	if err := h.Store.Attendance.Update(ctx, &a); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Attendance updated successfully", a)
+}
+
+func (h *AttendanceHandler) DeleteAttendance(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if err := h.Store.Attendance.Delete(ctx, chi.URLParam(r, "id")); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Attendance deleted successfully", nil)
+}
+
+func (h *AttendanceHandler) GetAttendanceDaily(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	dt, err := parseDateParam(r, "date", time.Now())
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	records, summary, err := h.Store.Attendance.GetDaily(ctx, dt)
+	if err != nil {
+		utils.WriteError(w, utils.NewInternalServerError(err))
+		return
+	}
+
+	data := map[string]interface{}{
+		"present": summary.Present,
+		"half_shifts": summary.HalfShifts,
+		"night_shifts": summary.NightShifts,
+		"checked_in": summary.CheckedIn,
+		"hours_worked": summary.HoursWorked,
+		"overtime_hours": summary.OvertimeHours,
+		"date": dt.Format("2006-01-02"),
+		"day": dt.Weekday().String(),
+		"attendance": records,
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get daily attendance", data)
+}
+
+func (h *AttendanceHandler) GetAttendanceMonthly(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	monthNum, _ := strconv.Atoi(r.URL.Query().Get("month"))
+	currentMonth := int(time.Now().Month())
+	if monthNum < 1 || monthNum > 12 {
+		monthNum = currentMonth
+	}
+	targetOffset := monthNum - currentMonth
+
+	if targetOffset < -6 {
+		targetOffset += 12
+	}
+
+	employees, err := h.Store.Attendance.GetMonthly(ctx, targetOffset)
+	if err != nil {
+		utils.WriteError(w, utils.NewInternalServerError(err))
+		return
+	}
+
+	var days, overtime, wages float64
+	for _, e := range employees {
+		days += e.Days
+		overtime += e.OvertimeHours
+		wages += e.Wage
+	}
+
+	data := map[string]interface{}{
+		"employees": employees,
+		"total_days": days,
+		"total_overtime_hours": overtime,
+		"total_wages": wages,
+		"month": monthNum,
+		"month_name": time.Month(monthNum).String(),
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get monthly attendance", data)
+}
+
+func validateAttendance(a *models.Attendance) error {
+	switch a.Shift {
+	case "":
+		a.Shift = models.ShiftDay
+	case models.ShiftDay, models.ShiftNight, models.ShiftHalf:
+	default:
+		return utils.NewBadRequestError("Shift must be day, night or half")
+	}
+
+	if a.CheckIn.After(time.Now()) {
+		return utils.NewBadRequestError("Date cannot be in the future")
+	}
+
+	return nil
+}
\ No newline at end of file
@@ -0,0 +1,302 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/kevinbrivio/batako-backend/internal/bankstatement"
+	"github.com/kevinbrivio/batako-backend/internal/models"
+	"github.com/kevinbrivio/batako-backend/internal/store"
+	"github.com/kevinbrivio/batako-backend/internal/utils"
+)
+
+const maxStatementSize = 5 << 20
+
+type BankHandler struct {
+	Store store.Storage
+}
+
+func NewBankHandler(s store.Storage) *BankHandler {
+	return &BankHandler{Store: s}
+}
+
+func (h *BankHandler) GetFormats(w http.ResponseWriter, r *http.Request) {
+	utils.WriteJSON(w, http.StatusOK, "Successfully get bank formats", bankstatement.FormatList())
+}
+
+// Multipart upload of a CSV export. format picks one of the known bank
+// layouts; mapping, a JSON object of BankFormat fields, overrides parts of it
+// or describes a bank we don't know yet.
+func (h *BankHandler) ImportStatement(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Leave some room for the other form fields
+	r.Body = http.MaxBytesReader(w, r.Body, maxStatementSize + 1 << 20)
+	if err := r.ParseMultipartForm(maxStatementSize); err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("Invalid upload, expected a multipart form with a file of at most 5 MB"))
+		return
+	}
+
+	format := models.BankFormat{Code: "custom", Name: "Custom", Delimiter: ","}
+	if code := strings.ToLower(r.FormValue("format")); code != "" {
+		preset, ok := bankstatement.Formats[code]
+		if !ok {
+			utils.WriteError(w, utils.NewBadRequestError("Unknown bank format " + code))
+			return
+		}
+		format = preset
+	}
+
+	if mapping := r.FormValue("mapping"); mapping != "" {
+		if err := json.Unmarshal([]byte(mapping), &format); err != nil {
+			utils.WriteError(w, utils.NewBadRequestError("Invalid mapping JSON"))
+			return
+		}
+	}
+
+	if err := bankstatement.Validate(format); err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("Invalid mapping: " + err.Error()))
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("File is required"))
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxStatementSize+1))
+	if err != nil {
+		utils.WriteError(w, utils.NewInternalServerError(err))
+		return
+	}
+
+	if len(data) > maxStatementSize {
+		utils.WriteError(w, utils.NewBadRequestError("File must not exceed 5 MB"))
+		return
+	}
+
+	// Excel likes to save CSVs with a byte order mark
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	lines, skipped, err := bankstatement.Parse(bytes.NewReader(data), format)
+	if err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("Could not read statement: " + err.Error()))
+		return
+	}
+
+	if len(lines) == 0 {
+		utils.WriteError(w, utils.NewBadRequestError("No transactions found, check the format and column mapping"))
+		return
+	}
+
+	st := models.BankStatement{
+		BankFormat: format.Code,
+		FileName:   filepath.Base(header.Filename),
+		Note:       strings.TrimSpace(r.FormValue("note")),
+		Skipped:    skipped,
+	}
+
+	if err := h.Store.Bank.Import(ctx, &st, lines); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, "Bank statement imported successfully", st)
+}
+
+func (h *BankHandler) GetStatements(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Get query params
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1 // default to 1
+	}
+
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+
+	// Calculate offset
+	offset := (page - 1) * limit
+
+	statements, totalCount, err := h.Store.Bank.GetStatements(ctx, limit, offset)
+	if err != nil {
+		utils.WriteError(w, utils.NewInternalServerError(err))
+		return
+	}
+
+	totalPages := (totalCount + limit - 1) / limit
+
+	response := utils.PaginatedResponse{
+		Items:      statements,
+		Total:      totalCount,
+		Page:       page,
+		PageSize:   limit,
+		TotalPages: totalPages,
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get bank statements", response)
+}
+
+func (h *BankHandler) GetStatement(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	st, err := h.Store.Bank.GetStatement(ctx, chi.URLParam(r, "id"))
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get bank statement", st)
+}
+
+func (h *BankHandler) DeleteStatement(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if err := h.Store.Bank.DeleteStatement(ctx, chi.URLParam(r, "id")); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Bank statement deleted successfully", nil)
+}
+
+// ?status=unmatched is the reconciliation work list
+func (h *BankHandler) GetLines(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Get query params
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1 // default to 1
+	}
+
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+
+	// Calculate offset
+	offset := (page - 1) * limit
+
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", models.BankLineUnmatched, models.BankLineMatched, models.BankLineIgnored:
+	default:
+		utils.WriteError(w, utils.NewBadRequestError("Status must be unmatched, matched or ignored"))
+		return
+	}
+
+	lines, totalCount, err := h.Store.Bank.GetLines(ctx, status, limit, offset)
+	if err != nil {
+		utils.WriteError(w, utils.NewInternalServerError(err))
+		return
+	}
+
+	totalPages := (totalCount + limit - 1) / limit
+
+	response := utils.PaginatedResponse{
+		Items:      lines,
+		Total:      totalCount,
+		Page:       page,
+		PageSize:   limit,
+		TotalPages: totalPages,
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get bank statement lines", response)
+}
+
+func (h *BankHandler) GetSuggestions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	suggestions, err := h.Store.Bank.GetSuggestions(ctx, chi.URLParam(r, "id"))
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get match suggestions", suggestions)
+}
+
+func (h *BankHandler) ConfirmMatch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	tID, err := readMatchTransaction(r)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	l, err := h.Store.Bank.ConfirmMatch(ctx, chi.URLParam(r, "id"), tID)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Match confirmed and payment recorded", l)
+}
+
+func (h *BankHandler) RejectMatch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	tID, err := readMatchTransaction(r)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	if err := h.Store.Bank.RejectMatch(ctx, chi.URLParam(r, "id"), tID); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Match rejected successfully", nil)
+}
+
+func (h *BankHandler) IgnoreLine(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	l, err := h.Store.Bank.Ignore(ctx, chi.URLParam(r, "id"))
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Line ignored successfully", l)
+}
+
+func (h *BankHandler) UnmatchLine(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	l, err := h.Store.Bank.Unmatch(ctx, chi.URLParam(r, "id"))
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Line unmatched successfully", l)
+}
+
+func readMatchTransaction(r *http.Request) (string, error) {
+	var req struct {
+		TransactionID string `json:"transaction_id"`
+	}
+	if err := utils.ReadJSON(r, &req); err != nil {
+		return "", utils.NewBadRequestError("Invalid JSON format")
+	}
+
+	if req.TransactionID == "" {
+		return "", utils.NewBadRequestError("Transaction ID is required")
+	}
+	return req.TransactionID, nil
+}
\ No newline at end of file
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/kevinbrivio/batako-backend/internal/models"
+	"github.com/kevinbrivio/batako-backend/internal/store"
+	"github.com/kevinbrivio/batako-backend/internal/utils"
+)
+
+type CashHandler struct {
+	Store store.Storage
+}
+
+func NewCashHandler(s store.Storage) *CashHandler {
+	return &CashHandler{Store: s}
+}
+
+// Open the drawer for a day, today unless session_date is given
+func (h *CashHandler) OpenSession(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req models.CashSession
+	if err := utils.ReadJSON(r, &req); err != nil && err != io.EOF {
+		utils.WriteError(w, utils.NewBadRequestError("Invalid JSON format"))
+		return
+	}
+
+	now := time.Now()
+	if req.SessionDate.IsZero() {
+		req.SessionDate = now
+	}
+
+	if req.SessionDate.After(now) {
+		utils.WriteError(w, utils.NewBadRequestError("Date cannot be in the future"))
+		return
+	}
+
+	if req.OpeningFloat < 0 {
+		utils.WriteError(w, utils.NewBadRequestError("Opening float cannot be negative"))
+		return
+	}
+	req.Note = strings.TrimSpace(req.Note)
+
+	if err := h.Store.Cash.Open(ctx, &req); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, "Cash session opened successfully", req)
+}
+
+func (h *CashHandler) GetAllSessions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Get query params
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1 // default to 1
+	}
+
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+
+	// Calculate offset
+	offset := (page - 1) * limit
+
+	sessions, totalCount, err := h.Store.Cash.GetAll(ctx, limit, offset)
+	if err != nil {
+		utils.WriteError(w, utils.NewInternalServerError(err))
+		return
+	}
+
+	totalPages := (totalCount + limit - 1) / limit
+
+	response := utils.PaginatedResponse{
+		Items:      sessions,
+		Total:      totalCount,
+		Page:       page,
+		PageSize:   limit,
+		TotalPages: totalPages,
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get cash sessions", response)
+}
+
+func (h *CashHandler) GetSession(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	date, err := parseSessionDate(r)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	cs, err := h.Store.Cash.GetByDate(ctx, date)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get cash session", cs)
+}
+
+func (h *CashHandler) CloseSession(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	date, err := parseSessionDate(r)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	var req struct {
+		CountedCash *float64 `json:"counted_cash"`
+		Note        string   `json:"note"`
+	}
+	if err := utils.ReadJSON(r, &req); err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("Invalid JSON format"))
+		return
+	}
+
+	if req.CountedCash == nil || *req.CountedCash < 0 {
+		utils.WriteError(w, utils.NewBadRequestError("Counted cash is required and cannot be negative"))
+		return
+	}
+
+	cs, err := h.Store.Cash.Close(ctx, date, *req.CountedCash, strings.TrimSpace(req.Note))
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Cash session closed successfully", cs)
+}
+
+func (h *CashHandler) ReopenSession(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	date, err := parseSessionDate(r)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	cs, err := h.Store.Cash.Reopen(ctx, date)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Cash session reopened successfully", cs)
+}
+
+func (h *CashHandler) DeleteSession(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	date, err := parseSessionDate(r)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	if err := h.Store.Cash.Delete(ctx, date); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Cash session deleted successfully", nil)
+}
+
+func parseSessionDate(r *http.Request) (time.Time, error) {
+	date, err := time.Parse("2006-01-02", chi.URLParam(r, "date"))
+	if err != nil {
+		return time.Time{}, utils.NewBadRequestError("Date must be in YYYY-MM-DD format")
+	}
+	return date, nil
+}
\ No newline at end of file
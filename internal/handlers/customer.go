@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/kevinbrivio/batako-backend/internal/models"
+	"github.com/kevinbrivio/batako-backend/internal/store"
+	"github.com/kevinbrivio/batako-backend/internal/utils"
+)
+
+type CustomerHandler struct {
+	Store store.Storage
+}
+
+func NewCustomerHandler(s store.Storage) *CustomerHandler {
+	return &CustomerHandler{Store: s}
+}
+
+func (h *CustomerHandler) CreateCustomer(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req models.Customer
+	if err := utils.ReadJSON(r, &req); err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("Invalid JSON format"))
+		return
+	}
+
+	if utils.CleanName(req.Name) == "" {
+		utils.WriteError(w, utils.NewBadRequestError("Customer name cannot be empty"))
+		return
+	}
+
+	for _, a := range req.Addresses {
+		if strings.TrimSpace(a.Address) == "" {
+			utils.WriteError(w, utils.NewBadRequestError("Address cannot be empty"))
+			return
+		}
+	}
+
+	for _, p := range req.Phones {
+		if strings.TrimSpace(p.Phone) == "" {
+			utils.WriteError(w, utils.NewBadRequestError("Phone number cannot be empty"))
+			return
+		}
+	}
+
+	if err := h.Store.Customer.Create(ctx, &req); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, "Customer created successfully", req)
+}
+
+func (h *CustomerHandler) GetAllCustomers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Get query params
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1 // default to 1
+	}
+
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+
+	search := r.URL.Query().Get("q")
+
+	// Calculate offset
+	offset := (page - 1) * limit
+
+	customers, totalCount, err := h.Store.Customer.GetAll(ctx, search, limit, offset)
+	if err != nil {
+		utils.WriteError(w, utils.NewInternalServerError(err))
+		return
+	}
+
+	totalPages := (totalCount + limit - 1) / limit
+
+	response := utils.PaginatedResponse{
+		Items:      customers,
+		Total:      totalCount,
+		Page:       page,
+		PageSize:   limit,
+		TotalPages: totalPages,
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get all customers", response)
+}
+
+func (h *CustomerHandler) GetCustomer(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idStr := chi.URLParam(r, "id")
+
+	c, err := h.Store.Customer.GetByID(ctx, idStr)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get customer", c)
+}
+
+func (h *CustomerHandler) UpdateCustomer(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idStr := chi.URLParam(r, "id")
+
+	var c models.Customer
+	if err := utils.ReadJSON(r, &c); err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("Invalid JSON Format"))
+		return
+	}
+
+	if utils.CleanName(c.Name) == "" {
+		utils.WriteError(w, utils.NewBadRequestError("Customer name cannot be empty"))
+		return
+	}
+
+	c.ID = idStr
+
+	if err := h.Store.Customer.Update(ctx, &c); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Customer updated successfully", c)
+}
+
+func (h *CustomerHandler) DeleteCustomer(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idStr := chi.URLParam(r, "id")
+	if err := h.Store.Customer.Delete(ctx, idStr); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Customer deleted successfully", nil)
+}
+
+func (h *CustomerHandler) AddAddress(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var a models.CustomerAddress
+	if err := utils.ReadJSON(r, &a); err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("Invalid JSON format"))
+		return
+	}
+
+	if strings.TrimSpace(a.Address) == "" {
+		utils.WriteError(w, utils.NewBadRequestError("Address cannot be empty"))
+		return
+	}
+
+	a.CustomerID = chi.URLParam(r, "id")
+
+	if err := h.Store.Customer.AddAddress(ctx, &a); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, "Address added successfully", a)
+}
+
+func (h *CustomerHandler) UpdateAddress(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var a models.CustomerAddress
+	if err := utils.ReadJSON(r, &a); err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("Invalid JSON Format"))
+		return
+	}
+
+	if strings.TrimSpace(a.Address) == "" {
+		utils.WriteError(w, utils.NewBadRequestError("Address cannot be empty"))
+		return
+	}
+
+	a.CustomerID = chi.URLParam(r, "id")
+	a.ID = chi.URLParam(r, "addressID")
+
+	if err := h.Store.Customer.UpdateAddress(ctx, &a); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Address updated successfully", a)
+}
+
+func (h *CustomerHandler) DeleteAddress(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	err := h.Store.Customer.DeleteAddress(ctx, chi.URLParam(r, "id"), chi.URLParam(r, "addressID"))
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Address deleted successfully", nil)
+}
+
+func (h *CustomerHandler) AddPhone(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var p models.CustomerPhone
+	if err := utils.ReadJSON(r, &p); err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("Invalid JSON format"))
+		return
+	}
+
+	if strings.TrimSpace(p.Phone) == "" {
+		utils.WriteError(w, utils.NewBadRequestError("Phone number cannot be empty"))
+		return
+	}
+
+	p.CustomerID = chi.URLParam(r, "id")
+
+	if err := h.Store.Customer.AddPhone(ctx, &p); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, "Phone added successfully", p)
+}
+
+func (h *CustomerHandler) DeletePhone(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	err := h.Store.Customer.DeletePhone(ctx, chi.URLParam(r, "id"), chi.URLParam(r, "phoneID"))
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Phone deleted successfully", nil)
+}
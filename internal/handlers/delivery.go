@@ -0,0 +1,293 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/kevinbrivio/batako-backend/internal/models"
+	"github.com/kevinbrivio/batako-backend/internal/pdf"
+	"github.com/kevinbrivio/batako-backend/internal/store"
+	"github.com/kevinbrivio/batako-backend/internal/utils"
+)
+
+type DeliveryHandler struct {
+	Store store.Storage
+}
+
+func NewDeliveryHandler(s store.Storage) *DeliveryHandler {
+	return &DeliveryHandler{Store: s}
+}
+
+func (h *DeliveryHandler) CreateDelivery(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req models.Delivery
+	if err := utils.ReadJSON(r, &req); err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("Invalid JSON format"))
+		return
+	}
+
+	if err := validateDelivery(&req); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	req.TransactionID = chi.URLParam(r, "id")
+
+	if err := h.Store.Delivery.Create(ctx, &req); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, "Delivery scheduled successfully", req)
+}
+
+func (h *DeliveryHandler) GetTransactionDeliveries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idStr := chi.URLParam(r, "id")
+
+	// 404 for unknown transactions instead of an empty list
+	t, err := h.Store.Transaction.GetByID(ctx, idStr)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	deliveries, err := h.Store.Delivery.GetByTransaction(ctx, idStr)
+	if err != nil {
+		utils.WriteError(w, utils.NewInternalServerError(err))
+		return
+	}
+
+	var scheduled, delivered int
+	for _, d := range deliveries {
+		if d.Status != models.DeliveryFailed {
+			scheduled += d.Quantity
+		}
+		if d.Status == models.DeliveryDelivered {
+			delivered += d.Quantity
+		}
+	}
+
+	data := map[string]interface{}{
+		"deliveries":         deliveries,
+		"quantity":           t.Quantity,
+		"scheduled_quantity": scheduled,
+		"delivered_quantity": delivered,
+		"unscheduled":        t.Quantity - scheduled,
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get deliveries", data)
+}
+
+func (h *DeliveryHandler) GetAllDeliveries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Get query params
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1 // default to 1
+	}
+
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+
+	date := r.URL.Query().Get("date")
+	if date != "" {
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			utils.WriteError(w, utils.NewBadRequestError("Invalid date, expected YYYY-MM-DD"))
+			return
+		}
+	}
+
+	status := r.URL.Query().Get("status")
+
+	// Calculate offset
+	offset := (page - 1) * limit
+
+	deliveries, totalCount, err := h.Store.Delivery.GetAll(ctx, date, status, limit, offset)
+	if err != nil {
+		utils.WriteError(w, utils.NewInternalServerError(err))
+		return
+	}
+
+	totalPages := (totalCount + limit - 1) / limit
+
+	response := utils.PaginatedResponse{
+		Items:      deliveries,
+		Total:      totalCount,
+		Page:       page,
+		PageSize:   limit,
+		TotalPages: totalPages,
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get all deliveries", response)
+}
+
+func (h *DeliveryHandler) GetDelivery(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	d, err := h.Store.Delivery.GetByID(ctx, chi.URLParam(r, "id"))
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get delivery", d)
+}
+
+func (h *DeliveryHandler) UpdateDelivery(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var d models.Delivery
+	if err := utils.ReadJSON(r, &d); err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("Invalid JSON Format"))
+		return
+	}
+
+	if err := validateDelivery(&d); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	d.ID = chi.URLParam(r, "id")
+
+	if err := h.Store.Delivery.Update(ctx, &d); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Delivery updated successfully", d)
+}
+
+func (h *DeliveryHandler) UpdateDeliveryStatus(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req models.DeliveryStatusUpdate
+	if err := utils.ReadJSON(r, &req); err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("Invalid JSON format"))
+		return
+	}
+
+	switch req.Status {
+	case models.DeliveryLoaded, models.DeliveryDelivered:
+	case models.DeliveryFailed:
+		if req.Note == "" {
+			utils.WriteError(w, utils.NewBadRequestError("Note is required when a delivery failed"))
+			return
+		}
+	default:
+		utils.WriteError(w, utils.NewBadRequestError("Status must be loaded, delivered or failed"))
+		return
+	}
+
+	d, err := h.Store.Delivery.UpdateStatus(ctx, chi.URLParam(r, "id"), &req)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Delivery status updated successfully", d)
+}
+
+func (h *DeliveryHandler) DeleteDelivery(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if err := h.Store.Delivery.Delete(ctx, chi.URLParam(r, "id")); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Delivery deleted successfully", nil)
+}
+
+// Surat jalan: the note the driver carries and the customer signs on arrival
+func (h *DeliveryHandler) GetDeliveryNotePDF(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	d, err := h.Store.Delivery.GetByID(ctx, chi.URLParam(r, "id"))
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	doc := renderDeliveryNote(d)
+
+	filename := strings.ReplaceAll(d.DeliveryNumber, "/", "-") + ".pdf"
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=\"%s\"", filename))
+	w.WriteHeader(http.StatusOK)
+	doc.WriteTo(w)
+}
+
+func renderDeliveryNote(d *models.Delivery) *pdf.Document {
+	doc := pdf.New()
+	page := doc.AddPage()
+
+	left, right := 50.0, pdf.PageWidth - 50
+
+	page.Text(left, 60, 18, true, companyName())
+	page.TextRight(right, 60, 18, true, "SURAT JALAN")
+	page.Line(left, 75, right, 75)
+
+	page.Text(left, 100, 10, true, "Deliver to")
+	page.Text(left, 116, 10, false, d.Customer)
+	page.Text(left, 130, 10, false, d.Address)
+
+	page.TextRight(right - 110, 100, 10, true, "No.")
+	page.TextRight(right, 100, 10, false, d.DeliveryNumber)
+	page.TextRight(right - 110, 116, 10, true, "Date")
+	page.TextRight(right, 116, 10, false, d.ScheduledDate.Format("02 Jan 2006"))
+	page.TextRight(right - 110, 130, 10, true, "Vehicle")
+	page.TextRight(right, 130, 10, false, d.Vehicle)
+	page.TextRight(right - 110, 144, 10, true, "Driver")
+	page.TextRight(right, 144, 10, false, d.Driver)
+
+	// Items table
+	y := 180.0
+	page.Line(left, y, right, y)
+	page.Text(left + 5, y + 15, 10, true, "Description")
+	page.TextRight(right - 5, y + 15, 10, true, "Quantity")
+	page.Line(left, y + 22, right, y + 22)
+
+	y += 40
+	page.Text(left + 5, y, 10, false, d.Product)
+	page.TextRight(right - 5, y, 10, false, strconv.Itoa(d.Quantity) + " pcs")
+	page.Line(left, y + 10, right, y + 10)
+
+	if d.Note != "" {
+		page.Text(left, y + 30, 10, false, "Note: " + d.Note)
+	}
+
+	// Signature boxes
+	y += 70
+	boxWidth := (right - left - 40) / 3
+	for i, label := range []string{"Sender", "Driver", "Received by"} {
+		x := left + float64(i) * (boxWidth + 20)
+		page.Text(x, y, 10, true, label)
+		page.Rect(x, y + 8, boxWidth, 70)
+	}
+
+	page.Text(left, pdf.PageHeight - 60, 8, false, "Transaction " + d.TransactionID)
+
+	return doc
+}
+
+func validateDelivery(d *models.Delivery) error {
+	if d.ScheduledDate.IsZero() {
+		return utils.NewBadRequestError("Scheduled date cannot be empty")
+	}
+
+	if d.Quantity <= 0 {
+		return utils.NewBadRequestError("Quantity must be greater than 0")
+	}
+
+	return nil
+}
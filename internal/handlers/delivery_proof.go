@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/kevinbrivio/batako-backend/internal/blob"
+	"github.com/kevinbrivio/batako-backend/internal/models"
+	"github.com/kevinbrivio/batako-backend/internal/store"
+	"github.com/kevinbrivio/batako-backend/internal/utils"
+)
+
+const (
+	maxProofSize = 5 << 20
+	proofThumbnailSize = 256
+
+	// Decoding allocates per pixel, so a small file declaring a huge canvas is
+	// refused up front. 40 megapixels covers any phone camera.
+	maxProofPixels = 40000000
+
+	// Length of the file_name column
+	maxProofFileName = 255
+)
+
+// File extension for each accepted upload type
+var proofContentTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+}
+
+// Client file name without its path, cut to fit the column. A long name keeps
+// its extension.
+func proofFileName(name string) string {
+	name = filepath.Base(name)
+	runes := []rune(name)
+	if len(runes) <= maxProofFileName {
+		return name
+	}
+
+	ext := []rune(filepath.Ext(name))
+	if len(ext) >= maxProofFileName {
+		ext = nil
+	}
+	return string(runes[:maxProofFileName - len(ext)]) + string(ext)
+}
+
+type DeliveryProofHandler struct {
+	Store store.Storage
+	Blob blob.Storage
+}
+
+func NewDeliveryProofHandler(s store.Storage, b blob.Storage) *DeliveryProofHandler {
+	return &DeliveryProofHandler{Store: s, Blob: b}
+}
+
+func (h *DeliveryProofHandler) UploadProof(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	tID := chi.URLParam(r, "id")
+
+	// Leave some room for the other form fields
+	r.Body = http.MaxBytesReader(w, r.Body, maxProofSize + 1 << 20)
+	if err := r.ParseMultipartForm(maxProofSize); err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("Invalid upload, expected a multipart form with a file of at most 5 MB"))
+		return
+	}
+
+	kind := r.FormValue("kind")
+	if kind != models.ProofPhoto && kind != models.ProofSignature {
+		utils.WriteError(w, utils.NewBadRequestError("Kind must be photo or signature"))
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("File is required"))
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxProofSize + 1))
+	if err != nil {
+		utils.WriteError(w, utils.NewInternalServerError(err))
+		return
+	}
+
+	if len(data) > maxProofSize {
+		utils.WriteError(w, utils.NewBadRequestError("File must not exceed 5 MB"))
+		return
+	}
+
+	// Trust the bytes, not the client's headers
+	contentType := http.DetectContentType(data)
+	ext, ok := proofContentTypes[contentType]
+	if !ok {
+		utils.WriteError(w, utils.NewBadRequestError("File must be a JPEG or PNG image"))
+		return
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("File is not a valid image"))
+		return
+	}
+
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width > maxProofPixels / cfg.Height {
+		utils.WriteError(w, utils.NewBadRequestError("Image dimensions are too large"))
+		return
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("File is not a valid image"))
+		return
+	}
+
+	var thumb bytes.Buffer
+	if contentType == "image/png" {
+		err = png.Encode(&thumb, utils.Thumbnail(img, proofThumbnailSize))
+	} else {
+		err = jpeg.Encode(&thumb, utils.Thumbnail(img, proofThumbnailSize), &jpeg.Options{Quality: 80})
+	}
+	if err != nil {
+		utils.WriteError(w, utils.NewInternalServerError(err))
+		return
+	}
+
+	// 404 before anything is written to storage
+	if _, err := h.Store.Transaction.GetByID(ctx, tID); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	proof := models.DeliveryProof{
+		ID: uuid.New().String(),
+		TransactionID: tID,
+		DeliveryID: r.FormValue("delivery_id"),
+		Kind: kind,
+		FileName: proofFileName(header.Filename),
+		ContentType: contentType,
+		Size: int64(len(data)),
+	}
+	proof.StorageKey = fmt.Sprintf("proofs/%s/%s%s", tID, proof.ID, ext)
+	proof.ThumbnailKey = fmt.Sprintf("proofs/%s/%s_thumb%s", tID, proof.ID, ext)
+
+	if err := h.Blob.Put(ctx, proof.StorageKey, bytes.NewReader(data)); err != nil {
+		utils.WriteError(w, utils.NewInternalServerError(err))
+		return
+	}
+
+	err = h.Blob.Put(ctx, proof.ThumbnailKey, &thumb)
+	if err == nil {
+		err = h.Store.DeliveryProof.Create(ctx, &proof)
+	}
+	if err != nil {
+		h.deleteBlobs(r, &proof)
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, "Proof of delivery uploaded successfully", proof)
+}
+
+func (h *DeliveryProofHandler) GetProofs(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idStr := chi.URLParam(r, "id")
+
+	// 404 for unknown transactions instead of an empty list
+	if _, err := h.Store.Transaction.GetByID(ctx, idStr); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	proofs, err := h.Store.DeliveryProof.GetByTransaction(ctx, idStr)
+	if err != nil {
+		utils.WriteError(w, utils.NewInternalServerError(err))
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get proofs of delivery", proofs)
+}
+
+// Stream the uploaded file, or its thumbnail with ?thumbnail=true
+func (h *DeliveryProofHandler) DownloadProof(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	proof, err := h.Store.DeliveryProof.GetByID(ctx, chi.URLParam(r, "id"), chi.URLParam(r, "proofID"))
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	key, filename := proof.StorageKey, proof.FileName
+	if r.URL.Query().Get("thumbnail") == "true" {
+		key, filename = proof.ThumbnailKey, "thumb_" + proof.FileName
+	}
+
+	f, err := h.Blob.Get(ctx, key)
+	if errors.Is(err, blob.ErrNotFound) {
+		utils.WriteError(w, utils.NewNotFoundError("File"))
+		return
+	}
+	if err != nil {
+		utils.WriteError(w, utils.NewInternalServerError(err))
+		return
+	}
+	defer f.Close()
+
+	w.Header().Set("Content-Type", proof.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+	io.Copy(w, f)
+}
+
+func (h *DeliveryProofHandler) DeleteProof(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	proof, err := h.Store.DeliveryProof.Delete(ctx, chi.URLParam(r, "id"), chi.URLParam(r, "proofID"))
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	h.deleteBlobs(r, proof)
+
+	utils.WriteJSON(w, http.StatusOK, "Proof of delivery deleted successfully", nil)
+}
+
+// Best effort, a leftover file only costs disk space
+func (h *DeliveryProofHandler) deleteBlobs(r *http.Request, proof *models.DeliveryProof) {
+	for _, key := range []string{proof.StorageKey, proof.ThumbnailKey} {
+		if err := h.Blob.Delete(r.Context(), key); err != nil {
+			log.Printf("Failed to delete blob %s: %v", key, err)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/kevinbrivio/batako-backend/internal/models"
+	"github.com/kevinbrivio/batako-backend/internal/store"
+	"github.com/kevinbrivio/batako-backend/internal/utils"
+)
+
+type EmployeeHandler struct {
+	Store store.Storage
+}
+
+func NewEmployeeHandler(s store.Storage) *EmployeeHandler {
+	return &EmployeeHandler{Store: s}
+}
+
+func (h *EmployeeHandler) CreateEmployee(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// New employees are active unless the request says otherwise
+	req := models.Employee{IsActive: true}
+	if err := utils.ReadJSON(r, &req); err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("Invalid JSON format"))
+		return
+	}
+
+	if utils.CleanName(req.Name) == "" {
+		utils.WriteError(w, utils.NewBadRequestError("Employee name cannot be empty"))
+		return
+	}
+
+	if req.DailyWage < 0 || req.OvertimeRate < 0 {
+		utils.WriteError(w, utils.NewBadRequestError("Wages cannot be negative"))
+		return
+	}
+
+	if err := h.Store.Employee.Create(ctx, &req); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, "Employee created successfully", req)
+}
+
+// ?active=true leaves out employees who no longer work here
+func (h *EmployeeHandler) GetAllEmployees(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Get query params
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1 // default to 1
+	}
+
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+
+	activeOnly, _ := strconv.ParseBool(r.URL.Query().Get("active"))
+
+	// Calculate offset
+	offset := (page - 1) * limit
+
+	employees, totalCount, err := h.Store.Employee.GetAll(ctx, activeOnly, limit, offset)
+	if err != nil {
+		utils.WriteError(w, utils.NewInternalServerError(err))
+		return
+	}
+
+	totalPages := (totalCount + limit - 1) / limit
+
+	response := utils.PaginatedResponse{
+		Items:      employees,
+		Total:      totalCount,
+		Page:       page,
+		PageSize:   limit,
+		TotalPages: totalPages,
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get all employees", response)
+}
+
+func (h *EmployeeHandler) GetEmployee(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idStr := chi.URLParam(r, "id")
+
+	e, err := h.Store.Employee.GetByID(ctx, idStr)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get employee", e)
+}
+
+func (h *EmployeeHandler) UpdateEmployee(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idStr := chi.URLParam(r, "id")
+
+	e := models.Employee{IsActive: true}
+	if err := utils.ReadJSON(r, &e); err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("Invalid JSON Format"))
+		return
+	}
+
+	if utils.CleanName(e.Name) == "" {
+		utils.WriteError(w, utils.NewBadRequestError("Employee name cannot be empty"))
+		return
+	}
+
+	if e.DailyWage < 0 || e.OvertimeRate < 0 {
+		utils.WriteError(w, utils.NewBadRequestError("Wages cannot be negative"))
+		return
+	}
+
+	e.ID = idStr
+
+	if err := h.Store.Employee.Update(ctx, &e); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Employee updated successfully", e)
+}
+
+func (h *EmployeeHandler) DeleteEmployee(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idStr := chi.URLParam(r, "id")
+	if err := h.Store.Employee.Delete(ctx, idStr); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Employee deleted successfully", nil)
+}
\ No newline at end of file
@@ -0,0 +1,356 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/kevinbrivio/batako-backend/internal/models"
+	"github.com/kevinbrivio/batako-backend/internal/store"
+	"github.com/kevinbrivio/batako-backend/internal/utils"
+)
+
+type EquipmentHandler struct {
+	Store store.Storage
+}
+
+func NewEquipmentHandler(s store.Storage) *EquipmentHandler {
+	return &EquipmentHandler{Store: s}
+}
+
+func (h *EquipmentHandler) CreateEquipment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// New machines are in use unless the request says otherwise
+	req := models.Equipment{IsActive: true}
+	if err := utils.ReadJSON(r, &req); err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("Invalid JSON format"))
+		return
+	}
+
+	if err := validateEquipment(&req); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	if err := h.Store.Equipment.Create(ctx, &req); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, "Equipment created successfully", req)
+}
+
+func (h *EquipmentHandler) GetAllEquipment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Get query params
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1 // default to 1
+	}
+
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+
+	// Calculate offset
+	offset := (page - 1) * limit
+
+	equipment, totalCount, err := h.Store.Equipment.GetAll(ctx, limit, offset)
+	if err != nil {
+		utils.WriteError(w, utils.NewInternalServerError(err))
+		return
+	}
+
+	totalPages := (totalCount + limit - 1) / limit
+
+	response := utils.PaginatedResponse{
+		Items:      equipment,
+		Total:      totalCount,
+		Page:       page,
+		PageSize:   limit,
+		TotalPages: totalPages,
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get all equipment", response)
+}
+
+func (h *EquipmentHandler) GetEquipment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	e, err := h.Store.Equipment.GetByID(ctx, chi.URLParam(r, "id"))
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get equipment", e)
+}
+
+func (h *EquipmentHandler) UpdateEquipment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	e := models.Equipment{IsActive: true}
+	if err := utils.ReadJSON(r, &e); err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("Invalid JSON Format"))
+		return
+	}
+
+	if err := validateEquipment(&e); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	e.ID = chi.URLParam(r, "id")
+
+	if err := h.Store.Equipment.Update(ctx, &e); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Equipment updated successfully", e)
+}
+
+func (h *EquipmentHandler) DeleteEquipment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if err := h.Store.Equipment.Delete(ctx, chi.URLParam(r, "id")); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Equipment deleted successfully", nil)
+}
+
+func (h *EquipmentHandler) CreateSchedule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req models.MaintenanceSchedule
+	if err := utils.ReadJSON(r, &req); err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("Invalid JSON format"))
+		return
+	}
+
+	if err := validateMaintenanceSchedule(&req); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	req.EquipmentID = chi.URLParam(r, "id")
+
+	if err := h.Store.Equipment.CreateSchedule(ctx, &req); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, "Maintenance schedule created successfully", req)
+}
+
+func (h *EquipmentHandler) UpdateSchedule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var ms models.MaintenanceSchedule
+	if err := utils.ReadJSON(r, &ms); err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("Invalid JSON Format"))
+		return
+	}
+
+	if err := validateMaintenanceSchedule(&ms); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	ms.ID = chi.URLParam(r, "scheduleID")
+	ms.EquipmentID = chi.URLParam(r, "id")
+
+	if err := h.Store.Equipment.UpdateSchedule(ctx, &ms); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Maintenance schedule updated successfully", ms)
+}
+
+func (h *EquipmentHandler) DeleteSchedule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	err := h.Store.Equipment.DeleteSchedule(ctx, chi.URLParam(r, "id"), chi.URLParam(r, "scheduleID"))
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Maintenance schedule deleted successfully", nil)
+}
+
+func (h *EquipmentHandler) LogMaintenance(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req models.MaintenanceLog
+	if err := utils.ReadJSON(r, &req); err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("Invalid JSON format"))
+		return
+	}
+
+	now := time.Now()
+	if req.PerformedDate.IsZero() {
+		req.PerformedDate = now
+	}
+
+	if req.PerformedDate.After(now) {
+		utils.WriteError(w, utils.NewBadRequestError("Date cannot be in the future"))
+		return
+	}
+
+	if req.Cost < 0 {
+		utils.WriteError(w, utils.NewBadRequestError("Cost cannot be negative"))
+		return
+	}
+
+	req.Task = strings.TrimSpace(req.Task)
+	req.EquipmentID = chi.URLParam(r, "id")
+
+	if err := h.Store.Equipment.LogMaintenance(ctx, &req); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, "Maintenance recorded successfully", req)
+}
+
+func (h *EquipmentHandler) GetMaintenanceLogs(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idStr := chi.URLParam(r, "id")
+
+	// 404 for unknown equipment instead of an empty list
+	if _, err := h.Store.Equipment.GetByID(ctx, idStr); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	// Get query params
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1 // default to 1
+	}
+
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+
+	// Calculate offset
+	offset := (page - 1) * limit
+
+	logs, totalCount, err := h.Store.Equipment.GetMaintenanceLogs(ctx, idStr, limit, offset)
+	if err != nil {
+		utils.WriteError(w, utils.NewInternalServerError(err))
+		return
+	}
+
+	totalPages := (totalCount + limit - 1) / limit
+
+	response := utils.PaginatedResponse{
+		Items:      logs,
+		Total:      totalCount,
+		Page:       page,
+		PageSize:   limit,
+		TotalPages: totalPages,
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get maintenance logs", response)
+}
+
+// Overdue maintenance and what falls due in the next ?days= days (14 by default)
+func (h *EquipmentHandler) GetMaintenanceDue(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	days, err := strconv.Atoi(r.URL.Query().Get("days"))
+	if err != nil || days < 1 {
+		days = 14
+	}
+
+	if days > 90 {
+		utils.WriteError(w, utils.NewBadRequestError("Days cannot be more than 90"))
+		return
+	}
+
+	schedules, err := h.Store.Equipment.GetMaintenanceDue(ctx, time.Now(), days)
+	if err != nil {
+		utils.WriteError(w, utils.NewInternalServerError(err))
+		return
+	}
+
+	var overdue int
+	for _, ms := range schedules {
+		if ms.Status == models.MaintenanceOverdue {
+			overdue++
+		}
+	}
+
+	data := map[string]interface{}{
+		"overdue": overdue,
+		"upcoming": len(schedules) - overdue,
+		"days": days,
+		"schedules": schedules,
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get maintenance due", data)
+}
+
+func validateEquipment(e *models.Equipment) error {
+	if utils.CleanName(e.Name) == "" {
+		return utils.NewBadRequestError("Equipment name cannot be empty")
+	}
+
+	switch e.Type {
+	case models.EquipmentPress, models.EquipmentMold, models.EquipmentMixer, models.EquipmentOther:
+	default:
+		return utils.NewBadRequestError("Type must be press, mold, mixer or other")
+	}
+
+	if e.BlocksPerCycle == 0 {
+		e.BlocksPerCycle = 1
+	}
+
+	if e.BlocksPerCycle < 0 || e.InitialBlocks < 0 {
+		return utils.NewBadRequestError("Counters cannot be negative")
+	}
+
+	if e.CommissionedDate.IsZero() {
+		e.CommissionedDate = time.Now()
+	}
+
+	return nil
+}
+
+func validateMaintenanceSchedule(ms *models.MaintenanceSchedule) error {
+	ms.Task = strings.TrimSpace(ms.Task)
+	if ms.Task == "" {
+		return utils.NewBadRequestError("Task cannot be empty")
+	}
+
+	if ms.IntervalBlocks < 0 || ms.IntervalDays < 0 {
+		return utils.NewBadRequestError("Intervals cannot be negative")
+	}
+
+	if ms.IntervalBlocks == 0 && ms.IntervalDays == 0 {
+		return utils.NewBadRequestError("Set an interval in blocks, in days or both")
+	}
+
+	now := time.Now()
+	if ms.LastDoneDate.IsZero() {
+		ms.LastDoneDate = now
+	}
+
+	if ms.LastDoneDate.After(now) {
+		return utils.NewBadRequestError("Date cannot be in the future")
+	}
+
+	return nil
+}
\ No newline at end of file
@@ -0,0 +1,457 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/kevinbrivio/batako-backend/internal/blob"
+	"github.com/kevinbrivio/batako-backend/internal/models"
+	"github.com/kevinbrivio/batako-backend/internal/store"
+	"github.com/kevinbrivio/batako-backend/internal/utils"
+)
+
+const maxReceiptSize = 5 << 20
+
+// Receipts are photos or scanned PDFs
+var receiptContentTypes = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"application/pdf": ".pdf",
+}
+
+type ExpenseHandler struct {
+	Store store.Storage
+	Blob blob.Storage
+}
+
+func NewExpenseHandler(s store.Storage, b blob.Storage) *ExpenseHandler {
+	return &ExpenseHandler{Store: s, Blob: b}
+}
+
+func (h *ExpenseHandler) CreateExpense(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req models.Expense
+	if err := utils.ReadJSON(r, &req); err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("Invalid JSON format"))
+		return
+	}
+
+	if err := validateExpense(&req); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	if err := h.Store.Expense.Create(ctx, &req); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, "Expense created successfully", req)
+}
+
+func (h *ExpenseHandler) GetAllExpenses(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Get query params
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1 // default to 1
+	}
+
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+
+	// Calculate offset
+	offset := (page - 1) * limit
+
+	expenses, totalCount, err := h.Store.Expense.GetAll(ctx, r.URL.Query().Get("category_id"), limit, offset)
+	if err != nil {
+		utils.WriteError(w, utils.NewInternalServerError(err))
+		return
+	}
+
+	totalPages := (totalCount + limit - 1) / limit
+
+	response := utils.PaginatedResponse{
+		Items:      expenses,
+		Total:      totalCount,
+		Page:       page,
+		PageSize:   limit,
+		TotalPages: totalPages,
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get all expenses", response)
+}
+
+func (h *ExpenseHandler) GetExpensesDaily(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	dt, err := parseDateParam(r, "date", time.Now())
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	expenses, summary, err := h.Store.Expense.GetAllDaily(ctx, dt)
+	if err != nil {
+		utils.WriteError(w, utils.NewInternalServerError(err))
+		return
+	}
+
+	data := map[string]interface{}{
+		"total_count": summary.TotalCount,
+		"total_amount": summary.TotalAmount,
+		"categories": summary.Categories,
+		"date": dt.Format("2006-01-02"),
+		"day": dt.Weekday().String(),
+		"expenses": expenses,
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get daily expenses", data)
+}
+
+// ?page=1 is the current week, 2 the one before and so on
+func (h *ExpenseHandler) GetExpensesWeekly(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1 // default to 1
+	}
+
+	weekOffset := page - 1
+
+	expenses, summary, err := h.Store.Expense.GetAllWeekly(ctx, weekOffset)
+	if err != nil {
+		utils.WriteError(w, utils.NewInternalServerError(err))
+		return
+	}
+
+	start, end := utils.GetWeekRange(time.Now(), weekOffset)
+
+	data := map[string]interface{}{
+		"total_count": summary.TotalCount,
+		"total_amount": summary.TotalAmount,
+		"categories": summary.Categories,
+		"start_date": start.Format("2006-01-02"),
+		"end_date": end.Format("2006-01-02"),
+		"page": page,
+		"expenses": expenses,
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get weekly expenses", data)
+}
+
+func (h *ExpenseHandler) GetExpensesMonthly(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	monthNum, _ := strconv.Atoi(r.URL.Query().Get("month"))
+	currentMonth := int(time.Now().Month())
+	if monthNum < 1 || monthNum > 12 {
+		monthNum = currentMonth
+	}
+	targetOffset := monthNum - currentMonth
+
+	if targetOffset < -6 {
+		targetOffset += 12
+	}
+
+	expenses, summary, err := h.Store.Expense.GetAllMonthly(ctx, targetOffset)
+	if err != nil {
+		utils.WriteError(w, utils.NewInternalServerError(err))
+		return
+	}
+
+	data := map[string]interface{}{
+		"expenses": expenses,
+		"total_count": summary.TotalCount,
+		"total_amount": summary.TotalAmount,
+		"categories": summary.Categories,
+		"month": monthNum,
+		"month_name": time.Month(monthNum).String(),
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get monthly expenses", data)
+}
+
+func (h *ExpenseHandler) GetExpense(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	x, err := h.Store.Expense.GetByID(ctx, chi.URLParam(r, "id"))
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get expense", x)
+}
+
+func (h *ExpenseHandler) UpdateExpense(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var x models.Expense
+	if err := utils.ReadJSON(r, &x); err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("Invalid JSON Format"))
+		return
+	}
+
+	if err := validateExpense(&x); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	x.ID = chi.URLParam(r, "id")
+
+	if err := h.Store.Expense.Update(ctx, &x); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Expense updated successfully", x)
+}
+
+func (h *ExpenseHandler) DeleteExpense(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	x, err := h.Store.Expense.Delete(ctx, chi.URLParam(r, "id"))
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	h.deleteReceipt(r, x.ReceiptKey)
+
+	utils.WriteJSON(w, http.StatusOK, "Expense deleted successfully", nil)
+}
+
+// Attach a receipt to an expense, replacing the one it had
+func (h *ExpenseHandler) UploadReceipt(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Leave some room for the other form fields
+	r.Body = http.MaxBytesReader(w, r.Body, maxReceiptSize + 1 << 20)
+	if err := r.ParseMultipartForm(maxReceiptSize); err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("Invalid upload, expected a multipart form with a file of at most 5 MB"))
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("File is required"))
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxReceiptSize + 1))
+	if err != nil {
+		utils.WriteError(w, utils.NewInternalServerError(err))
+		return
+	}
+
+	if len(data) > maxReceiptSize {
+		utils.WriteError(w, utils.NewBadRequestError("File must not exceed 5 MB"))
+		return
+	}
+
+	// Trust the bytes, not the client's headers
+	contentType := http.DetectContentType(data)
+	ext, ok := receiptContentTypes[contentType]
+	if !ok {
+		utils.WriteError(w, utils.NewBadRequestError("File must be a JPEG, PNG or PDF"))
+		return
+	}
+
+	xID := chi.URLParam(r, "id")
+
+	// 404 before anything is written to storage
+	x, err := h.Store.Expense.GetByID(ctx, xID)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	x.ReceiptFileName = filepath.Base(header.Filename)
+	x.ReceiptContentType = contentType
+	x.ReceiptKey = fmt.Sprintf("receipts/%s/%s%s", xID, uuid.New().String(), ext)
+
+	if err := h.Blob.Put(ctx, x.ReceiptKey, bytes.NewReader(data)); err != nil {
+		utils.WriteError(w, utils.NewInternalServerError(err))
+		return
+	}
+
+	oldKey, err := h.Store.Expense.SetReceipt(ctx, x)
+	if err != nil {
+		h.deleteReceipt(r, x.ReceiptKey)
+		utils.WriteError(w, err)
+		return
+	}
+
+	h.deleteReceipt(r, oldKey)
+	x.HasReceipt = true
+
+	utils.WriteJSON(w, http.StatusOK, "Receipt uploaded successfully", x)
+}
+
+func (h *ExpenseHandler) DownloadReceipt(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	x, err := h.Store.Expense.GetByID(ctx, chi.URLParam(r, "id"))
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	if !x.HasReceipt {
+		utils.WriteError(w, utils.NewNotFoundError("Receipt"))
+		return
+	}
+
+	f, err := h.Blob.Get(ctx, x.ReceiptKey)
+	if errors.Is(err, blob.ErrNotFound) {
+		utils.WriteError(w, utils.NewNotFoundError("File"))
+		return
+	}
+	if err != nil {
+		utils.WriteError(w, utils.NewInternalServerError(err))
+		return
+	}
+	defer f.Close()
+
+	w.Header().Set("Content-Type", x.ReceiptContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", x.ReceiptFileName))
+	w.WriteHeader(http.StatusOK)
+	io.Copy(w, f)
+}
+
+func (h *ExpenseHandler) DeleteReceipt(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	key, err := h.Store.Expense.ClearReceipt(ctx, chi.URLParam(r, "id"))
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	h.deleteReceipt(r, key)
+
+	utils.WriteJSON(w, http.StatusOK, "Receipt deleted successfully", nil)
+}
+
+func (h *ExpenseHandler) CreateCategory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req models.ExpenseCategory
+	if err := utils.ReadJSON(r, &req); err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("Invalid JSON format"))
+		return
+	}
+
+	if utils.CleanName(req.Name) == "" {
+		utils.WriteError(w, utils.NewBadRequestError("Category name cannot be empty"))
+		return
+	}
+
+	if err := h.Store.Expense.CreateCategory(ctx, &req); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, "Expense category created successfully", req)
+}
+
+func (h *ExpenseHandler) GetCategories(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	categories, err := h.Store.Expense.GetCategories(ctx)
+	if err != nil {
+		utils.WriteError(w, utils.NewInternalServerError(err))
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get expense categories", categories)
+}
+
+func (h *ExpenseHandler) UpdateCategory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var c models.ExpenseCategory
+	if err := utils.ReadJSON(r, &c); err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("Invalid JSON Format"))
+		return
+	}
+
+	if utils.CleanName(c.Name) == "" {
+		utils.WriteError(w, utils.NewBadRequestError("Category name cannot be empty"))
+		return
+	}
+
+	c.ID = chi.URLParam(r, "categoryID")
+
+	if err := h.Store.Expense.UpdateCategory(ctx, &c); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Expense category updated successfully", c)
+}
+
+func (h *ExpenseHandler) DeleteCategory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if err := h.Store.Expense.DeleteCategory(ctx, chi.URLParam(r, "categoryID")); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Expense category deleted successfully", nil)
+}
+
+// Best effort, a leftover file only costs disk space
+func (h *ExpenseHandler) deleteReceipt(r *http.Request, key string) {
+	if key == "" {
+		return
+	}
+
+	if err := h.Blob.Delete(r.Context(), key); err != nil {
+		log.Printf("Failed to delete blob %s: %v", key, err)
+	}
+}
+
+func validateExpense(x *models.Expense) error {
+	if x.CategoryID == "" {
+		return utils.NewBadRequestError("Category cannot be empty")
+	}
+
+	if x.Amount <= 0 {
+		return utils.NewBadRequestError("Amount must be greater than 0")
+	}
+
+	if x.Unpaid && x.SupplierID == "" {
+		return utils.NewBadRequestError("An unpaid expense needs a supplier")
+	}
+
+	now := time.Now()
+	if x.ExpenseDate.IsZero() {
+		x.ExpenseDate = now
+	}
+
+	if x.ExpenseDate.After(now) {
+		return utils.NewBadRequestError("Date cannot be in the future")
+	}
+
+	x.Description = strings.TrimSpace(x.Description)
+	return nil
+}
\ No newline at end of file
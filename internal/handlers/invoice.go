@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/kevinbrivio/batako-backend/internal/models"
+	"github.com/kevinbrivio/batako-backend/internal/pdf"
+	"github.com/kevinbrivio/batako-backend/internal/store"
+	"github.com/kevinbrivio/batako-backend/internal/utils"
+)
+
+type InvoiceHandler struct {
+	Store store.Storage
+}
+
+func NewInvoiceHandler(s store.Storage) *InvoiceHandler {
+	return &InvoiceHandler{Store: s}
+}
+
+// Numbers are only used up here, on purpose; reading an invoice never issues one
+func (h *InvoiceHandler) IssueInvoice(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	inv, err := h.Store.Invoice.Issue(ctx, chi.URLParam(r, "id"))
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, "Invoice issued successfully", inv)
+}
+
+func (h *InvoiceHandler) GetInvoice(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	inv, err := h.Store.Invoice.GetByTransaction(ctx, chi.URLParam(r, "id"))
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get invoice", inv)
+}
+
+func (h *InvoiceHandler) GetInvoicePDF(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idStr := chi.URLParam(r, "id")
+
+	inv, err := h.Store.Invoice.GetByTransaction(ctx, idStr)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	t, err := h.Store.Transaction.GetByID(ctx, idStr)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	certificates, err := h.Store.Quality.GetByTransaction(ctx, idStr)
+	if err != nil {
+		utils.WriteError(w, utils.NewInternalServerError(err))
+		return
+	}
+
+	// Test certificates of the attached batches follow the invoice
+	doc := renderInvoice(inv, t)
+	for i := range certificates {
+		addCertificatePage(doc, &certificates[i])
+	}
+
+	filename := strings.ReplaceAll(inv.InvoiceNumber, "/", "-") + ".pdf"
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=\"%s\"", filename))
+	w.WriteHeader(http.StatusOK)
+	doc.WriteTo(w)
+}
+
+// Lowest line the items and totals may use, clear of the footer, and the room
+// the totals block below the items needs
+const (
+	invoiceBottom = pdf.PageHeight - 80
+	invoiceTotalsHeight = 140.0
+)
+
+// Draw the items table header at y and return where the first row goes
+func invoiceItemsHeader(page *pdf.Page, y float64) float64 {
+	left, right := 50.0, pdf.PageWidth - 50
+
+	page.Line(left, y, right, y)
+	page.Text(left + 5, y + 15, 10, true, "Description")
+	page.TextRight(290, y + 15, 10, true, "Quantity")
+	page.TextRight(380, y + 15, 10, true, "Unit price")
+	page.TextRight(460, y + 15, 10, true, "Discount")
+	page.TextRight(right - 5, y + 15, 10, true, "Amount")
+	page.Line(left, y + 22, right, y + 22)
+
+	return y + 22
+}
+
+func renderInvoice(inv *models.Invoice, t *models.Transaction) *pdf.Document {
+	doc := pdf.New()
+	page := doc.AddPage()
+
+	left, right := 50.0, pdf.PageWidth - 50
+
+	page.Text(left, 60, 18, true, companyName())
+	page.TextRight(right, 60, 18, true, "INVOICE")
+	page.Line(left, 75, right, 75)
+
+	page.Text(left, 100, 10, true, "Bill to")
+	page.Text(left, 116, 10, false, t.Customer)
+	page.Text(left, 130, 10, false, t.Address)
+
+	page.TextRight(right - 110, 100, 10, true, "Invoice no.")
+	page.TextRight(right, 100, 10, false, inv.InvoiceNumber)
+	page.TextRight(right - 110, 116, 10, true, "Invoice date")
+	page.TextRight(right, 116, 10, false, inv.IssueDate.Format("02 Jan 2006"))
+	page.TextRight(right - 110, 130, 10, true, "Purchase date")
+	page.TextRight(right, 130, 10, false, t.PurchaseDate.Format("02 Jan 2006"))
+
+	// Items table, carried over to a new page with its header when the page is full
+	y := invoiceItemsHeader(page, 170)
+	for _, item := range t.Items {
+		if y + 18 > invoiceBottom {
+			page.Line(left, y + 10, right, y + 10)
+			page = doc.AddPage()
+			y = invoiceItemsHeader(page, 60)
+		}
+
+		y += 18
+		page.Text(left + 5, y, 10, false, itemDescription(item))
+		page.TextRight(290, y, 10, false, strconv.Itoa(item.Quantity))
+		page.TextRight(380, y, 10, false, utils.FormatRupiah(item.UnitPrice))
+		if item.Discount > 0 {
+			page.TextRight(460, y, 10, false, utils.FormatRupiah(item.Discount))
+		}
+		page.TextRight(right - 5, y, 10, false, utils.FormatRupiah(item.LineTotal))
+	}
+	page.Line(left, y + 10, right, y + 10)
+
+	// Keep the totals block together
+	if y + invoiceTotalsHeight > invoiceBottom {
+		page = doc.AddPage()
+		y = 40
+	}
+
+	y += 30
+	if t.TaxAmount > 0 {
+		taxLabel := "PPN " + strconv.FormatFloat(t.TaxRate, 'f', -1, 64) + "%"
+		if t.TaxInclusive {
+			taxLabel += " (included)"
+		}
+		page.TextRight(430, y, 10, false, "Subtotal")
+		page.TextRight(right - 5, y, 10, false, utils.FormatRupiah(t.Subtotal))
+		page.TextRight(430, y + 16, 10, false, taxLabel)
+		page.TextRight(right - 5, y + 16, 10, false, utils.FormatRupiah(t.TaxAmount))
+		y += 32
+	}
+	page.TextRight(430, y, 10, true, "Total")
+	page.TextRight(right - 5, y, 10, true, utils.FormatRupiah(t.TotalPrice))
+	page.TextRight(430, y + 16, 10, false, "Paid")
+	page.TextRight(right - 5, y + 16, 10, false, utils.FormatRupiah(t.PaidAmount))
+	page.TextRight(430, y + 32, 10, true, "Balance due")
+	page.TextRight(right - 5, y + 32, 10, true, utils.FormatRupiah(t.Balance))
+
+	if t.TaxExempt {
+		page.Text(left, y + 60, 9, false, "Customer is exempt from PPN")
+	}
+
+	page.Text(left, pdf.PageHeight - 60, 8, false, "Transaction "+t.ID)
+
+	return doc
+}
+
+// Product name, with the line's own description when it has one
+func itemDescription(item models.TransactionItem) string {
+	switch {
+	case item.Product == "":
+		return item.Description
+	case item.Description == "":
+		return item.Product
+	default:
+		return item.Product + " - " + item.Description
+	}
+}
+
+func companyName() string {
+	if name := os.Getenv("COMPANY_NAME"); name != "" {
+		return name
+	}
+	return "Batako"
+}
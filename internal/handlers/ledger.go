@@ -0,0 +1,288 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/kevinbrivio/batako-backend/internal/models"
+	"github.com/kevinbrivio/batako-backend/internal/store"
+	"github.com/kevinbrivio/batako-backend/internal/utils"
+)
+
+type LedgerHandler struct {
+	Store store.Storage
+}
+
+func NewLedgerHandler(s store.Storage) *LedgerHandler {
+	return &LedgerHandler{Store: s}
+}
+
+func (h *LedgerHandler) CreateAccount(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req models.Account
+	if err := utils.ReadJSON(r, &req); err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("Invalid JSON format"))
+		return
+	}
+
+	if err := validateAccount(&req); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	if err := h.Store.Ledger.CreateAccount(ctx, &req); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, "Account created successfully", req)
+}
+
+func (h *LedgerHandler) GetAccounts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	accounts, err := h.Store.Ledger.GetAccounts(ctx)
+	if err != nil {
+		utils.WriteError(w, utils.NewInternalServerError(err))
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get accounts", accounts)
+}
+
+func (h *LedgerHandler) UpdateAccount(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var a models.Account
+	if err := utils.ReadJSON(r, &a); err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("Invalid JSON Format"))
+		return
+	}
+
+	if err := validateAccount(&a); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	a.ID = chi.URLParam(r, "id")
+
+	if err := h.Store.Ledger.UpdateAccount(ctx, &a); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Account updated successfully", a)
+}
+
+func (h *LedgerHandler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if err := h.Store.Ledger.DeleteAccount(ctx, chi.URLParam(r, "id")); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Account deleted successfully", nil)
+}
+
+// Postings to one account, ?from= defaults to the start of the month
+func (h *LedgerHandler) GetAccountLedger(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	from, to, err := parseLedgerRange(r)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	ledger, err := h.Store.Ledger.GetAccountLedger(ctx, chi.URLParam(r, "id"), from, to)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	data := map[string]interface{}{
+		"account":         ledger.Account,
+		"opening_balance": ledger.OpeningBalance,
+		"lines":           ledger.Lines,
+		"total_debit":     ledger.TotalDebit,
+		"total_credit":    ledger.TotalCredit,
+		"closing_balance": ledger.ClosingBalance,
+		"from":            from.Format("2006-01-02"),
+		"to":              to.Format("2006-01-02"),
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get general ledger", data)
+}
+
+// Manual entries only; sales, payments, receipts and expenses post themselves
+func (h *LedgerHandler) CreateEntry(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req models.JournalEntry
+	if err := utils.ReadJSON(r, &req); err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("Invalid JSON format"))
+		return
+	}
+
+	if req.EntryDate.IsZero() {
+		utils.WriteError(w, utils.NewBadRequestError("Entry date is required"))
+		return
+	}
+
+	req.Description = strings.TrimSpace(req.Description)
+	if req.Description == "" {
+		utils.WriteError(w, utils.NewBadRequestError("Description cannot be empty"))
+		return
+	}
+
+	if err := h.Store.Ledger.CreateEntry(ctx, &req); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, "Journal entry created successfully", req)
+}
+
+func (h *LedgerHandler) GetEntries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Get query params
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1 // default to 1
+	}
+
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+
+	// Calculate offset
+	offset := (page - 1) * limit
+
+	from, to, err := parseLedgerRange(r)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	entries, totalCount, err := h.Store.Ledger.GetEntries(ctx, from, to, r.URL.Query().Get("source_type"), limit, offset)
+	if err != nil {
+		utils.WriteError(w, utils.NewInternalServerError(err))
+		return
+	}
+
+	totalPages := (totalCount + limit - 1) / limit
+
+	response := utils.PaginatedResponse{
+		Items:      entries,
+		Total:      totalCount,
+		Page:       page,
+		PageSize:   limit,
+		TotalPages: totalPages,
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get journal entries", response)
+}
+
+func (h *LedgerHandler) GetEntry(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	e, err := h.Store.Ledger.GetEntryByID(ctx, chi.URLParam(r, "id"))
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get journal entry", e)
+}
+
+func (h *LedgerHandler) ReverseEntry(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	reversal, err := h.Store.Ledger.ReverseEntry(ctx, chi.URLParam(r, "id"))
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, "Journal entry reversed successfully", reversal)
+}
+
+// Balances as of ?date=, today by default
+func (h *LedgerHandler) GetTrialBalance(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	date, err := parseDateParam(r, "date", time.Now())
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	tb, err := h.Store.Ledger.GetTrialBalance(ctx, date)
+	if err != nil {
+		utils.WriteError(w, utils.NewInternalServerError(err))
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get trial balance", tb)
+}
+
+func (h *LedgerHandler) GetBalanceSheet(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	date, err := parseDateParam(r, "date", time.Now())
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	bs, err := h.Store.Ledger.GetBalanceSheet(ctx, date)
+	if err != nil {
+		utils.WriteError(w, utils.NewInternalServerError(err))
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get balance sheet", bs)
+}
+
+func validateAccount(a *models.Account) error {
+	if strings.TrimSpace(a.Code) == "" {
+		return utils.NewBadRequestError("Account code cannot be empty")
+	}
+
+	if utils.CleanName(a.Name) == "" {
+		return utils.NewBadRequestError("Account name cannot be empty")
+	}
+
+	switch a.Type {
+	case models.AccountAsset, models.AccountLiability, models.AccountEquity, models.AccountRevenue, models.AccountExpense:
+		return nil
+	default:
+		return utils.NewBadRequestError("Account type must be asset, liability, equity, revenue or expense")
+	}
+}
+
+// ?from= and ?to=, defaulting to the current month so far
+func parseLedgerRange(r *http.Request) (time.Time, time.Time, error) {
+	now := time.Now()
+	from, err := parseDateParam(r, "from", time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()))
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	to, err := parseDateParam(r, "to", now)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	if to.Before(from) {
+		return time.Time{}, time.Time{}, utils.NewBadRequestError("to cannot be before from")
+	}
+	return from, to, nil
+}
\ No newline at end of file
@@ -0,0 +1,280 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/kevinbrivio/batako-backend/internal/models"
+	"github.com/kevinbrivio/batako-backend/internal/store"
+	"github.com/kevinbrivio/batako-backend/internal/utils"
+)
+
+type MaterialHandler struct {
+	Store store.Storage
+}
+
+func NewMaterialHandler(s store.Storage) *MaterialHandler {
+	return &MaterialHandler{Store: s}
+}
+
+func (h *MaterialHandler) CreateMaterial(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req models.Material
+	if err := utils.ReadJSON(r, &req); err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("Invalid JSON format"))
+		return
+	}
+
+	if err := validateMaterial(&req); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	if err := h.Store.Material.Create(ctx, &req); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, "Material created successfully", req)
+}
+
+func (h *MaterialHandler) GetAllMaterials(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Get query params
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1 // default to 1
+	}
+
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+
+	// Calculate offset
+	offset := (page - 1) * limit
+
+	materials, totalCount, err := h.Store.Material.GetAll(ctx, limit, offset)
+	if err != nil {
+		utils.WriteError(w, utils.NewInternalServerError(err))
+		return
+	}
+
+	totalPages := (totalCount + limit - 1) / limit
+
+	response := utils.PaginatedResponse{
+		Items:      materials,
+		Total:      totalCount,
+		Page:       page,
+		PageSize:   limit,
+		TotalPages: totalPages,
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get all materials", response)
+}
+
+func (h *MaterialHandler) GetLowStockMaterials(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	materials, err := h.Store.Material.GetLowStock(ctx)
+	if err != nil {
+		utils.WriteError(w, utils.NewInternalServerError(err))
+		return
+	}
+
+	data := map[string]interface{}{
+		"materials":   materials,
+		"total_count": len(materials),
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get low stock materials", data)
+}
+
+func (h *MaterialHandler) GetMaterial(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idStr := chi.URLParam(r, "id")
+
+	m, err := h.Store.Material.GetByID(ctx, idStr)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get material", m)
+}
+
+func (h *MaterialHandler) UpdateMaterial(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idStr := chi.URLParam(r, "id")
+
+	var m models.Material
+	if err := utils.ReadJSON(r, &m); err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("Invalid JSON Format"))
+		return
+	}
+
+	if err := validateMaterial(&m); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	m.ID = idStr
+
+	if err := h.Store.Material.Update(ctx, &m); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Material updated successfully", m)
+}
+
+func (h *MaterialHandler) DeleteMaterial(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idStr := chi.URLParam(r, "id")
+	if err := h.Store.Material.Delete(ctx, idStr); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Material deleted successfully", nil)
+}
+
+func (h *MaterialHandler) CreateReceipt(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req models.MaterialReceipt
+	if err := utils.ReadJSON(r, &req); err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("Invalid JSON format"))
+		return
+	}
+
+	if req.Quantity <= 0 {
+		utils.WriteError(w, utils.NewBadRequestError("Quantity must be greater than 0"))
+		return
+	}
+
+	if req.UnitCost < 0 {
+		utils.WriteError(w, utils.NewBadRequestError("Unit cost cannot be negative"))
+		return
+	}
+
+	if req.ReceivedDate.IsZero() {
+		req.ReceivedDate = time.Now()
+	}
+
+	if req.ReceivedDate.After(time.Now()) {
+		utils.WriteError(w, utils.NewBadRequestError("Date cannot be in the future"))
+		return
+	}
+
+	// Receipts against a purchase order go through /purchase-orders/{id}/receipts
+	req.MaterialID = chi.URLParam(r, "id")
+	req.PurchaseOrderItemID = ""
+
+	if err := h.Store.Material.CreateReceipt(ctx, &req); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, "Receipt recorded successfully", req)
+}
+
+func (h *MaterialHandler) GetReceipts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Get query params
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1 // default to 1
+	}
+
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+
+	// Calculate offset
+	offset := (page - 1) * limit
+
+	receipts, totalCount, err := h.Store.Material.GetReceipts(ctx, chi.URLParam(r, "id"), limit, offset)
+	if err != nil {
+		utils.WriteError(w, utils.NewInternalServerError(err))
+		return
+	}
+
+	totalPages := (totalCount + limit - 1) / limit
+
+	response := utils.PaginatedResponse{
+		Items:      receipts,
+		Total:      totalCount,
+		Page:       page,
+		PageSize:   limit,
+		TotalPages: totalPages,
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get material receipts", response)
+}
+
+func (h *MaterialHandler) GetMovements(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Get query params
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1 // default to 1
+	}
+
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit < 1 {
+		limit = 20
+	}
+
+	// Calculate offset
+	offset := (page - 1) * limit
+
+	movements, totalCount, err := h.Store.Material.GetMovements(ctx, chi.URLParam(r, "id"), limit, offset)
+	if err != nil {
+		utils.WriteError(w, utils.NewInternalServerError(err))
+		return
+	}
+
+	totalPages := (totalCount + limit - 1) / limit
+
+	response := utils.PaginatedResponse{
+		Items:      movements,
+		Total:      totalCount,
+		Page:       page,
+		PageSize:   limit,
+		TotalPages: totalPages,
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get material movements", response)
+}
+
+func validateMaterial(m *models.Material) error {
+	if strings.TrimSpace(m.Code) == "" {
+		return utils.NewBadRequestError("Material code cannot be empty")
+	}
+
+	if strings.TrimSpace(m.Name) == "" {
+		return utils.NewBadRequestError("Material name cannot be empty")
+	}
+
+	if strings.TrimSpace(m.Unit) == "" {
+		return utils.NewBadRequestError("Unit cannot be empty")
+	}
+
+	if m.LowStockThreshold < 0 {
+		return utils.NewBadRequestError("Low stock threshold cannot be negative")
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/kevinbrivio/batako-backend/internal/models"
+	"github.com/kevinbrivio/batako-backend/internal/store"
+	"github.com/kevinbrivio/batako-backend/internal/utils"
+)
+
+type PaymentHandler struct {
+	Store store.Storage
+}
+
+func NewPaymentHandler(s store.Storage) *PaymentHandler {
+	return &PaymentHandler{Store: s}
+}
+
+func (h *PaymentHandler) CreatePayment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req models.Payment
+	if err := utils.ReadJSON(r, &req); err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("Invalid JSON format"))
+		return
+	}
+
+	if req.Amount <= 0 {
+		utils.WriteError(w, utils.NewBadRequestError("Amount must be greater than 0"))
+		return
+	}
+
+	switch req.Method {
+	case "":
+		req.Method = models.PaymentMethodCash
+	case models.PaymentMethodCash, models.PaymentMethodTransfer, models.PaymentMethodOther:
+	default:
+		utils.WriteError(w, utils.NewBadRequestError("Method must be cash, transfer or other"))
+		return
+	}
+
+	if req.PaymentDate.IsZero() {
+		req.PaymentDate = time.Now()
+	}
+
+	if req.PaymentDate.After(time.Now()) {
+		utils.WriteError(w, utils.NewBadRequestError("Date cannot be in the future"))
+		return
+	}
+
+	req.TransactionID = chi.URLParam(r, "id")
+
+	if err := h.Store.Payment.Create(ctx, &req); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, "Payment recorded successfully", req)
+}
+
+func (h *PaymentHandler) GetPayments(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idStr := chi.URLParam(r, "id")
+
+	// 404 for unknown transactions instead of an empty list
+	t, err := h.Store.Transaction.GetByID(ctx, idStr)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	payments, err := h.Store.Payment.GetByTransaction(ctx, idStr)
+	if err != nil {
+		utils.WriteError(w, utils.NewInternalServerError(err))
+		return
+	}
+
+	data := map[string]interface{}{
+		"payments":       payments,
+		"total_price":    t.TotalPrice,
+		"paid_amount":    t.PaidAmount,
+		"balance":        t.Balance,
+		"payment_status": t.PaymentStatus,
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get payments", data)
+}
+
+func (h *PaymentHandler) DeletePayment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	err := h.Store.Payment.Delete(ctx, chi.URLParam(r, "id"), chi.URLParam(r, "paymentID"))
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Payment deleted successfully", nil)
+}
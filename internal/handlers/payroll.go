@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/kevinbrivio/batako-backend/internal/store"
+	"github.com/kevinbrivio/batako-backend/internal/utils"
+)
+
+type PayrollHandler struct {
+	Store store.Storage
+}
+
+func NewPayrollHandler(s store.Storage) *PayrollHandler {
+	return &PayrollHandler{Store: s}
+}
+
+// Piece-rate pay per worker for a month ("2026-10") or ISO week ("2026-W42").
+// Shows the approved run when there is one, otherwise a draft.
+func (h *PayrollHandler) GetPayrollPeriod(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	period := chi.URLParam(r, "period")
+	start, end, err := utils.ParsePeriod(period, time.Now().Location())
+	if err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("Invalid period, expected YYYY-MM or YYYY-Www"))
+		return
+	}
+
+	run, err := h.Store.Payroll.GetPeriod(ctx, period, start, end)
+	if err != nil {
+		utils.WriteError(w, utils.NewInternalServerError(err))
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get payroll", run)
+}
+
+func (h *PayrollHandler) ApprovePayroll(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	period := chi.URLParam(r, "period")
+	start, end, err := utils.ParsePeriod(period, time.Now().Location())
+	if err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("Invalid period, expected YYYY-MM or YYYY-Www"))
+		return
+	}
+
+	run, err := h.Store.Payroll.Approve(ctx, period, start, end)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, "Payroll approved successfully", run)
+}
+
+func (h *PayrollHandler) PayPayroll(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	run, err := h.Store.Payroll.MarkPaid(ctx, chi.URLParam(r, "period"))
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Payroll marked as paid", run)
+}
+
+// Unlock an approved but unpaid period so its productions can be corrected
+func (h *PayrollHandler) ReopenPayroll(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if err := h.Store.Payroll.Reopen(ctx, chi.URLParam(r, "period")); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Payroll reopened successfully", nil)
+}
\ No newline at end of file
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/kevinbrivio/batako-backend/internal/models"
+	"github.com/kevinbrivio/batako-backend/internal/store"
+	"github.com/kevinbrivio/batako-backend/internal/utils"
+)
+
+type PriceListHandler struct {
+	Store store.Storage
+}
+
+func NewPriceListHandler(s store.Storage) *PriceListHandler {
+	return &PriceListHandler{Store: s}
+}
+
+func (h *PriceListHandler) CreatePrice(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req models.PriceList
+	if err := utils.ReadJSON(r, &req); err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("Invalid JSON format"))
+		return
+	}
+
+	if req.UnitPrice <= 0 {
+		utils.WriteError(w, utils.NewBadRequestError("Unit price must be greater than 0"))
+		return
+	}
+
+	if req.EffectiveFrom.IsZero() {
+		utils.WriteError(w, utils.NewBadRequestError("Effective date cannot be empty"))
+		return
+	}
+
+	if err := h.Store.PriceList.Create(ctx, &req); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, "Price created successfully", req)
+}
+
+func (h *PriceListHandler) GetAllPrices(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Get query params
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1 // default to 1
+	}
+
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+
+	// Calculate offset
+	offset := (page - 1) * limit
+
+	productID := r.URL.Query().Get("product_id")
+
+	prices, totalCount, err := h.Store.PriceList.GetAll(ctx, productID, limit, offset)
+	if err != nil {
+		utils.WriteError(w, utils.NewInternalServerError(err))
+		return
+	}
+
+	totalPages := (totalCount + limit - 1) / limit
+
+	response := utils.PaginatedResponse{
+		Items:      prices,
+		Total:      totalCount,
+		Page:       page,
+		PageSize:   limit,
+		TotalPages: totalPages,
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get all prices", response)
+}
+
+func (h *PriceListHandler) GetCurrentPrice(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Defaults to today when no valid date is given
+	dt := time.Now()
+	if dateStr := r.URL.Query().Get("date"); dateStr != "" {
+		parsed, err := time.Parse("2006-01-02", dateStr)
+		if err != nil {
+			utils.WriteError(w, utils.NewBadRequestError("Invalid date format, expected YYYY-MM-DD"))
+			return
+		}
+		dt = parsed
+	}
+
+	p, err := h.Store.PriceList.GetEffective(ctx, r.URL.Query().Get("product_id"), dt)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get current price", p)
+}
+
+func (h *PriceListHandler) GetPrice(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idStr := chi.URLParam(r, "id")
+
+	p, err := h.Store.PriceList.GetByID(ctx, idStr)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get price", p)
+}
+
+func (h *PriceListHandler) UpdatePrice(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idStr := chi.URLParam(r, "id")
+
+	var p models.PriceList
+	if err := utils.ReadJSON(r, &p); err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("Invalid JSON Format"))
+		return
+	}
+
+	if p.UnitPrice <= 0 {
+		utils.WriteError(w, utils.NewBadRequestError("Unit price must be greater than 0"))
+		return
+	}
+
+	if p.EffectiveFrom.IsZero() {
+		utils.WriteError(w, utils.NewBadRequestError("Effective date cannot be empty"))
+		return
+	}
+
+	p.ID = idStr
+
+	if err := h.Store.PriceList.Update(ctx, &p); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Price updated successfully", p)
+}
+
+func (h *PriceListHandler) DeletePrice(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idStr := chi.URLParam(r, "id")
+	if err := h.Store.PriceList.Delete(ctx, idStr); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Price deleted successfully", nil)
+}
@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/kevinbrivio/batako-backend/internal/models"
+	"github.com/kevinbrivio/batako-backend/internal/store"
+	"github.com/kevinbrivio/batako-backend/internal/utils"
+)
+
+type ProductHandler struct {
+	Store store.Storage
+}
+
+func NewProductHandler(s store.Storage) *ProductHandler {
+	return &ProductHandler{Store: s}
+}
+
+func (h *ProductHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req models.Product
+	if err := utils.ReadJSON(r, &req); err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("Invalid JSON format"))
+		return
+	}
+
+	if err := validateProduct(&req); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	if err := h.Store.Product.Create(ctx, &req); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, "Product created successfully", req)
+}
+
+func (h *ProductHandler) GetAllProducts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Get query params
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1 // default to 1
+	}
+
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+
+	// Calculate offset
+	offset := (page - 1) * limit
+
+	products, totalCount, err := h.Store.Product.GetAll(ctx, limit, offset)
+	if err != nil {
+		utils.WriteError(w, utils.NewInternalServerError(err))
+		return
+	}
+
+	totalPages := (totalCount + limit - 1) / limit
+
+	response := utils.PaginatedResponse{
+		Items:      products,
+		Total:      totalCount,
+		Page:       page,
+		PageSize:   limit,
+		TotalPages: totalPages,
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get all products", response)
+}
+
+func (h *ProductHandler) GetProduct(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idStr := chi.URLParam(r, "id")
+
+	p, err := h.Store.Product.GetByID(ctx, idStr)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get product", p)
+}
+
+func (h *ProductHandler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idStr := chi.URLParam(r, "id")
+
+	var p models.Product
+	if err := utils.ReadJSON(r, &p); err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("Invalid JSON Format"))
+		return
+	}
+
+	if err := validateProduct(&p); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	p.ID = idStr
+
+	if err := h.Store.Product.Update(ctx, &p); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Product updated successfully", p)
+}
+
+func (h *ProductHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idStr := chi.URLParam(r, "id")
+	if err := h.Store.Product.Delete(ctx, idStr); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Product deleted successfully", nil)
+}
+
+func validateProduct(p *models.Product) error {
+	if utils.CleanName(p.Name) == "" {
+		return utils.NewBadRequestError("Product name cannot be empty")
+	}
+
+	if p.LengthMM < 0 || p.WidthMM < 0 || p.HeightMM < 0 {
+		return utils.NewBadRequestError("Dimensions cannot be negative")
+	}
+
+	if strings.TrimSpace(p.Unit) == "" {
+		p.Unit = "pcs"
+	}
+
+	if p.DefaultPrice < 0 {
+		return utils.NewBadRequestError("Default price cannot be negative")
+	}
+
+	if p.CuringDays < 0 {
+		return utils.NewBadRequestError("Curing days cannot be negative")
+	}
+
+	p.StrengthClass = strings.TrimSpace(p.StrengthClass)
+	if p.MinStrength < 0 {
+		return utils.NewBadRequestError("Minimum strength cannot be negative")
+	}
+
+	for _, item := range p.Recipe {
+		if item.MaterialID == "" || item.QuantityPerUnit <= 0 {
+			return utils.NewBadRequestError("Each recipe line needs a material and a quantity above 0")
+		}
+	}
+
+	return nil
+}
+
+
+func (h *ProductHandler) CreatePieceRate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req models.PieceRate
+	if err := utils.ReadJSON(r, &req); err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("Invalid JSON format"))
+		return
+	}
+
+	if req.Rate < 0 {
+		utils.WriteError(w, utils.NewBadRequestError("Rate cannot be negative"))
+		return
+	}
+
+	if req.EffectiveFrom.IsZero() {
+		utils.WriteError(w, utils.NewBadRequestError("Effective date cannot be empty"))
+		return
+	}
+
+	req.ProductID = chi.URLParam(r, "id")
+
+	if err := h.Store.Product.CreatePieceRate(ctx, &req); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, "Piece rate created successfully", req)
+}
+
+func (h *ProductHandler) GetPieceRates(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	rates, err := h.Store.Product.GetPieceRates(ctx, chi.URLParam(r, "id"))
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get piece rates", rates)
+}
+
+func (h *ProductHandler) DeletePieceRate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	err := h.Store.Product.DeletePieceRate(ctx, chi.URLParam(r, "id"), chi.URLParam(r, "rateID"))
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Piece rate deleted successfully", nil)
+}
\ No newline at end of file
@@ -38,9 +38,26 @@ func (h *ProductionHandler) CreateProduction(w http.ResponseWriter, r *http.Requ
 		utils.WriteError(w, utils.NewBadRequestError("Date cannot be in the future"))
 		return
 	} 
+
+	for _, m := range req.Materials {
+		if m.MaterialID == "" || m.Quantity <= 0 {
+			utils.WriteError(w, utils.NewBadRequestError("Each material needs an id and a quantity above 0"))
+			return
+		}
+	}
+
+	if err := validateCuringDays(&req); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	if err := validateProductionAssignments(&req); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
 	
 	if err := h.Store.Production.Create(ctx, &req); err != nil {
-		utils.WriteError(w, utils.NewInternalServerError(err))
+		utils.WriteError(w, err)
 		return 
 	}
 
@@ -94,7 +111,7 @@ func (h *ProductionHandler) GetProductionMonthly(w http.ResponseWriter, r *http.
 		targetOffset += 12
 	}
 
-	p, totalCount, totalQuantity, err := h.Store.Production.GetAllMonthly(ctx, targetOffset)
+	p, summary, err := h.Store.Production.GetAllMonthly(ctx, targetOffset)
 	if err != nil {
 		utils.WriteError(w, utils.NewInternalServerError(err))
 		return
@@ -102,8 +119,11 @@ func (h *ProductionHandler) GetProductionMonthly(w http.ResponseWriter, r *http.
 
 	data := map[string]interface{}{
 		"productions": p,
-		"total_count": totalCount,
-		"total_quantity": totalQuantity,
+		"total_count": summary.TotalCount,
+		"total_quantity": summary.TotalQuantity,
+		"total_rejected": summary.TotalRejected,
+		"total_good": summary.TotalGood,
+		"products": summary.Products,
 		"month": monthNum,
 		"month_name": time.Month(monthNum).String(),
 	}
@@ -111,6 +131,86 @@ func (h *ProductionHandler) GetProductionMonthly(w http.ResponseWriter, r *http.
 	utils.WriteJSON(w, http.StatusOK, "Successfully get monthly productions", data)
 }
 
+// Reject rate per month for the last ?months= months (6 by default), split by reason
+func (h *ProductionHandler) GetProductionYield(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	months, err := strconv.Atoi(r.URL.Query().Get("months"))
+	if err != nil || months < 1 {
+		months = 6
+	}
+
+	if months > 12 {
+		utils.WriteError(w, utils.NewBadRequestError("Months cannot be more than 12"))
+		return
+	}
+
+	now := time.Now()
+	start, _ := utils.GetMonthRange(now, -(months - 1))
+	_, end := utils.GetMonthRange(now, 0)
+
+	yield, err := h.Store.Production.GetYield(ctx, start, end)
+	if err != nil {
+		utils.WriteError(w, utils.NewInternalServerError(err))
+		return
+	}
+
+	var produced, rejected int
+	for _, m := range yield {
+		produced += m.Produced
+		rejected += m.Rejected
+	}
+
+	var rate float64
+	if produced > 0 {
+		rate = float64(rejected) / float64(produced) * 100
+	}
+
+	data := map[string]interface{}{
+		"months": yield,
+		"total_produced": produced,
+		"total_rejected": rejected,
+		"reject_rate": rate,
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get production yield", data)
+}
+
+// Upcoming days with what finishes curing on each, ?days= ahead (14 by default),
+// optionally for a single ?product_id=
+func (h *ProductionHandler) GetCuringSchedule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	days, err := strconv.Atoi(r.URL.Query().Get("days"))
+	if err != nil || days < 1 {
+		days = 14
+	}
+
+	if days > 90 {
+		utils.WriteError(w, utils.NewBadRequestError("Days cannot be more than 90"))
+		return
+	}
+
+	schedule, cured, err := h.Store.Production.GetCuringSchedule(ctx, r.URL.Query().Get("product_id"), time.Now(), days)
+	if err != nil {
+		utils.WriteError(w, utils.NewInternalServerError(err))
+		return
+	}
+
+	var curing int
+	for _, d := range schedule {
+		curing += d.Quantity
+	}
+
+	data := map[string]interface{}{
+		"cured": cured,
+		"curing": curing,
+		"days": schedule,
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get curing schedule", data)
+}
+
 func (h *ProductionHandler) GetProduction(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -143,6 +243,23 @@ func (h *ProductionHandler) UpdateProduction(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	for _, m := range prod.Materials {
+		if m.MaterialID == "" || m.Quantity <= 0 {
+			utils.WriteError(w, utils.NewBadRequestError("Each material needs an id and a quantity above 0"))
+			return
+		}
+	}
+
+	if err := validateCuringDays(&prod); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	if err := validateProductionAssignments(&prod); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
 	prod.ID = idStr
 
 	err := h.Store.Production.Update(ctx, &prod)
@@ -154,6 +271,59 @@ func (h *ProductionHandler) UpdateProduction(w http.ResponseWriter, r *http.Requ
 	utils.WriteJSON(w, http.StatusOK, "Production updated successfully", prod)
 }
 
+func (h *ProductionHandler) AddReject(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req models.ProductionReject
+	if err := utils.ReadJSON(r, &req); err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("Invalid JSON format"))
+		return
+	}
+
+	switch req.Reason {
+	case models.RejectCracked, models.RejectUnderweight, models.RejectMalformed, models.RejectOther:
+	default:
+		utils.WriteError(w, utils.NewBadRequestError("Reason must be cracked, underweight, malformed or other"))
+		return
+	}
+
+	if req.Quantity <= 0 {
+		utils.WriteError(w, utils.NewBadRequestError("Quantity must be greater than 0"))
+		return
+	}
+
+	now := time.Now()
+	if req.RejectDate.IsZero() {
+		req.RejectDate = now
+	}
+
+	if req.RejectDate.After(now) {
+		utils.WriteError(w, utils.NewBadRequestError("Date cannot be in the future"))
+		return
+	}
+
+	req.ProductionID = chi.URLParam(r, "id")
+
+	if err := h.Store.Production.AddReject(ctx, &req); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, "Reject recorded successfully", req)
+}
+
+func (h *ProductionHandler) DeleteReject(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	err := h.Store.Production.DeleteReject(ctx, chi.URLParam(r, "id"), chi.URLParam(r, "rejectID"))
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Reject deleted successfully", nil)
+}
+
 func (h *ProductionHandler) DeleteProduction(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -165,4 +335,28 @@ func (h *ProductionHandler) DeleteProduction(w http.ResponseWriter, r *http.Requ
 	}
 
 	utils.WriteJSON(w, http.StatusOK, "Production deleted successfully", nil)
+}
+
+// Batches cure for their product's curing period unless they say otherwise
+func validateCuringDays(p *models.Production) error {
+	if p.CuringDays < 0 {
+		return utils.NewBadRequestError("Curing days cannot be negative")
+	}
+	return nil
+}
+
+// Crew and machines of a batch need ids; the store checks they exist
+func validateProductionAssignments(p *models.Production) error {
+	for _, member := range p.Crew {
+		if member.EmployeeID == "" {
+			return utils.NewBadRequestError("Each crew member needs an employee id")
+		}
+	}
+
+	for _, item := range p.Equipment {
+		if item.EquipmentID == "" {
+			return utils.NewBadRequestError("Each machine needs an equipment id")
+		}
+	}
+	return nil
 }
\ No newline at end of file
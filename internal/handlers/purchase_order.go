@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/kevinbrivio/batako-backend/internal/models"
+	"github.com/kevinbrivio/batako-backend/internal/store"
+	"github.com/kevinbrivio/batako-backend/internal/utils"
+)
+
+type PurchaseOrderHandler struct {
+	Store store.Storage
+}
+
+func NewPurchaseOrderHandler(s store.Storage) *PurchaseOrderHandler {
+	return &PurchaseOrderHandler{Store: s}
+}
+
+func (h *PurchaseOrderHandler) CreatePurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req models.PurchaseOrder
+	if err := utils.ReadJSON(r, &req); err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("Invalid JSON format"))
+		return
+	}
+
+	if err := validatePurchaseOrder(&req); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	if err := h.Store.PurchaseOrder.Create(ctx, &req); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, "Purchase order created successfully", req)
+}
+
+func (h *PurchaseOrderHandler) GetAllPurchaseOrders(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Get query params
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1 // default to 1
+	}
+
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+
+	status := r.URL.Query().Get("status")
+
+	// Calculate offset
+	offset := (page - 1) * limit
+
+	orders, totalCount, err := h.Store.PurchaseOrder.GetAll(ctx, status, limit, offset)
+	if err != nil {
+		utils.WriteError(w, utils.NewInternalServerError(err))
+		return
+	}
+
+	totalPages := (totalCount + limit - 1) / limit
+
+	response := utils.PaginatedResponse{
+		Items:      orders,
+		Total:      totalCount,
+		Page:       page,
+		PageSize:   limit,
+		TotalPages: totalPages,
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get all purchase orders", response)
+}
+
+func (h *PurchaseOrderHandler) GetSupplierSpendMonthly(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Get query params
+	monthNum, _ := strconv.Atoi(r.URL.Query().Get("month"))
+	currentMonth := int(time.Now().Month())
+	targetOffset := monthNum - currentMonth
+
+	if targetOffset < -6 {
+		targetOffset += 12
+	}
+
+	spend, totalSpend, err := h.Store.PurchaseOrder.GetSpendMonthly(ctx, targetOffset)
+	if err != nil {
+		utils.WriteError(w, utils.NewInternalServerError(err))
+		return
+	}
+
+	data := map[string]interface{}{
+		"suppliers":   spend,
+		"total_count": len(spend),
+		"total_spend": totalSpend,
+		"month":       monthNum,
+		"month_name":  time.Month(monthNum).String(),
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get monthly supplier spend", data)
+}
+
+func (h *PurchaseOrderHandler) GetPurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idStr := chi.URLParam(r, "id")
+
+	po, err := h.Store.PurchaseOrder.GetByID(ctx, idStr)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get purchase order", po)
+}
+
+func (h *PurchaseOrderHandler) UpdatePurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idStr := chi.URLParam(r, "id")
+
+	var po models.PurchaseOrder
+	if err := utils.ReadJSON(r, &po); err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("Invalid JSON Format"))
+		return
+	}
+
+	if err := validatePurchaseOrder(&po); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	po.ID = idStr
+
+	if err := h.Store.PurchaseOrder.Update(ctx, &po); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Purchase order updated successfully", po)
+}
+
+func (h *PurchaseOrderHandler) CancelPurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idStr := chi.URLParam(r, "id")
+
+	po, err := h.Store.PurchaseOrder.Cancel(ctx, idStr)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Purchase order cancelled successfully", po)
+}
+
+func (h *PurchaseOrderHandler) DeletePurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idStr := chi.URLParam(r, "id")
+	if err := h.Store.PurchaseOrder.Delete(ctx, idStr); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Purchase order deleted successfully", nil)
+}
+
+func (h *PurchaseOrderHandler) ReceiveGoods(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req models.GoodsReceipt
+	if err := utils.ReadJSON(r, &req); err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("Invalid JSON format"))
+		return
+	}
+
+	if len(req.Lines) == 0 {
+		utils.WriteError(w, utils.NewBadRequestError("At least one received line is required"))
+		return
+	}
+
+	for _, line := range req.Lines {
+		if line.ItemID == "" || line.Quantity <= 0 {
+			utils.WriteError(w, utils.NewBadRequestError("Each line needs an item id and a quantity above 0"))
+			return
+		}
+	}
+
+	if req.ReceivedDate.IsZero() {
+		req.ReceivedDate = time.Now()
+	}
+
+	if req.ReceivedDate.After(time.Now()) {
+		utils.WriteError(w, utils.NewBadRequestError("Date cannot be in the future"))
+		return
+	}
+
+	receipts, err := h.Store.PurchaseOrder.Receive(ctx, chi.URLParam(r, "id"), &req)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, "Goods received successfully", receipts)
+}
+
+func validatePurchaseOrder(po *models.PurchaseOrder) error {
+	if po.SupplierID == "" {
+		return utils.NewBadRequestError("Supplier cannot be empty")
+	}
+
+	if po.OrderDate.IsZero() {
+		return utils.NewBadRequestError("Order date cannot be empty")
+	}
+
+	if len(po.Items) == 0 {
+		return utils.NewBadRequestError("Purchase order needs at least one item")
+	}
+
+	for _, item := range po.Items {
+		if item.MaterialID == "" || item.Quantity <= 0 {
+			return utils.NewBadRequestError("Each item needs a material and a quantity above 0")
+		}
+
+		if item.UnitCost < 0 {
+			return utils.NewBadRequestError("Unit cost cannot be negative")
+		}
+	}
+
+	return nil
+}
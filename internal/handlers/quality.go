@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/kevinbrivio/batako-backend/internal/models"
+	"github.com/kevinbrivio/batako-backend/internal/pdf"
+	"github.com/kevinbrivio/batako-backend/internal/store"
+	"github.com/kevinbrivio/batako-backend/internal/utils"
+)
+
+type QualityHandler struct {
+	Store store.Storage
+}
+
+func NewQualityHandler(s store.Storage) *QualityHandler {
+	return &QualityHandler{Store: s}
+}
+
+func (h *QualityHandler) CreateTest(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req models.QualityTest
+	if err := utils.ReadJSON(r, &req); err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("Invalid JSON format"))
+		return
+	}
+
+	if req.LoadKN <= 0 {
+		utils.WriteError(w, utils.NewBadRequestError("Load must be greater than 0"))
+		return
+	}
+
+	if req.AreaMM2 < 0 {
+		utils.WriteError(w, utils.NewBadRequestError("Area cannot be negative"))
+		return
+	}
+
+	now := time.Now()
+	if req.TestDate.IsZero() {
+		req.TestDate = now
+	}
+
+	if req.TestDate.After(now) {
+		utils.WriteError(w, utils.NewBadRequestError("Date cannot be in the future"))
+		return
+	}
+
+	req.ProductionID = chi.URLParam(r, "id")
+
+	if err := h.Store.Quality.Create(ctx, &req); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, "Quality test recorded successfully", req)
+}
+
+func (h *QualityHandler) GetTests(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	batch, err := h.Store.Quality.GetByProduction(ctx, chi.URLParam(r, "id"))
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get quality tests", batch)
+}
+
+func (h *QualityHandler) DeleteTest(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if err := h.Store.Quality.Delete(ctx, chi.URLParam(r, "id"), chi.URLParam(r, "testID")); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Quality test deleted successfully", nil)
+}
+
+func (h *QualityHandler) GetCertificatePDF(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	batch, err := h.Store.Quality.GetByProduction(ctx, chi.URLParam(r, "id"))
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	if batch.TestCount == 0 {
+		utils.WriteError(w, utils.NewBadRequestError("Batch has no quality tests yet"))
+		return
+	}
+
+	doc := pdf.New()
+	addCertificatePage(doc, batch)
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=\"certificate-%s.pdf\"", batch.ProductionID))
+	w.WriteHeader(http.StatusOK)
+	doc.WriteTo(w)
+}
+
+func (h *QualityHandler) AttachCertificate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req models.CertificateRequest
+	if err := utils.ReadJSON(r, &req); err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("Invalid JSON format"))
+		return
+	}
+
+	if req.ProductionID == "" {
+		utils.WriteError(w, utils.NewBadRequestError("Production cannot be empty"))
+		return
+	}
+
+	idStr := chi.URLParam(r, "id")
+
+	if _, err := h.Store.Transaction.GetByID(ctx, idStr); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	if err := h.Store.Quality.Attach(ctx, idStr, req.ProductionID); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, "Certificate attached successfully", req)
+}
+
+func (h *QualityHandler) GetTransactionCertificates(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idStr := chi.URLParam(r, "id")
+
+	// 404 for unknown transactions instead of an empty list
+	if _, err := h.Store.Transaction.GetByID(ctx, idStr); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	batches, err := h.Store.Quality.GetByTransaction(ctx, idStr)
+	if err != nil {
+		utils.WriteError(w, utils.NewInternalServerError(err))
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get certificates", batches)
+}
+
+func (h *QualityHandler) DetachCertificate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	err := h.Store.Quality.Detach(ctx, chi.URLParam(r, "id"), chi.URLParam(r, "productionID"))
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Certificate detached successfully", nil)
+}
+
+func addCertificatePage(doc *pdf.Document, b *models.BatchQuality) {
+	page := doc.AddPage()
+
+	left, right := 50.0, pdf.PageWidth - 50
+
+	page.Text(left, 60, 18, true, companyName())
+	page.TextRight(right, 60, 18, true, "TEST CERTIFICATE")
+	page.Line(left, 75, right, 75)
+
+	page.Text(left, 100, 10, true, "Compressive strength")
+	page.Text(left, 116, 10, false, b.Product)
+	page.Text(left, 130, 10, false, "Produced " + b.ProductionDate.Format("02 Jan 2006") + ", " + strconv.Itoa(b.Quantity) + " pcs")
+
+	result := "PASS"
+	if !b.Passed {
+		result = "FAIL"
+	}
+
+	page.TextRight(right - 110, 100, 10, true, "Class")
+	page.TextRight(right, 100, 10, false, b.StrengthClass)
+	page.TextRight(right - 110, 116, 10, true, "Minimum")
+	page.TextRight(right, 116, 10, false, formatStrength(b.MinStrength))
+	page.TextRight(right - 110, 130, 10, true, "Result")
+	page.TextRight(right, 130, 10, true, result)
+
+	// Samples table
+	y := 170.0
+	page.Line(left, y, right, y)
+	page.Text(left + 5, y + 15, 10, true, "Sample")
+	page.Text(150, y + 15, 10, true, "Test date")
+	page.TextRight(270, y + 15, 10, true, "Age (days)")
+	page.TextRight(340, y + 15, 10, true, "Load (kN)")
+	page.TextRight(420, y + 15, 10, true, "Area (mm2)")
+	page.TextRight(490, y + 15, 10, true, "Strength")
+	page.TextRight(right - 5, y + 15, 10, true, "Result")
+	page.Line(left, y + 22, right, y + 22)
+
+	y += 22
+	for _, t := range b.Tests {
+		y += 18
+		passed := "Pass"
+		if !t.Passed {
+			passed = "Fail"
+		}
+		page.Text(left + 5, y, 10, false, t.SampleCode)
+		page.Text(150, y, 10, false, t.TestDate.Format("02 Jan 2006"))
+		page.TextRight(270, y, 10, false, strconv.Itoa(t.AgeDays))
+		page.TextRight(340, y, 10, false, strconv.FormatFloat(t.LoadKN, 'f', 1, 64))
+		page.TextRight(420, y, 10, false, strconv.FormatFloat(t.AreaMM2, 'f', 0, 64))
+		page.TextRight(490, y, 10, false, formatStrength(t.StrengthMPa))
+		page.TextRight(right - 5, y, 10, false, passed)
+	}
+	page.Line(left, y + 10, right, y + 10)
+
+	y += 30
+	page.TextRight(420, y, 10, true, "Average strength")
+	page.TextRight(490, y, 10, true, formatStrength(b.AverageStrength))
+
+	page.Text(left, pdf.PageHeight - 60, 8, false, "Production " + b.ProductionID)
+}
+
+func formatStrength(mpa float64) string {
+	return strconv.FormatFloat(mpa, 'f', 1, 64) + " MPa"
+}
\ No newline at end of file
@@ -0,0 +1,332 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/kevinbrivio/batako-backend/internal/models"
+	"github.com/kevinbrivio/batako-backend/internal/store"
+	"github.com/kevinbrivio/batako-backend/internal/utils"
+)
+
+type ReportHandler struct {
+	Store store.Storage
+}
+
+func NewReportHandler(s store.Storage) *ReportHandler {
+	return &ReportHandler{Store: s}
+}
+
+func (h *ReportHandler) GetReceivablesAging(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	asOf, err := parseDateParam(r, "as_of", time.Now())
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	aging, err := h.Store.Report.GetReceivablesAging(ctx, asOf)
+	if err != nil {
+		utils.WriteError(w, utils.NewInternalServerError(err))
+		return
+	}
+
+	if r.URL.Query().Get("format") == "csv" {
+		rows := make([][]string, 0, len(aging))
+		for _, a := range aging {
+			rows = append(rows, []string{
+				a.CustomerID,
+				a.Customer,
+				strconv.Itoa(a.TransactionCount),
+				formatAmount(a.Days0To30),
+				formatAmount(a.Days31To60),
+				formatAmount(a.Days61To90),
+				formatAmount(a.Over90),
+				formatAmount(a.Total),
+			})
+		}
+
+		header := []string{"customer_id", "customer", "transaction_count", "days_0_30", "days_31_60", "days_61_90", "days_over_90", "total"}
+		utils.WriteCSV(w, fmt.Sprintf("receivables-aging-%s.csv", asOf.Format("2006-01-02")), header, rows)
+		return
+	}
+
+	var total0To30, total31To60, total61To90, totalOver90, total float64
+	for _, a := range aging {
+		total0To30 += a.Days0To30
+		total31To60 += a.Days31To60
+		total61To90 += a.Days61To90
+		totalOver90 += a.Over90
+		total += a.Total
+	}
+
+	data := map[string]interface{}{
+		"customers":    aging,
+		"total_count":  len(aging),
+		"days_0_30":    total0To30,
+		"days_31_60":   total31To60,
+		"days_61_90":   total61To90,
+		"days_over_90": totalOver90,
+		"total":        total,
+		"as_of":        asOf.Format("2006-01-02"),
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get receivables aging", data)
+}
+
+func (h *ReportHandler) GetCustomerReceivables(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	asOf, err := parseDateParam(r, "as_of", time.Now())
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	customer, err := h.Store.Customer.GetByID(ctx, chi.URLParam(r, "customerID"))
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	items, err := h.Store.Report.GetCustomerReceivables(ctx, customer.ID, asOf)
+	if err != nil {
+		utils.WriteError(w, utils.NewInternalServerError(err))
+		return
+	}
+
+	if r.URL.Query().Get("format") == "csv" {
+		rows := make([][]string, 0, len(items))
+		for _, i := range items {
+			rows = append(rows, []string{
+				i.TransactionID,
+				i.PurchaseDate.Format("2006-01-02"),
+				strconv.Itoa(i.Quantity),
+				formatAmount(i.TotalPrice),
+				formatAmount(i.PaidAmount),
+				formatAmount(i.Balance),
+				strconv.Itoa(i.AgeDays),
+				i.Bucket,
+			})
+		}
+
+		header := []string{"transaction_id", "purchase_date", "quantity", "total_price", "paid_amount", "balance", "age_days", "bucket"}
+		utils.WriteCSV(w, fmt.Sprintf("receivables-%s-%s.csv", customer.ID, asOf.Format("2006-01-02")), header, rows)
+		return
+	}
+
+	var total float64
+	for _, i := range items {
+		total += i.Balance
+	}
+
+	data := map[string]interface{}{
+		"customer_id":  customer.ID,
+		"customer":     customer.Name,
+		"transactions": items,
+		"total_count":  len(items),
+		"total":        total,
+		"as_of":        asOf.Format("2006-01-02"),
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get customer receivables", data)
+}
+
+// Cement (and other recipe material) use against the product recipes,
+// per batch and per month. Defaults to the current month.
+func (h *ReportHandler) GetMaterialVariance(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	now := time.Now()
+	from, err := parseDateParam(r, "from", time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()))
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	to, err := parseDateParam(r, "to", now)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	if to.Before(from) {
+		utils.WriteError(w, utils.NewBadRequestError("to cannot be before from"))
+		return
+	}
+
+	tolerance := defaultVarianceTolerance()
+	if value := r.URL.Query().Get("tolerance"); value != "" {
+		tolerance, err = strconv.ParseFloat(value, 64)
+		if err != nil || tolerance < 0 {
+			utils.WriteError(w, utils.NewBadRequestError("Tolerance must be a percentage of 0 or more"))
+			return
+		}
+	}
+
+	material := r.URL.Query().Get("material")
+
+	batches, months, err := h.Store.Report.GetMaterialVariance(ctx, from, to, material, tolerance)
+	if err != nil {
+		utils.WriteError(w, utils.NewInternalServerError(err))
+		return
+	}
+
+	if r.URL.Query().Get("format") == "csv" {
+		rows := make([][]string, 0, len(batches))
+		for _, b := range batches {
+			rows = append(rows, []string{
+				b.ProductionID,
+				b.ProductionDate.Format("2006-01-02"),
+				b.Product,
+				strconv.Itoa(b.Quantity),
+				b.Material,
+				b.Unit,
+				formatAmount(b.Expected),
+				formatAmount(b.Actual),
+				formatAmount(b.Variance),
+				formatAmount(b.VariancePercent),
+				strconv.FormatBool(b.Flagged),
+			})
+		}
+
+		header := []string{"production_id", "production_date", "product", "quantity", "material", "unit", "expected", "actual", "variance", "variance_percent", "flagged"}
+		utils.WriteCSV(w, fmt.Sprintf("material-variance-%s-%s.csv", from.Format("2006-01-02"), to.Format("2006-01-02")), header, rows)
+		return
+	}
+
+	var flagged int
+	for _, b := range batches {
+		if b.Flagged {
+			flagged++
+		}
+	}
+
+	data := map[string]interface{}{
+		"productions":   batches,
+		"months":        months,
+		"flagged_count": flagged,
+		"tolerance":     tolerance,
+		"from":          from.Format("2006-01-02"),
+		"to":            to.Format("2006-01-02"),
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get material variance", data)
+}
+
+func (h *ReportHandler) GetProfitLoss(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	now := time.Now()
+	start, _ := utils.GetMonthRange(now, -5)
+	from, err := parseDateParam(r, "from", start)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	to, err := parseDateParam(r, "to", now)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	if to.Before(from) {
+		utils.WriteError(w, utils.NewBadRequestError("to cannot be before from"))
+		return
+	}
+
+	// Labour is computed month by month, so keep the range reasonable
+	if from.AddDate(2, 0, 0).Before(to) {
+		utils.WriteError(w, utils.NewBadRequestError("Range cannot be longer than 24 months"))
+		return
+	}
+
+	months, total, unpriced, err := h.Store.Report.GetProfitLoss(ctx, from, to)
+	if err != nil {
+		utils.WriteError(w, utils.NewInternalServerError(err))
+		return
+	}
+
+	if r.URL.Query().Get("format") == "csv" {
+		lines := []struct {
+			name  string
+			value func(m models.ProfitLossMonth) string
+		}{
+			{"revenue", func(m models.ProfitLossMonth) string { return formatAmount(m.Revenue) }},
+			{"blocks_sold", func(m models.ProfitLossMonth) string { return strconv.Itoa(m.BlocksSold) }},
+			{"cement_cost", func(m models.ProfitLossMonth) string { return formatAmount(m.CementCost) }},
+			{"material_cost", func(m models.ProfitLossMonth) string { return formatAmount(m.MaterialCost) }},
+			{"labour", func(m models.ProfitLossMonth) string { return formatAmount(m.Labour) }},
+			{"gross_profit", func(m models.ProfitLossMonth) string { return formatAmount(m.GrossProfit) }},
+			{"gross_margin", func(m models.ProfitLossMonth) string { return formatAmount(m.GrossMargin) }},
+			{"expenses", func(m models.ProfitLossMonth) string { return formatAmount(m.Expenses) }},
+			{"net_profit", func(m models.ProfitLossMonth) string { return formatAmount(m.NetProfit) }},
+			{"net_margin", func(m models.ProfitLossMonth) string { return formatAmount(m.NetMargin) }},
+			{"blocks_produced", func(m models.ProfitLossMonth) string { return strconv.Itoa(m.BlocksProduced) }},
+			{"unit_cost", func(m models.ProfitLossMonth) string { return formatAmount(m.UnitCost) }},
+			{"full_unit_cost", func(m models.ProfitLossMonth) string { return formatAmount(m.FullUnitCost) }},
+		}
+
+		// One column per month, like the statement the accountant keeps
+		header := []string{"line"}
+		for _, m := range months {
+			header = append(header, m.Month)
+		}
+		header = append(header, "total")
+
+		rows := make([][]string, 0, len(lines))
+		for _, l := range lines {
+			row := []string{l.name}
+			for _, m := range months {
+				row = append(row, l.value(m))
+			}
+			rows = append(rows, append(row, l.value(total)))
+		}
+
+		utils.WriteCSV(w, fmt.Sprintf("profit-loss-%s-%s.csv", from.Format("2006-01-02"), to.Format("2006-01-02")), header, rows)
+		return
+	}
+
+	data := map[string]interface{}{
+		"months":             months,
+		"total":              total,
+		"unpriced_materials": unpriced,
+		"from":               from.Format("2006-01-02"),
+		"to":                 to.Format("2006-01-02"),
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get profit and loss", data)
+}
+
+// Read an optional YYYY-MM-DD query param, falling back to def when absent
+func parseDateParam(r *http.Request, name string, def time.Time) (time.Time, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return def, nil
+	}
+
+	dt, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, utils.NewBadRequestError(fmt.Sprintf("Invalid %s, expected YYYY-MM-DD", name))
+	}
+	return dt, nil
+}
+
+// Percentage over the recipe a batch may use before it is flagged,
+// MATERIAL_VARIANCE_TOLERANCE or 10%
+func defaultVarianceTolerance() float64 {
+	if v, err := strconv.ParseFloat(os.Getenv("MATERIAL_VARIANCE_TOLERANCE"), 64); err == nil && v >= 0 {
+		return v
+	}
+	return 10
+}
+
+func formatAmount(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
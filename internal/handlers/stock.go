@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/kevinbrivio/batako-backend/internal/models"
+	"github.com/kevinbrivio/batako-backend/internal/store"
+	"github.com/kevinbrivio/batako-backend/internal/utils"
+)
+
+type StockHandler struct {
+	Store store.Storage
+}
+
+func NewStockHandler(s store.Storage) *StockHandler {
+	return &StockHandler{Store: s}
+}
+
+func (h *StockHandler) GetStock(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	levels, err := h.Store.Stock.GetLevels(ctx)
+	if err != nil {
+		utils.WriteError(w, utils.NewInternalServerError(err))
+		return
+	}
+
+	// Yard totals across products, with the per-product levels alongside
+	var total models.StockLevel
+	for _, l := range levels {
+		total.OnHand += l.OnHand
+		total.Cured += l.Cured
+		total.Curing += l.Curing
+		total.TotalIn += l.TotalIn
+		total.TotalOut += l.TotalOut
+		if l.LastMovementAt != nil && (total.LastMovementAt == nil || l.LastMovementAt.After(*total.LastMovementAt)) {
+			total.LastMovementAt = l.LastMovementAt
+		}
+	}
+
+	data := map[string]interface{}{
+		"on_hand":          total.OnHand,
+		"cured":            total.Cured,
+		"curing":           total.Curing,
+		"total_in":         total.TotalIn,
+		"total_out":        total.TotalOut,
+		"last_movement_at": total.LastMovementAt,
+		"products":         levels,
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get current stock", data)
+}
+
+func (h *StockHandler) GetStockMovements(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Get query params
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1 // default to 1
+	}
+
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit < 1 {
+		limit = 20
+	}
+
+	// Calculate offset
+	offset := (page - 1) * limit
+
+	movements, totalCount, err := h.Store.Stock.GetMovements(ctx, limit, offset)
+	if err != nil {
+		utils.WriteError(w, utils.NewInternalServerError(err))
+		return
+	}
+
+	totalPages := (totalCount + limit - 1) / limit
+
+	response := utils.PaginatedResponse{
+		Items:      movements,
+		Total:      totalCount,
+		Page:       page,
+		PageSize:   limit,
+		TotalPages: totalPages,
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get stock movements", response)
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/kevinbrivio/batako-backend/internal/models"
+	"github.com/kevinbrivio/batako-backend/internal/store"
+	"github.com/kevinbrivio/batako-backend/internal/utils"
+)
+
+type SupplierHandler struct {
+	Store store.Storage
+}
+
+func NewSupplierHandler(s store.Storage) *SupplierHandler {
+	return &SupplierHandler{Store: s}
+}
+
+func (h *SupplierHandler) CreateSupplier(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req models.Supplier
+	if err := utils.ReadJSON(r, &req); err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("Invalid JSON format"))
+		return
+	}
+
+	if utils.CleanName(req.Name) == "" {
+		utils.WriteError(w, utils.NewBadRequestError("Supplier name cannot be empty"))
+		return
+	}
+
+	if err := h.Store.Supplier.Create(ctx, &req); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, "Supplier created successfully", req)
+}
+
+func (h *SupplierHandler) GetAllSuppliers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Get query params
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1 // default to 1
+	}
+
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+
+	// Calculate offset
+	offset := (page - 1) * limit
+
+	suppliers, totalCount, err := h.Store.Supplier.GetAll(ctx, limit, offset)
+	if err != nil {
+		utils.WriteError(w, utils.NewInternalServerError(err))
+		return
+	}
+
+	totalPages := (totalCount + limit - 1) / limit
+
+	response := utils.PaginatedResponse{
+		Items:      suppliers,
+		Total:      totalCount,
+		Page:       page,
+		PageSize:   limit,
+		TotalPages: totalPages,
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get all suppliers", response)
+}
+
+func (h *SupplierHandler) GetSupplier(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idStr := chi.URLParam(r, "id")
+
+	sp, err := h.Store.Supplier.GetByID(ctx, idStr)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get supplier", sp)
+}
+
+func (h *SupplierHandler) UpdateSupplier(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idStr := chi.URLParam(r, "id")
+
+	var sp models.Supplier
+	if err := utils.ReadJSON(r, &sp); err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("Invalid JSON Format"))
+		return
+	}
+
+	if utils.CleanName(sp.Name) == "" {
+		utils.WriteError(w, utils.NewBadRequestError("Supplier name cannot be empty"))
+		return
+	}
+
+	sp.ID = idStr
+
+	if err := h.Store.Supplier.Update(ctx, &sp); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Supplier updated successfully", sp)
+}
+
+func (h *SupplierHandler) DeleteSupplier(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idStr := chi.URLParam(r, "id")
+	if err := h.Store.Supplier.Delete(ctx, idStr); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Supplier deleted successfully", nil)
+}
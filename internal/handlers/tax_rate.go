@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/kevinbrivio/batako-backend/internal/models"
+	"github.com/kevinbrivio/batako-backend/internal/store"
+	"github.com/kevinbrivio/batako-backend/internal/utils"
+)
+
+type TaxRateHandler struct {
+	Store store.Storage
+}
+
+func NewTaxRateHandler(s store.Storage) *TaxRateHandler {
+	return &TaxRateHandler{Store: s}
+}
+
+func validateTaxRate(r *models.TaxRate) error {
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" {
+		r.Name = "PPN"
+	}
+
+	if r.Rate < 0 || r.Rate >= 100 {
+		return utils.NewBadRequestError("Tax rate must be between 0 and 100")
+	}
+
+	if r.EffectiveFrom.IsZero() {
+		return utils.NewBadRequestError("Effective date cannot be empty")
+	}
+	return nil
+}
+
+func (h *TaxRateHandler) CreateTaxRate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req models.TaxRate
+	if err := utils.ReadJSON(r, &req); err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("Invalid JSON format"))
+		return
+	}
+
+	if err := validateTaxRate(&req); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	if err := h.Store.TaxRate.Create(ctx, &req); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, "Tax rate created successfully", req)
+}
+
+func (h *TaxRateHandler) GetAllTaxRates(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Get query params
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1 // default to 1
+	}
+
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+
+	// Calculate offset
+	offset := (page - 1) * limit
+
+	rates, totalCount, err := h.Store.TaxRate.GetAll(ctx, limit, offset)
+	if err != nil {
+		utils.WriteError(w, utils.NewInternalServerError(err))
+		return
+	}
+
+	totalPages := (totalCount + limit - 1) / limit
+
+	response := utils.PaginatedResponse{
+		Items:      rates,
+		Total:      totalCount,
+		Page:       page,
+		PageSize:   limit,
+		TotalPages: totalPages,
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get all tax rates", response)
+}
+
+func (h *TaxRateHandler) GetCurrentTaxRate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Defaults to today when no valid date is given
+	dt := time.Now()
+	if dateStr := r.URL.Query().Get("date"); dateStr != "" {
+		parsed, err := time.Parse("2006-01-02", dateStr)
+		if err != nil {
+			utils.WriteError(w, utils.NewBadRequestError("Invalid date format, expected YYYY-MM-DD"))
+			return
+		}
+		dt = parsed
+	}
+
+	rate, err := h.Store.TaxRate.GetEffective(ctx, dt)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get current tax rate", rate)
+}
+
+func (h *TaxRateHandler) GetTaxRate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idStr := chi.URLParam(r, "id")
+
+	rate, err := h.Store.TaxRate.GetByID(ctx, idStr)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Successfully get tax rate", rate)
+}
+
+func (h *TaxRateHandler) UpdateTaxRate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idStr := chi.URLParam(r, "id")
+
+	var rate models.TaxRate
+	if err := utils.ReadJSON(r, &rate); err != nil {
+		utils.WriteError(w, utils.NewBadRequestError("Invalid JSON Format"))
+		return
+	}
+
+	if err := validateTaxRate(&rate); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	rate.ID = idStr
+
+	if err := h.Store.TaxRate.Update(ctx, &rate); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Tax rate updated successfully", rate)
+}
+
+func (h *TaxRateHandler) DeleteTaxRate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idStr := chi.URLParam(r, "id")
+	if err := h.Store.TaxRate.Delete(ctx, idStr); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, "Tax rate deleted successfully", nil)
+}
@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -28,29 +30,13 @@ func (h *TransactionHandler) CreateTransaction(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	if req.Customer == "" {
-		utils.WriteError(w, utils.NewBadRequestError("Customer name cannot be empty"))
-		return 
-	}
-
-	if req.Address == "" {
-		utils.WriteError(w, utils.NewBadRequestError("Address cannot be empty"))
-		return 
-	}
-
-	if req.Quantity <= 0 {
-		utils.WriteError(w, utils.NewBadRequestError("Quantity is minimum 0."))
-		return
-	}
-
-	now := time.Now()
-	if req.PurchaseDate.After(now) {
-		utils.WriteError(w, utils.NewBadRequestError("Date cannot be in the future"))
+	if err := validateTransaction(&req); err != nil {
+		utils.WriteError(w, err)
 		return
 	}
 
 	if err := h.Store.Transaction.Create(ctx, &req); err != nil {
-		utils.WriteError(w, utils.NewInternalServerError(err))
+		utils.WriteError(w, err)
 		return
 	}
 
@@ -73,19 +59,29 @@ func (h *TransactionHandler) GetTransactionsDaily(w http.ResponseWriter, r *http
 		dt = time.Now()
 	}
 
-	t, totalCount, totalQuantity, totalRevenue, err := h.Store.Transaction.GetAllDaily(ctx, dt)
+	t, summary, err := h.Store.Transaction.GetAllDaily(ctx, dt)
+	if err != nil {
+		utils.WriteError(w, utils.NewInternalServerError(err))
+		return
+	}
+
+	// The day's cash drawer, nil if it was never opened
+	closing, err := h.Store.Cash.GetClosing(ctx, dt)
 	if err != nil {
 		utils.WriteError(w, utils.NewInternalServerError(err))
 		return
 	}
 
 	data := map[string]interface{}{
-        "total_count":  totalCount,
-		"total_revenue":  totalRevenue,
-		"total_quantity":  totalQuantity,
+        "total_count":  summary.TotalCount,
+		"total_revenue":  summary.TotalRevenue,
+		"total_quantity":  summary.TotalQuantity,
+		"total_paid":  summary.TotalPaid,
+		"total_outstanding":  summary.TotalOutstanding,
         "date":        dt.Format("2006-01-02"), // 1 for January, etc.
         "day":   		dt.Weekday().String(), // e.g., "Monday"
         "transactions": t,
+		"cash_closing": closing,
     }
 
 	utils.WriteJSON(w, http.StatusOK, "Sucessfully get daily Transactions", data)
@@ -137,7 +133,7 @@ func (h *TransactionHandler) GetTransactionsMonthly(w http.ResponseWriter, r *ht
 		targetOffset += 12  // Go to next year's January
 	}
 
-	t, totalCount, totalQuantity, totalRevenue, err := h.Store.Transaction.GetAllMonthly(ctx, targetOffset)
+	t, summary, err := h.Store.Transaction.GetAllMonthly(ctx, targetOffset)
 	
 	if err != nil {
 		utils.WriteError(w, utils.NewInternalServerError(err))
@@ -146,9 +142,13 @@ func (h *TransactionHandler) GetTransactionsMonthly(w http.ResponseWriter, r *ht
 
 	data := map[string]interface{}{
         "transactions": t,
-        "total_count":  totalCount,
-		"total_revenue":  totalRevenue,
-		"total_quantity":  totalQuantity,
+        "total_count":  summary.TotalCount,
+		"total_revenue":  summary.TotalRevenue,
+		"total_quantity":  summary.TotalQuantity,
+		"total_paid":  summary.TotalPaid,
+		"total_outstanding":  summary.TotalOutstanding,
+		"products":  summary.Products,
+		"tax":  summary.Tax,
         "month":        monthNum, // 1 for January, etc.
         "month_name":   time.Month(monthNum).String(), // e.g., "January"
     }
@@ -224,6 +224,11 @@ func (h *TransactionHandler) UpdateTransaction(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	if err := validateTransaction(&t); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
 	t.ID = idStr
 
 	err := h.Store.Transaction.Update(ctx, &t)
@@ -247,3 +252,53 @@ func (h *TransactionHandler) DeleteTransaction(w http.ResponseWriter, r *http.Re
 
 	utils.WriteJSON(w, http.StatusOK, "Transaction deleted successfully", nil)
 }
+
+// Checks shared by create and update; an update sends the whole sale again
+func validateTransaction(t *models.Transaction) error {
+	// Either pick an existing customer or give a name to match/create one
+	if t.CustomerID == "" && utils.CleanName(t.Customer) == "" {
+		return utils.NewBadRequestError("Customer name cannot be empty")
+	}
+
+	if t.CustomerID == "" && strings.TrimSpace(t.Address) == "" {
+		return utils.NewBadRequestError("Address cannot be empty")
+	}
+
+	if t.PurchaseDate.IsZero() {
+		return utils.NewBadRequestError("Purchase date cannot be empty")
+	}
+
+	if t.PurchaseDate.After(time.Now()) {
+		return utils.NewBadRequestError("Date cannot be in the future")
+	}
+
+	return validateTransactionItems(t)
+}
+
+func validateTransactionItems(t *models.Transaction) error {
+	// Older clients still send a single quantity of the default product
+	if len(t.Items) == 0 && t.Quantity > 0 {
+		t.Items = []models.TransactionItem{{Quantity: t.Quantity}}
+	}
+
+	if len(t.Items) == 0 {
+		return utils.NewBadRequestError("A sale needs at least one line")
+	}
+
+	for i, item := range t.Items {
+		if item.Quantity <= 0 {
+			return utils.NewBadRequestError(fmt.Sprintf("Quantity on line %d must be greater than 0", i + 1))
+		}
+
+		if item.Discount < 0 {
+			return utils.NewBadRequestError(fmt.Sprintf("Discount on line %d cannot be negative", i + 1))
+		}
+
+		// Lines without a product are extra charges, e.g. delivery
+		if item.ProductID == "" && strings.TrimSpace(item.Description) != "" && item.UnitPrice <= 0 {
+			return utils.NewBadRequestError(fmt.Sprintf("Unit price on line %d must be greater than 0", i + 1))
+		}
+	}
+
+	return nil
+}
\ No newline at end of file
@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+const (
+	ShiftDay = "day"
+	ShiftNight = "night"
+	ShiftHalf = "half"
+)
+
+// One employee's shift on a work day. Night shifts belong to the day they start.
+type Attendance struct {
+	ID string `json:"id"`
+	EmployeeID string `json:"employee_id"`
+	Employee string `json:"employee"`
+	WorkDate time.Time `json:"work_date"`
+	Shift string `json:"shift"`
+	CheckIn time.Time `json:"check_in"`
+	CheckOut *time.Time `json:"check_out"`
+	HoursWorked float64 `json:"hours_worked"`
+	OvertimeHours float64 `json:"overtime_hours"`
+	Note string `json:"note"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type AttendanceSummary struct {
+	Present int `json:"present"`
+	HalfShifts int `json:"half_shifts"`
+	NightShifts int `json:"night_shifts"`
+	CheckedIn int `json:"checked_in"`
+	HoursWorked float64 `json:"hours_worked"`
+	OvertimeHours float64 `json:"overtime_hours"`
+}
+
+// One employee's attendance over a month with the wages it earns
+type AttendanceMonth struct {
+	EmployeeID string `json:"employee_id"`
+	Employee string `json:"employee"`
+	Shifts int `json:"shifts"`
+	HalfShifts int `json:"half_shifts"`
+	Days float64 `json:"days"`
+	HoursWorked float64 `json:"hours_worked"`
+	OvertimeHours float64 `json:"overtime_hours"`
+	Wage float64 `json:"wage"`
+}
\ No newline at end of file
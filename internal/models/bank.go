@@ -0,0 +1,71 @@
+package models
+
+import "time"
+
+const (
+	BankLineUnmatched = "unmatched"
+	BankLineMatched = "matched"
+	BankLineIgnored = "ignored"
+)
+
+// How to read a bank's CSV export. Column numbers start at 1 and 0 means the
+// column isn't there. A statement has either a single amount column, signed or
+// suffixed with CR/DB, or separate debit and credit columns.
+type BankFormat struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+	Delimiter string `json:"delimiter"`
+	DateColumn int `json:"date_column"`
+	DateLayout string `json:"date_layout"`
+	DescriptionColumn int `json:"description_column"`
+	ReferenceColumn int `json:"reference_column"`
+	AmountColumn int `json:"amount_column"`
+	DebitColumn int `json:"debit_column"`
+	CreditColumn int `json:"credit_column"`
+}
+
+// One imported CSV file. Imported, Duplicates and Skipped describe the upload
+// that created it and are only filled in on that response.
+type BankStatement struct {
+	ID string `json:"id"`
+	BankFormat string `json:"bank_format"`
+	FileName string `json:"file_name"`
+	Note string `json:"note"`
+	LineCount int `json:"line_count"`
+	MatchedCount int `json:"matched_count"`
+	PeriodStart *time.Time `json:"period_start"`
+	PeriodEnd *time.Time `json:"period_end"`
+	Imported int `json:"imported,omitempty"`
+	Duplicates int `json:"duplicates,omitempty"`
+	Skipped int `json:"skipped,omitempty"`
+	Lines []BankStatementLine `json:"lines,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Amount is positive for money in and negative for money out. A matched line
+// points at the payment it was turned into.
+type BankStatementLine struct {
+	ID string `json:"id"`
+	StatementID string `json:"statement_id"`
+	LineNumber int `json:"line_number"`
+	TxnDate time.Time `json:"txn_date"`
+	Description string `json:"description"`
+	Reference string `json:"reference"`
+	Amount float64 `json:"amount"`
+	Status string `json:"status"`
+	TransactionID string `json:"transaction_id,omitempty"`
+	PaymentID string `json:"payment_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// An unpaid sale a bank credit could be paying for, best first
+type BankMatchSuggestion struct {
+	TransactionID string `json:"transaction_id"`
+	Customer string `json:"customer"`
+	PurchaseDate time.Time `json:"purchase_date"`
+	TotalPrice float64 `json:"total_price"`
+	Balance float64 `json:"balance"`
+	Score int `json:"score"`
+	Reasons []string `json:"reasons"`
+}
\ No newline at end of file
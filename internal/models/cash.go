@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+const (
+	CashSessionOpen = "open"
+	CashSessionClosed = "closed"
+
+	CashSourcePayment = "payment"
+	CashSourceExpense = "expense"
+)
+
+// Money into (positive) or out of (negative) the drawer on a day
+type CashMovement struct {
+	ID string `json:"id"`
+	MovementDate time.Time `json:"movement_date"`
+	SourceType string `json:"source_type"`
+	SourceID string `json:"source_id"`
+	Amount float64 `json:"amount"`
+	Description string `json:"description"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// One day at the cash drawer. Expected cash is the opening float plus what
+// came in less what went out; the cashier's count at closing is compared
+// against it and the difference kept as the discrepancy. Once a day is closed
+// its cash payments and expenses can't change until it is reopened.
+type CashSession struct {
+	ID string `json:"id"`
+	SessionDate time.Time `json:"session_date"`
+	Status string `json:"status"`
+	OpeningFloat float64 `json:"opening_float"`
+	CashIn float64 `json:"cash_in"`
+	CashOut float64 `json:"cash_out"`
+	ExpectedCash float64 `json:"expected_cash"`
+	CountedCash float64 `json:"counted_cash"`
+	Discrepancy float64 `json:"discrepancy"`
+	Note string `json:"note"`
+	ClosedAt *time.Time `json:"closed_at"`
+	Movements []CashMovement `json:"movements,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
\ No newline at end of file
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+type Customer struct {
+	ID string `json:"id"`
+	Name string `json:"name"`
+	TaxExempt bool `json:"tax_exempt"`
+	Addresses []CustomerAddress `json:"addresses"`
+	Phones []CustomerPhone `json:"phones"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type CustomerAddress struct {
+	ID string `json:"id"`
+	CustomerID string `json:"customer_id"`
+	Label string `json:"label"`
+	Address string `json:"address"`
+	IsDefault bool `json:"is_default"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type CustomerPhone struct {
+	ID string `json:"id"`
+	CustomerID string `json:"customer_id"`
+	Label string `json:"label"`
+	Phone string `json:"phone"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
\ No newline at end of file
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+const (
+	DeliveryScheduled = "scheduled"
+	DeliveryLoaded = "loaded"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed = "failed"
+)
+
+// One truck load of a sale. A sale can be split over several deliveries.
+type Delivery struct {
+	ID string `json:"id"`
+	DeliveryNumber string `json:"delivery_number"`
+	TransactionID string `json:"transaction_id"`
+	TransactionItemID string `json:"transaction_item_id"`
+	Customer string `json:"customer"`
+	Address string `json:"address"`
+	Product string `json:"product"`
+	ScheduledDate time.Time `json:"scheduled_date"`
+	Quantity int `json:"quantity"`
+	Vehicle string `json:"vehicle"`
+	Driver string `json:"driver"`
+	Status string `json:"status"`
+	Note string `json:"note"`
+	LoadedAt *time.Time `json:"loaded_at"`
+	DeliveredAt *time.Time `json:"delivered_at"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type DeliveryStatusUpdate struct {
+	Status string `json:"status"`
+	Note string `json:"note"`
+}
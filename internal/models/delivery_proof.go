@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+const (
+	ProofPhoto = "photo"
+	ProofSignature = "signature"
+)
+
+// A photo of the unloaded goods or the recipient's signature, kept in blob storage
+type DeliveryProof struct {
+	ID string `json:"id"`
+	TransactionID string `json:"transaction_id"`
+	DeliveryID string `json:"delivery_id,omitempty"`
+	Kind string `json:"kind"`
+	FileName string `json:"file_name"`
+	ContentType string `json:"content_type"`
+	Size int64 `json:"size"`
+	StorageKey string `json:"-"`
+	ThumbnailKey string `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
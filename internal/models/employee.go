@@ -0,0 +1,67 @@
+package models
+
+import "time"
+
+const (
+	PayrollDraft = "draft"
+	PayrollApproved = "approved"
+	PayrollPaid = "paid"
+)
+
+type Employee struct {
+	ID string `json:"id"`
+	Name string `json:"name"`
+	Phone string `json:"phone"`
+	Role string `json:"role"`
+	DailyWage float64 `json:"daily_wage"`
+	OvertimeRate float64 `json:"overtime_rate"`
+	IsActive bool `json:"is_active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Worker on the crew that molded a production batch
+type ProductionCrew struct {
+	EmployeeID string `json:"employee_id"`
+	EmployeeName string `json:"employee_name"`
+}
+
+// Pay per good block of a product, from EffectiveFrom until the next rate
+type PieceRate struct {
+	ID string `json:"id"`
+	ProductID string `json:"product_id"`
+	Rate float64 `json:"rate"`
+	EffectiveFrom time.Time `json:"effective_from"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Pay for one week or month. A draft is computed on the fly; once approved the
+// lines are stored and work in the period can no longer change.
+type PayrollRun struct {
+	ID string `json:"id,omitempty"`
+	Period string `json:"period"`
+	PeriodStart time.Time `json:"period_start"`
+	PeriodEnd time.Time `json:"period_end"`
+	Status string `json:"status"`
+	Total float64 `json:"total"`
+	UnassignedBatches int `json:"unassigned_batches"`
+	UnpricedBatches int `json:"unpriced_batches"`
+	OpenAttendance int `json:"open_attendance"`
+	Lines []PayrollLine `json:"lines"`
+	ApprovedAt *time.Time `json:"approved_at"`
+	PaidAt *time.Time `json:"paid_at"`
+}
+
+// Piece pay plus daily wages for one worker. A batch's good blocks are split
+// equally over its crew; a half shift counts as half a day.
+type PayrollLine struct {
+	EmployeeID string `json:"employee_id"`
+	Employee string `json:"employee"`
+	Batches int `json:"batches"`
+	Quantity float64 `json:"quantity"`
+	PieceAmount float64 `json:"piece_amount"`
+	Days float64 `json:"days"`
+	OvertimeHours float64 `json:"overtime_hours"`
+	WageAmount float64 `json:"wage_amount"`
+	Amount float64 `json:"amount"`
+}
\ No newline at end of file
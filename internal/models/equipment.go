@@ -0,0 +1,70 @@
+package models
+
+import "time"
+
+const (
+	EquipmentPress = "press"
+	EquipmentMold = "mold"
+	EquipmentMixer = "mixer"
+	EquipmentOther = "other"
+
+	MaintenanceOK = "ok"
+	MaintenanceDueSoon = "due_soon"
+	MaintenanceOverdue = "overdue"
+)
+
+// A press, mold or other machine. Its counters come from the productions it
+// was used for, on top of what it had done before it was registered.
+type Equipment struct {
+	ID string `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+	BlocksPerCycle int `json:"blocks_per_cycle"`
+	InitialBlocks int `json:"initial_blocks"`
+	Blocks int `json:"blocks"`
+	Cycles int `json:"cycles"`
+	CommissionedDate time.Time `json:"commissioned_date"`
+	IsActive bool `json:"is_active"`
+	Note string `json:"note"`
+	Schedules []MaintenanceSchedule `json:"schedules,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Equipment a production batch was made with
+type ProductionEquipment struct {
+	EquipmentID string `json:"equipment_id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// Recurring maintenance every IntervalBlocks blocks and/or IntervalDays days,
+// whichever comes first. Zero means the interval is not used.
+type MaintenanceSchedule struct {
+	ID string `json:"id"`
+	EquipmentID string `json:"equipment_id"`
+	Equipment string `json:"equipment"`
+	Task string `json:"task"`
+	IntervalBlocks int `json:"interval_blocks"`
+	IntervalDays int `json:"interval_days"`
+	LastDoneDate time.Time `json:"last_done_date"`
+	LastDoneBlocks int `json:"last_done_blocks"`
+	RemainingBlocks *int `json:"remaining_blocks"`
+	NextDueDate *time.Time `json:"next_due_date"`
+	EstimatedDueDate *time.Time `json:"estimated_due_date"`
+	Status string `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type MaintenanceLog struct {
+	ID string `json:"id"`
+	EquipmentID string `json:"equipment_id"`
+	ScheduleID string `json:"schedule_id"`
+	Task string `json:"task"`
+	PerformedDate time.Time `json:"performed_date"`
+	BlocksAt int `json:"blocks_at"`
+	Cost float64 `json:"cost"`
+	Note string `json:"note"`
+	CreatedAt time.Time `json:"created_at"`
+}
\ No newline at end of file
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// Expenses of a category are posted to its ledger account, or to Other
+// Expenses when it has none
+type ExpenseCategory struct {
+	ID string `json:"id"`
+	Name string `json:"name"`
+	AccountID string `json:"account_id"`
+	AccountName string `json:"account_name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Money spent on running the yard: diesel, electricity, repairs and so on.
+// The receipt, when there is one, is kept in blob storage. An unpaid
+// expense is owed to its supplier and sits in Accounts Payable.
+type Expense struct {
+	ID string `json:"id"`
+	CategoryID string `json:"category_id"`
+	Category string `json:"category"`
+	SupplierID string `json:"supplier_id,omitempty"`
+	Supplier string `json:"supplier,omitempty"`
+	ExpenseDate time.Time `json:"expense_date"`
+	Amount float64 `json:"amount"`
+	Description string `json:"description"`
+	Unpaid bool `json:"unpaid"`
+	HasReceipt bool `json:"has_receipt"`
+	ReceiptFileName string `json:"receipt_file_name,omitempty"`
+	ReceiptContentType string `json:"receipt_content_type,omitempty"`
+	ReceiptKey string `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ExpenseSummary struct {
+	TotalCount int `json:"total_count"`
+	TotalAmount float64 `json:"total_amount"`
+	Categories []ExpenseCategorySummary `json:"categories"`
+}
+
+// Per-category totals next to the daily, weekly and monthly lists
+type ExpenseCategorySummary struct {
+	CategoryID string `json:"category_id"`
+	Category string `json:"category"`
+	Count int `json:"count"`
+	Amount float64 `json:"amount"`
+}
\ No newline at end of file
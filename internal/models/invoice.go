@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+type Invoice struct {
+	ID string `json:"id"`
+	TransactionID string `json:"transaction_id"`
+	InvoiceNumber string `json:"invoice_number"`
+	IssueDate time.Time `json:"issue_date"`
+	CreatedAt time.Time `json:"created_at"`
+}
\ No newline at end of file
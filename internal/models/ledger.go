@@ -0,0 +1,128 @@
+package models
+
+import "time"
+
+const (
+	AccountAsset = "asset"
+	AccountLiability = "liability"
+	AccountEquity = "equity"
+	AccountRevenue = "revenue"
+	AccountExpense = "expense"
+
+	// Accounts the automatic postings rely on
+	AccountCodeCash = "1100"
+	AccountCodeBank = "1110"
+	AccountCodeReceivable = "1200"
+	AccountCodeInventory = "1300"
+	AccountCodePayable = "2100"
+	AccountCodeTaxPayable = "2200"
+	AccountCodeRetainedEarnings = "3200"
+	AccountCodeSales = "4100"
+	AccountCodeMaterialsUsed = "5100"
+	AccountCodeWages = "6300"
+	AccountCodeOtherExpenses = "6900"
+	AccountCodeCashOverShort = "6950"
+
+	JournalSourceTransaction = "transaction"
+	JournalSourcePayment = "payment"
+	JournalSourceReceipt = "receipt"
+	JournalSourceProduction = "production"
+	JournalSourceExpense = "expense"
+	JournalSourceCashSession = "cash_session"
+	JournalSourceManual = "manual"
+)
+
+// System accounts are used by the automatic postings and can't be removed
+type Account struct {
+	ID string `json:"id"`
+	Code string `json:"code"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+	IsSystem bool `json:"is_system"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// A balanced set of debits and credits. Entries are never edited: a change to
+// the sale, payment, receipt or expense behind one posts a reversal (with
+// ReversalOf pointing back at it) followed by a fresh entry.
+type JournalEntry struct {
+	ID string `json:"id"`
+	EntryDate time.Time `json:"entry_date"`
+	SourceType string `json:"source_type"`
+	SourceID string `json:"source_id,omitempty"`
+	Description string `json:"description"`
+	ReversalOf string `json:"reversal_of,omitempty"`
+	Lines []JournalLine `json:"lines"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type JournalLine struct {
+	ID string `json:"id"`
+	AccountID string `json:"account_id"`
+	AccountCode string `json:"account_code"`
+	AccountName string `json:"account_name"`
+	Debit float64 `json:"debit"`
+	Credit float64 `json:"credit"`
+}
+
+// Balance of one account shown on its normal side
+type TrialBalanceLine struct {
+	AccountID string `json:"account_id"`
+	Code string `json:"code"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+	Debit float64 `json:"debit"`
+	Credit float64 `json:"credit"`
+}
+
+type TrialBalance struct {
+	Date time.Time `json:"date"`
+	Accounts []TrialBalanceLine `json:"accounts"`
+	TotalDebit float64 `json:"total_debit"`
+	TotalCredit float64 `json:"total_credit"`
+	Balanced bool `json:"balanced"`
+}
+
+// Balance runs on the account's normal side, so it grows with debits for
+// assets and expenses and with credits for everything else
+type LedgerLine struct {
+	EntryID string `json:"entry_id"`
+	EntryDate time.Time `json:"entry_date"`
+	SourceType string `json:"source_type"`
+	SourceID string `json:"source_id,omitempty"`
+	Description string `json:"description"`
+	Debit float64 `json:"debit"`
+	Credit float64 `json:"credit"`
+	Balance float64 `json:"balance"`
+}
+
+type AccountLedger struct {
+	Account Account `json:"account"`
+	OpeningBalance float64 `json:"opening_balance"`
+	Lines []LedgerLine `json:"lines"`
+	TotalDebit float64 `json:"total_debit"`
+	TotalCredit float64 `json:"total_credit"`
+	ClosingBalance float64 `json:"closing_balance"`
+}
+
+type BalanceSheetLine struct {
+	AccountID string `json:"account_id"`
+	Code string `json:"code"`
+	Name string `json:"name"`
+	Balance float64 `json:"balance"`
+}
+
+// Revenue and expense accounts are not closed off, so their net to date shows
+// up under equity as current earnings
+type BalanceSheet struct {
+	Date time.Time `json:"date"`
+	Assets []BalanceSheetLine `json:"assets"`
+	Liabilities []BalanceSheetLine `json:"liabilities"`
+	Equity []BalanceSheetLine `json:"equity"`
+	CurrentEarnings float64 `json:"current_earnings"`
+	TotalAssets float64 `json:"total_assets"`
+	TotalLiabilities float64 `json:"total_liabilities"`
+	TotalEquity float64 `json:"total_equity"`
+	Balanced bool `json:"balanced"`
+}
\ No newline at end of file
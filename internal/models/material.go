@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+const (
+	MaterialCodeCement = "cement"
+
+	MaterialSourceReceipt = "receipt"
+	MaterialSourceProduction = "production"
+)
+
+type Material struct {
+	ID string `json:"id"`
+	Code string `json:"code"`
+	Name string `json:"name"`
+	Unit string `json:"unit"`
+	OnHand float64 `json:"on_hand"`
+	LowStockThreshold float64 `json:"low_stock_threshold"`
+	IsLowStock bool `json:"is_low_stock"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type MaterialReceipt struct {
+	ID string `json:"id"`
+	MaterialID string `json:"material_id"`
+	SupplierID string `json:"supplier_id,omitempty"`
+	PurchaseOrderItemID string `json:"purchase_order_item_id,omitempty"`
+	Quantity float64 `json:"quantity"`
+	UnitCost float64 `json:"unit_cost"`
+	ReceivedDate time.Time `json:"received_date"`
+	Note string `json:"note"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Quantity is signed: positive for receipts, negative for consumption
+type MaterialMovement struct {
+	ID string `json:"id"`
+	MaterialID string `json:"material_id"`
+	MovementDate time.Time `json:"movement_date"`
+	SourceType string `json:"source_type"`
+	SourceID string `json:"source_id"`
+	Quantity float64 `json:"quantity"`
+	Note string `json:"note"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Material consumed by a production besides the cement in CementUsed
+type ProductionMaterial struct {
+	MaterialID string `json:"material_id"`
+	MaterialName string `json:"material_name"`
+	Unit string `json:"unit"`
+	Quantity float64 `json:"quantity"`
+}
\ No newline at end of file
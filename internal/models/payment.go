@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+const (
+	PaymentMethodCash = "cash"
+	PaymentMethodTransfer = "transfer"
+	PaymentMethodOther = "other"
+)
+
+type Payment struct {
+	ID string `json:"id"`
+	TransactionID string `json:"transaction_id"`
+	Amount float64 `json:"amount"`
+	PaymentDate time.Time `json:"payment_date"`
+	Method string `json:"method"`
+	Reference string `json:"reference"`
+	Note string `json:"note"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
\ No newline at end of file
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+type PriceList struct {
+	ID string `json:"id"`
+	ProductID string `json:"product_id"`
+	UnitPrice float64 `json:"unit_price"`
+	EffectiveFrom time.Time `json:"effective_from"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
\ No newline at end of file
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+type Product struct {
+	ID string `json:"id"`
+	Name string `json:"name"`
+	LengthMM int `json:"length_mm"`
+	WidthMM int `json:"width_mm"`
+	HeightMM int `json:"height_mm"`
+	Unit string `json:"unit"`
+	DefaultPrice float64 `json:"default_price"`
+	CuringDays int `json:"curing_days"`
+	StrengthClass string `json:"strength_class"`
+	MinStrength float64 `json:"min_strength"`
+	IsDefault bool `json:"is_default"`
+	Recipe []ProductRecipeItem `json:"recipe,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Material that goes into a single unit of a product
+type ProductRecipeItem struct {
+	MaterialID string `json:"material_id"`
+	MaterialName string `json:"material_name"`
+	Unit string `json:"unit"`
+	QuantityPerUnit float64 `json:"quantity_per_unit"`
+}
+
+// Per-product totals next to the monthly lists
+type ProductSummary struct {
+	ProductID string `json:"product_id"`
+	ProductName string `json:"product_name"`
+	Count int `json:"count"`
+	Quantity int `json:"quantity"`
+	Revenue float64 `json:"revenue,omitempty"`
+}
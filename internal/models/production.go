@@ -2,11 +2,72 @@ package models
 
 import "time"
 
+const (
+	RejectCracked = "cracked"
+	RejectUnderweight = "underweight"
+	RejectMalformed = "malformed"
+	RejectOther = "other"
+)
+
 type Production struct {
 	ID string `json:"id"`
+	ProductID string `json:"product_id"`
+	Product string `json:"product"`
 	Quantity int `json:"quantity"`
+	Rejected int `json:"rejected"`
+	GoodQuantity int `json:"good_quantity"`
 	CementUsed float64 `json:"cement_used"`
 	ProductionDate time.Time `json:"production_date"`
+	CuringDays int `json:"curing_days"`
+	ReadyDate time.Time `json:"ready_date"`
+	IsCured bool `json:"is_cured"`
+	Materials []ProductionMaterial `json:"materials,omitempty"`
+	Rejects []ProductionReject `json:"rejects,omitempty"`
+	Crew []ProductionCrew `json:"crew,omitempty"`
+	Equipment []ProductionEquipment `json:"equipment,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ProductionSummary struct {
+	TotalCount int `json:"total_count"`
+	TotalQuantity int `json:"total_quantity"`
+	TotalRejected int `json:"total_rejected"`
+	TotalGood int `json:"total_good"`
+	Products []ProductSummary `json:"products"`
+}
+
+// Blocks of a batch thrown away after demolding or during curing
+type ProductionReject struct {
+	ID string `json:"id"`
+	ProductionID string `json:"production_id"`
+	Reason string `json:"reason"`
+	Quantity int `json:"quantity"`
+	RejectDate time.Time `json:"reject_date"`
+	Note string `json:"note"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Produced against rejected for the batches made in one month
+type YieldMonth struct {
+	Month string `json:"month"`
+	Produced int `json:"produced"`
+	Rejected int `json:"rejected"`
+	Good int `json:"good"`
+	RejectRate float64 `json:"reject_rate"`
+	Reasons []YieldReason `json:"reasons"`
+}
+
+type YieldReason struct {
+	Reason string `json:"reason"`
+	Quantity int `json:"quantity"`
+	RejectRate float64 `json:"reject_rate"`
+}
+
+// Blocks of one day's batches that finish curing on Date
+type CuringDay struct {
+	Date time.Time `json:"date"`
+	BatchCount int `json:"batch_count"`
+	Quantity int `json:"quantity"`
+	Available int `json:"available"`
 }
\ No newline at end of file
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// Compressive strength test of one sample from a production batch. The class
+// and minimum strength are copied from the product when the test is recorded.
+type QualityTest struct {
+	ID string `json:"id"`
+	ProductionID string `json:"production_id"`
+	SampleCode string `json:"sample_code"`
+	TestDate time.Time `json:"test_date"`
+	AgeDays int `json:"age_days"`
+	LoadKN float64 `json:"load_kn"`
+	AreaMM2 float64 `json:"area_mm2"`
+	StrengthMPa float64 `json:"strength_mpa"`
+	StrengthClass string `json:"strength_class"`
+	MinStrength float64 `json:"min_strength"`
+	Passed bool `json:"passed"`
+	TestedBy string `json:"tested_by"`
+	Note string `json:"note"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Test results of a batch, as printed on its certificate. A batch passes when
+// it has been tested and every sample passed.
+type BatchQuality struct {
+	ProductionID string `json:"production_id"`
+	ProductID string `json:"product_id"`
+	Product string `json:"product"`
+	ProductionDate time.Time `json:"production_date"`
+	Quantity int `json:"quantity"`
+	StrengthClass string `json:"strength_class"`
+	MinStrength float64 `json:"min_strength"`
+	TestCount int `json:"test_count"`
+	AverageStrength float64 `json:"average_strength"`
+	Passed bool `json:"passed"`
+	Tests []QualityTest `json:"tests"`
+}
+
+type CertificateRequest struct {
+	ProductionID string `json:"production_id"`
+}
\ No newline at end of file
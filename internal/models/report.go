@@ -0,0 +1,85 @@
+package models
+
+import "time"
+
+const (
+	AgingBucket0To30 = "0-30"
+	AgingBucket31To60 = "31-60"
+	AgingBucket61To90 = "61-90"
+	AgingBucketOver90 = "90+"
+)
+
+// Outstanding balance of one customer split by how long ago the sales were made
+type ReceivableAging struct {
+	CustomerID string `json:"customer_id"`
+	Customer string `json:"customer"`
+	TransactionCount int `json:"transaction_count"`
+	Days0To30 float64 `json:"days_0_30"`
+	Days31To60 float64 `json:"days_31_60"`
+	Days61To90 float64 `json:"days_61_90"`
+	Over90 float64 `json:"days_over_90"`
+	Total float64 `json:"total"`
+}
+
+type ReceivableItem struct {
+	TransactionID string `json:"transaction_id"`
+	PurchaseDate time.Time `json:"purchase_date"`
+	Quantity int `json:"quantity"`
+	TotalPrice float64 `json:"total_price"`
+	PaidAmount float64 `json:"paid_amount"`
+	Balance float64 `json:"balance"`
+	AgeDays int `json:"age_days"`
+	Bucket string `json:"bucket"`
+}
+// Actual against recipe consumption of one material in one production batch.
+// Variance is actual minus expected, so positive means more was used.
+type MaterialVariance struct {
+	ProductionID string `json:"production_id"`
+	ProductionDate time.Time `json:"production_date"`
+	ProductID string `json:"product_id"`
+	Product string `json:"product"`
+	Quantity int `json:"quantity"`
+	MaterialID string `json:"material_id"`
+	Material string `json:"material"`
+	Unit string `json:"unit"`
+	Expected float64 `json:"expected"`
+	Actual float64 `json:"actual"`
+	Variance float64 `json:"variance"`
+	VariancePercent float64 `json:"variance_percent"`
+	Flagged bool `json:"flagged"`
+}
+
+// MaterialVariance rolled up per month and material
+type MaterialVarianceMonth struct {
+	Month string `json:"month"`
+	MaterialID string `json:"material_id"`
+	Material string `json:"material"`
+	Unit string `json:"unit"`
+	ProductionCount int `json:"production_count"`
+	FlaggedCount int `json:"flagged_count"`
+	Expected float64 `json:"expected"`
+	Actual float64 `json:"actual"`
+	Variance float64 `json:"variance"`
+	VariancePercent float64 `json:"variance_percent"`
+}
+
+// Revenue against what it cost to make and sell the blocks over one month.
+// Gross profit takes off materials and labour, net profit also the operating
+// expenses. Unit costs are per good block produced.
+type ProfitLossMonth struct {
+	Month string `json:"month"`
+	Revenue float64 `json:"revenue"`
+	BlocksSold int `json:"blocks_sold"`
+	CementCost float64 `json:"cement_cost"`
+	MaterialCost float64 `json:"material_cost"`
+	Labour float64 `json:"labour"`
+	GrossProfit float64 `json:"gross_profit"`
+	GrossMargin float64 `json:"gross_margin"`
+	Expenses float64 `json:"expenses"`
+	ExpenseCategories []ExpenseCategorySummary `json:"expense_categories"`
+	NetProfit float64 `json:"net_profit"`
+	NetMargin float64 `json:"net_margin"`
+	BlocksProduced int `json:"blocks_produced"`
+	UnitCost float64 `json:"unit_cost"`
+	FullUnitCost float64 `json:"full_unit_cost"`
+}
\ No newline at end of file
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+const (
+	StockIn = "in"
+	StockOut = "out"
+
+	StockSourceProduction = "production"
+	StockSourceTransaction = "transaction"
+)
+
+// Quantity is signed: positive for stock coming in, negative for stock going out
+type StockMovement struct {
+	ID string `json:"id"`
+	ProductID string `json:"product_id"`
+	ProductName string `json:"product_name"`
+	MovementDate time.Time `json:"movement_date"`
+	MovementType string `json:"movement_type"`
+	SourceType string `json:"source_type"`
+	SourceID string `json:"source_id"`
+	Quantity int `json:"quantity"`
+	Note string `json:"note"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type StockLevel struct {
+	ProductID string `json:"product_id,omitempty"`
+	ProductName string `json:"product_name,omitempty"`
+	OnHand int `json:"on_hand"`
+	Cured int `json:"cured"`
+	Curing int `json:"curing"`
+	TotalIn int `json:"total_in"`
+	TotalOut int `json:"total_out"`
+	LastMovementAt *time.Time `json:"last_movement_at"`
+}
\ No newline at end of file
@@ -0,0 +1,61 @@
+package models
+
+import "time"
+
+const (
+	PurchaseOrderOpen = "open"
+	PurchaseOrderPartiallyReceived = "partially_received"
+	PurchaseOrderReceived = "received"
+	PurchaseOrderCancelled = "cancelled"
+)
+
+type Supplier struct {
+	ID string `json:"id"`
+	Name string `json:"name"`
+	Phone string `json:"phone"`
+	Address string `json:"address"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type PurchaseOrder struct {
+	ID string `json:"id"`
+	SupplierID string `json:"supplier_id"`
+	SupplierName string `json:"supplier_name"`
+	OrderDate time.Time `json:"order_date"`
+	Status string `json:"status"`
+	Note string `json:"note"`
+	TotalAmount float64 `json:"total_amount"`
+	Items []PurchaseOrderItem `json:"items,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type PurchaseOrderItem struct {
+	ID string `json:"id"`
+	MaterialID string `json:"material_id"`
+	MaterialName string `json:"material_name"`
+	Unit string `json:"unit"`
+	Quantity float64 `json:"quantity"`
+	UnitCost float64 `json:"unit_cost"`
+	ReceivedQuantity float64 `json:"received_quantity"`
+}
+
+// Goods received against a purchase order, possibly only part of it
+type GoodsReceipt struct {
+	ReceivedDate time.Time `json:"received_date"`
+	Note string `json:"note"`
+	Lines []GoodsReceiptLine `json:"lines"`
+}
+
+type GoodsReceiptLine struct {
+	ItemID string `json:"item_id"`
+	Quantity float64 `json:"quantity"`
+}
+
+type SupplierSpend struct {
+	SupplierID string `json:"supplier_id"`
+	SupplierName string `json:"supplier_name"`
+	ReceiptCount int `json:"receipt_count"`
+	TotalSpend float64 `json:"total_spend"`
+}
\ No newline at end of file
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// PPN rate that applies to sales from EffectiveFrom until the next rate starts.
+// When PricesIncludeTax is set the price list already contains the tax and it
+// is carved out of the sale total instead of added on top.
+type TaxRate struct {
+	ID string `json:"id"`
+	Name string `json:"name"`
+	Rate float64 `json:"rate"`
+	PricesIncludeTax bool `json:"prices_include_tax"`
+	EffectiveFrom time.Time `json:"effective_from"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Output tax for a period, for filing the PPN return
+type TaxSummary struct {
+	TaxableAmount float64 `json:"taxable_amount"`
+	TaxAmount float64 `json:"tax_amount"`
+	ExemptAmount float64 `json:"exempt_amount"`
+	UntaxedAmount float64 `json:"untaxed_amount"`
+	Rates []TaxRateSummary `json:"rates"`
+}
+
+type TaxRateSummary struct {
+	Rate float64 `json:"rate"`
+	Count int `json:"count"`
+	TaxableAmount float64 `json:"taxable_amount"`
+	TaxAmount float64 `json:"tax_amount"`
+}
\ No newline at end of file
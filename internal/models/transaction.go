@@ -2,13 +2,60 @@ package models
 
 import "time"
 
+const (
+	PaymentUnpaid = "unpaid"
+	PaymentPartial = "partial"
+	PaymentPaid = "paid"
+)
+
 type Transaction struct {
 	ID string `json:"id"`
+	Items []TransactionItem `json:"items"`
 	Quantity int `json:"quantity"`
+	Subtotal float64 `json:"subtotal"`
+	TaxRate float64 `json:"tax_rate"`
+	TaxAmount float64 `json:"tax_amount"`
 	TotalPrice float64 `json:"total_price"`
+	TaxInclusive bool `json:"tax_inclusive"`
+	TaxExempt bool `json:"tax_exempt"`
+	PaidAmount float64 `json:"paid_amount"`
+	Balance float64 `json:"balance"`
+	PaymentStatus string `json:"payment_status"`
 	PurchaseDate time.Time `json:"purchase_date"`
+	CustomerID string `json:"customer_id"`
 	Customer string `json:"customer"`
+	AddressID string `json:"address_id"`
 	Address string `json:"address"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	// Allow selling more than is currently in stock or cured. The sale goes
+	// through and the shortfall is reported in Warnings instead.
+	OverrideStock bool `json:"override_stock,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// One line of a sale. Product lines are priced from the price list and take
+// stock; lines without a product (delivery, unloading) are charged at the
+// given unit price.
+type TransactionItem struct {
+	ID string `json:"id"`
+	LineNumber int `json:"line_number"`
+	ProductID string `json:"product_id,omitempty"`
+	Product string `json:"product,omitempty"`
+	Description string `json:"description"`
+	Quantity int `json:"quantity"`
+	UnitPrice float64 `json:"unit_price"`
+	Discount float64 `json:"discount"`
+	LineTotal float64 `json:"line_total"`
+}
+
+type TransactionSummary struct {
+	TotalCount int `json:"total_count"`
+	TotalQuantity int `json:"total_quantity"`
+	TotalRevenue float64 `json:"total_revenue"`
+	TotalPaid float64 `json:"total_paid"`
+	TotalOutstanding float64 `json:"total_outstanding"`
+	Products []ProductSummary `json:"products,omitempty"`
+	Tax *TaxSummary `json:"tax,omitempty"`
 }
\ No newline at end of file
@@ -0,0 +1,328 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kevinbrivio/batako-backend/internal/models"
+	"github.com/kevinbrivio/batako-backend/internal/utils"
+)
+
+type AttendanceStore struct {
+	db *sql.DB
+}
+
+const attendanceColumns = `
+	a.id,
+	a.employee_id,
+	e.name,
+	a.work_date,
+	a.shift,
+	a.check_in,
+	a.check_out,
+	COALESCE(EXTRACT(EPOCH FROM a.check_out - a.check_in) / 3600, 0)::float8 as hours_worked,
+	a.overtime_hours,
+	a.note,
+	a.created_at,
+	a.updated_at
+`
+
+func (s *AttendanceStore) CheckIn(ctx context.Context, a *models.Attendance) error {
+	a.ID = uuid.New().String()
+	a.WorkDate, _ = utils.GetDayRange(a.CheckIn)
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		var active bool
+		err := tx.QueryRowContext(ctx, `
+			SELECT name, is_active FROM employees WHERE id = $1
+		`, a.EmployeeID).Scan(&a.Employee, &active)
+		if err == sql.ErrNoRows {
+			return utils.NewBadRequestError("Employee does not exist")
+		}
+		if err != nil {
+			return err
+		}
+
+		if !active {
+			return utils.NewBadRequestError(a.Employee + " is no longer active")
+		}
+
+		if err := ensurePayrollOpen(ctx, tx, a.WorkDate); err != nil {
+			return err
+		}
+
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO attendance (id, employee_id, work_date, shift, check_in, note)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING created_at, updated_at
+		`, a.ID, a.EmployeeID, a.WorkDate, a.Shift, a.CheckIn, a.Note).Scan(&a.CreatedAt, &a.UpdatedAt)
+		if isUniqueViolation(err) {
+			return utils.NewConflictError(a.Employee + " already checked in on " + a.WorkDate.Format("2006-01-02"))
+		}
+
+		return err
+	})
+}
+
+func (s *AttendanceStore) CheckOut(ctx context.Context, aID string, checkOut time.Time) (*models.Attendance, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	var a *models.Attendance
+	err := withTx(ctx, s.db, func(tx *sql.Tx) error {
+		var err error
+		a, err = getAttendance(ctx, tx, aID)
+		if err != nil {
+			return err
+		}
+
+		if a.CheckOut != nil {
+			return utils.NewConflictError(a.Employee + " already checked out")
+		}
+
+		a.CheckOut = &checkOut
+		return saveAttendance(ctx, tx, a)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// Correct the shift, times or note of a record
+func (s *AttendanceStore) Update(ctx context.Context, a *models.Attendance) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		current, err := getAttendance(ctx, tx, a.ID)
+		if err != nil {
+			return err
+		}
+
+		a.EmployeeID, a.Employee, a.CreatedAt = current.EmployeeID, current.Employee, current.CreatedAt
+		a.WorkDate = current.WorkDate
+		return saveAttendance(ctx, tx, a)
+	})
+}
+
+func (s *AttendanceStore) Delete(ctx context.Context, aID string) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		a, err := getAttendance(ctx, tx, aID)
+		if err != nil {
+			return err
+		}
+
+		if err := ensurePayrollOpen(ctx, tx, a.WorkDate); err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `DELETE FROM attendance WHERE id = $1`, aID)
+		return err
+	})
+}
+
+func (s *AttendanceStore) GetDaily(ctx context.Context, date time.Time) ([]models.Attendance, models.AttendanceSummary, error) {
+	start, end := utils.GetDayRange(date)
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+attendanceColumns+`
+		FROM attendance a
+		JOIN employees e ON e.id = a.employee_id
+		WHERE a.work_date BETWEEN $1 AND $2
+		ORDER BY a.check_in ASC
+	`, start, end)
+	if err != nil {
+		return nil, models.AttendanceSummary{}, err
+	}
+	defer rows.Close()
+
+	records := []models.Attendance{}
+	var summary models.AttendanceSummary
+
+	for rows.Next() {
+		a, err := scanAttendance(rows)
+		if err != nil {
+			return records, models.AttendanceSummary{}, err
+		}
+
+		summary.Present++
+		switch a.Shift {
+		case models.ShiftHalf:
+			summary.HalfShifts++
+		case models.ShiftNight:
+			summary.NightShifts++
+		}
+		if a.CheckOut == nil {
+			summary.CheckedIn++
+		}
+		summary.HoursWorked += a.HoursWorked
+		summary.OvertimeHours += a.OvertimeHours
+
+		records = append(records, *a)
+	}
+	if err = rows.Err(); err != nil {
+		return records, models.AttendanceSummary{}, err
+	}
+
+	return records, summary, nil
+}
+
+// Shifts, hours and wages per employee for the month monthOffset months from now
+func (s *AttendanceStore) GetMonthly(ctx context.Context, monthOffset int) ([]models.AttendanceMonth, error) {
+	start, end := utils.GetMonthRange(time.Now(), monthOffset)
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	return getAttendanceWages(ctx, s.db, start, end)
+}
+
+// Attendance per employee between start and end, shared with payroll
+func getAttendanceWages(ctx context.Context, q querier, start, end time.Time) ([]models.AttendanceMonth, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT
+			e.id,
+			e.name,
+			COUNT(*),
+			COUNT(*) FILTER (WHERE a.shift = 'half'),
+			COALESCE(SUM(EXTRACT(EPOCH FROM a.check_out - a.check_in) / 3600), 0)::float8,
+			SUM(a.overtime_hours),
+			e.daily_wage,
+			e.overtime_rate
+		FROM attendance a
+		JOIN employees e ON e.id = a.employee_id
+		WHERE a.work_date BETWEEN $1 AND $2
+		GROUP BY e.id, e.name, e.daily_wage, e.overtime_rate
+		ORDER BY e.name ASC
+	`, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	months := []models.AttendanceMonth{}
+	for rows.Next() {
+		var m models.AttendanceMonth
+		var dailyWage, overtimeRate float64
+		if err := rows.Scan(
+			&m.EmployeeID,
+			&m.Employee,
+			&m.Shifts,
+			&m.HalfShifts,
+			&m.HoursWorked,
+			&m.OvertimeHours,
+			&dailyWage,
+			&overtimeRate,
+		); err != nil {
+			return months, err
+		}
+
+		m.Days = float64(m.Shifts) - float64(m.HalfShifts) / 2
+		m.Wage = m.Days * dailyWage + m.OvertimeHours * overtimeRate
+		months = append(months, m)
+	}
+
+	return months, rows.Err()
+}
+
+// Write a record back with its overtime worked out again. Both the day it was
+// on and the day it moves to must be outside an approved payroll.
+func saveAttendance(ctx context.Context, q querier, a *models.Attendance) error {
+	if err := ensurePayrollOpen(ctx, q, a.WorkDate); err != nil {
+		return err
+	}
+	a.WorkDate, _ = utils.GetDayRange(a.CheckIn)
+
+	if err := ensurePayrollOpen(ctx, q, a.WorkDate); err != nil {
+		return err
+	}
+
+	if a.CheckOut != nil && !a.CheckOut.After(a.CheckIn) {
+		return utils.NewBadRequestError("Check-out must be after check-in")
+	}
+
+	a.HoursWorked, a.OvertimeHours = 0, 0
+	if a.CheckOut != nil {
+		a.HoursWorked = a.CheckOut.Sub(a.CheckIn).Hours()
+		a.OvertimeHours = overtimeHours(a.Shift, a.HoursWorked)
+	}
+
+	err := q.QueryRowContext(ctx, `
+		UPDATE attendance
+		SET work_date = $2, shift = $3, check_in = $4, check_out = $5, overtime_hours = $6, note = $7, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at
+	`, a.ID, a.WorkDate, a.Shift, a.CheckIn, a.CheckOut, a.OvertimeHours, a.Note).Scan(&a.UpdatedAt)
+	if isUniqueViolation(err) {
+		return utils.NewConflictError(a.Employee + " already has attendance on " + a.WorkDate.Format("2006-01-02"))
+	}
+
+	return err
+}
+
+// Hours past the normal length of a shift, counted in whole half hours
+func overtimeHours(shift string, worked float64) float64 {
+	normal := 8.0
+	if shift == models.ShiftHalf {
+		normal = 4
+	}
+
+	return math.Max(0, math.Floor((worked - normal) * 2) / 2)
+}
+
+func getAttendance(ctx context.Context, q querier, aID string) (*models.Attendance, error) {
+	row := q.QueryRowContext(ctx, `
+		SELECT `+attendanceColumns+`
+		FROM attendance a
+		JOIN employees e ON e.id = a.employee_id
+		WHERE a.id = $1
+		FOR UPDATE OF a
+	`, aID)
+
+	a, err := scanAttendance(row)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("Attendance")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+func scanAttendance(row rowScanner) (*models.Attendance, error) {
+	var a models.Attendance
+	err := row.Scan(
+		&a.ID,
+		&a.EmployeeID,
+		&a.Employee,
+		&a.WorkDate,
+		&a.Shift,
+		&a.CheckIn,
+		&a.CheckOut,
+		&a.HoursWorked,
+		&a.OvertimeHours,
+		&a.Note,
+		&a.CreatedAt,
+		&a.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &a, nil
+}
\ No newline at end of file
@@ -0,0 +1,33 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/kevinbrivio/batako-backend/internal/models"
+)
+
+func TestOvertimeHours(t *testing.T) {
+	tests := []struct {
+		name   string
+		shift  string
+		worked float64
+		want   float64
+	}{
+		{"short day", models.ShiftDay, 6, 0},
+		{"exact day", models.ShiftDay, 8, 0},
+		{"under half hour", models.ShiftDay, 8.4, 0},
+		{"half hour", models.ShiftDay, 8.5, 0.5},
+		{"rounds down to half hour", models.ShiftDay, 9.9, 1.5},
+		{"night uses day length", models.ShiftNight, 10, 2},
+		{"half shift", models.ShiftHalf, 4, 0},
+		{"half shift overtime", models.ShiftHalf, 5.25, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := overtimeHours(tt.shift, tt.worked); got != tt.want {
+				t.Errorf("overtimeHours(%q, %v) = %v, want %v", tt.shift, tt.worked, got, tt.want)
+			}
+		})
+	}
+}
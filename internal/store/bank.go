@@ -0,0 +1,582 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kevinbrivio/batako-backend/internal/bankstatement"
+	"github.com/kevinbrivio/batako-backend/internal/models"
+	"github.com/kevinbrivio/batako-backend/internal/utils"
+)
+
+type BankStore struct {
+	db *sql.DB
+}
+
+const bankStatementColumns = `
+	s.id,
+	s.bank_format,
+	s.file_name,
+	s.note,
+	COALESCE(l.line_count, 0),
+	COALESCE(l.matched_count, 0),
+	l.period_start,
+	l.period_end,
+	s.created_at
+`
+
+const bankStatementJoins = `
+	LEFT JOIN (
+		SELECT
+			statement_id,
+			COUNT(*) as line_count,
+			COUNT(*) FILTER (WHERE status = 'matched') as matched_count,
+			MIN(txn_date) as period_start,
+			MAX(txn_date) as period_end
+		FROM bank_statement_lines
+		GROUP BY statement_id
+	) l ON l.statement_id = s.id
+`
+
+const bankLineColumns = `
+	id,
+	statement_id,
+	line_number,
+	txn_date,
+	description,
+	reference,
+	amount,
+	status,
+	COALESCE(transaction_id, ''),
+	COALESCE(payment_id, ''),
+	created_at,
+	updated_at
+`
+
+// Store the lines of an uploaded statement, skipping any already imported
+// from an earlier, overlapping export
+func (s *BankStore) Import(ctx context.Context, st *models.BankStatement, lines []models.BankStatementLine) error {
+	st.ID = uuid.New().String()
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 30)
+	defer cancel()
+
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO bank_statements (id, bank_format, file_name, note)
+			VALUES ($1, $2, $3, $4)
+		`, st.ID, st.BankFormat, st.FileName, st.Note)
+		if err != nil {
+			return err
+		}
+
+		occurrences := map[string]int{}
+		for i := range lines {
+			l := &lines[i]
+			key := bankstatement.Fingerprint(*l, 0)
+			fingerprint := bankstatement.Fingerprint(*l, occurrences[key])
+			occurrences[key]++
+
+			l.ID = uuid.New().String()
+			l.StatementID = st.ID
+			l.Status = models.BankLineUnmatched
+
+			res, err := tx.ExecContext(ctx, `
+				INSERT INTO bank_statement_lines (id, statement_id, line_number, txn_date, description, reference, amount, fingerprint)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+				ON CONFLICT (fingerprint) DO NOTHING
+			`, l.ID, l.StatementID, l.LineNumber, l.TxnDate, l.Description, l.Reference, l.Amount, fingerprint)
+			if err != nil {
+				return err
+			}
+
+			if rows, err := res.RowsAffected(); err != nil {
+				return err
+			} else if rows == 0 {
+				st.Duplicates++
+				continue
+			}
+			st.Imported++
+		}
+
+		if st.Imported == 0 {
+			return utils.NewConflictError("Every line in this statement has already been imported")
+		}
+
+		imported, duplicates, skipped := st.Imported, st.Duplicates, st.Skipped
+		saved, err := getBankStatement(ctx, tx, st.ID)
+		if err != nil {
+			return err
+		}
+
+		*st = *saved
+		st.Imported, st.Duplicates, st.Skipped = imported, duplicates, skipped
+		return nil
+	})
+}
+
+func (s *BankStore) GetStatements(ctx context.Context, limit, offset int) ([]models.BankStatement, int, error) {
+	query := `
+		SELECT ` + bankStatementColumns + `, COUNT(*) OVER() as total_count
+		FROM bank_statements s
+		` + bankStatementJoins + `
+		ORDER BY s.created_at DESC
+		LIMIT $1 OFFSET $2
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	statements := []models.BankStatement{}
+	var totalCount int
+
+	for rows.Next() {
+		var st models.BankStatement
+		if err := rows.Scan(append(bankStatementFields(&st), &totalCount)...); err != nil {
+			return statements, 0, err
+		}
+		statements = append(statements, st)
+	}
+	if err = rows.Err(); err != nil {
+		return statements, 0, err
+	}
+
+	return statements, totalCount, nil
+}
+
+func (s *BankStore) GetStatement(ctx context.Context, stID string) (*models.BankStatement, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	st, err := getBankStatement(ctx, s.db, stID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT ` + bankLineColumns + `
+		FROM bank_statement_lines
+		WHERE statement_id = $1
+		ORDER BY line_number ASC
+	`, stID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	st.Lines = []models.BankStatementLine{}
+	for rows.Next() {
+		var l models.BankStatementLine
+		if err := rows.Scan(bankLineFields(&l)...); err != nil {
+			return nil, err
+		}
+		st.Lines = append(st.Lines, l)
+	}
+
+	return st, rows.Err()
+}
+
+// Only statements none of whose lines have become payments can go
+func (s *BankStore) DeleteStatement(ctx context.Context, stID string) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		st, err := getBankStatement(ctx, tx, stID)
+		if err != nil {
+			return err
+		}
+
+		if st.MatchedCount > 0 {
+			return utils.NewConflictError("Statement has matched lines; unmatch them before deleting it")
+		}
+
+		_, err = tx.ExecContext(ctx, `DELETE FROM bank_statements WHERE id = $1`, stID)
+		return err
+	})
+}
+
+// Lines across all statements, oldest first, optionally of one status
+func (s *BankStore) GetLines(ctx context.Context, status string, limit, offset int) ([]models.BankStatementLine, int, error) {
+	query := `
+		SELECT ` + bankLineColumns + `, COUNT(*) OVER() as total_count
+		FROM bank_statement_lines
+		WHERE $1 = '' OR status = $1
+		ORDER BY txn_date ASC, line_number ASC
+		LIMIT $2 OFFSET $3
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, status, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	lines := []models.BankStatementLine{}
+	var totalCount int
+
+	for rows.Next() {
+		var l models.BankStatementLine
+		if err := rows.Scan(append(bankLineFields(&l), &totalCount)...); err != nil {
+			return lines, 0, err
+		}
+		lines = append(lines, l)
+	}
+	if err = rows.Err(); err != nil {
+		return lines, 0, err
+	}
+
+	return lines, totalCount, nil
+}
+
+// Unpaid sales the money on a line could be for, scored on how well the
+// amount, the date and the customer's name fit. Sales rejected for this line
+// before are left out.
+func (s *BankStore) GetSuggestions(ctx context.Context, lineID string) ([]models.BankMatchSuggestion, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	l, err := getBankLine(ctx, s.db, lineID)
+	if err != nil {
+		return nil, err
+	}
+
+	if l.Status != models.BankLineUnmatched || l.Amount <= 0 {
+		return []models.BankMatchSuggestion{}, nil
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT t.id, t.customer, t.purchase_date, t.total_price, t.total_price - COALESCE(p.paid_amount, 0)
+		FROM transactions t
+		LEFT JOIN (
+			SELECT transaction_id, SUM(amount) as paid_amount
+			FROM payments
+			GROUP BY transaction_id
+		) p ON p.transaction_id = t.id
+		WHERE t.total_price - COALESCE(p.paid_amount, 0) >= $2 - 0.005
+			AND t.purchase_date BETWEEN $3::date - $4::int AND $3::date
+			AND NOT EXISTS (
+				SELECT 1 FROM bank_match_rejections r
+				WHERE r.line_id = $1 AND r.transaction_id = t.id
+			)
+	`, l.ID, l.Amount, l.TxnDate, bankMatchWindowDays)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	description := strings.ToLower(l.Description)
+	suggestions := []models.BankMatchSuggestion{}
+
+	for rows.Next() {
+		var sg models.BankMatchSuggestion
+		if err := rows.Scan(&sg.TransactionID, &sg.Customer, &sg.PurchaseDate, &sg.TotalPrice, &sg.Balance); err != nil {
+			return nil, err
+		}
+
+		scoreBankMatch(&sg, l, description)
+		if sg.Score >= bankMatchMinScore {
+			suggestions = append(suggestions, sg)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Score != suggestions[j].Score {
+			return suggestions[i].Score > suggestions[j].Score
+		}
+		return suggestions[i].PurchaseDate.Before(suggestions[j].PurchaseDate)
+	})
+
+	if len(suggestions) > 5 {
+		suggestions = suggestions[:5]
+	}
+	return suggestions, nil
+}
+
+// Turn the line into a bank transfer payment on the transaction
+func (s *BankStore) ConfirmMatch(ctx context.Context, lineID, tID string) (*models.BankStatementLine, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	var l *models.BankStatementLine
+	err := withTx(ctx, s.db, func(tx *sql.Tx) error {
+		var err error
+		l, err = lockBankLine(ctx, tx, lineID)
+		if err != nil {
+			return err
+		}
+
+		if l.Status != models.BankLineUnmatched {
+			return utils.NewConflictError("Line is already " + l.Status)
+		}
+		if l.Amount <= 0 {
+			return utils.NewBadRequestError("Only money coming in can be matched to a sale")
+		}
+
+		note := "Bank statement: " + l.Description
+		if r := []rune(note); len(r) > 255 {
+			note = string(r[:255])
+		}
+
+		p := &models.Payment{
+			TransactionID: tID,
+			Amount: l.Amount,
+			PaymentDate: l.TxnDate,
+			Method: models.PaymentMethodTransfer,
+			Reference: l.Reference,
+			Note: note,
+		}
+		if err := insertPayment(ctx, tx, p); err != nil {
+			return err
+		}
+
+		return tx.QueryRowContext(ctx, `
+			UPDATE bank_statement_lines
+			SET status = $2, transaction_id = $3, payment_id = $4, updated_at = NOW()
+			WHERE id = $1
+			RETURNING status, transaction_id, payment_id, updated_at
+		`, l.ID, models.BankLineMatched, tID, p.ID).Scan(&l.Status, &l.TransactionID, &l.PaymentID, &l.UpdatedAt)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// Stop suggesting a sale for this line
+func (s *BankStore) RejectMatch(ctx context.Context, lineID, tID string) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	if _, err := getBankLine(ctx, s.db, lineID); err != nil {
+		return err
+	}
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO bank_match_rejections (line_id, transaction_id)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`, lineID, tID)
+	if isForeignKeyViolation(err) {
+		return utils.NewNotFoundError("Transaction")
+	}
+	return err
+}
+
+// Mark a line as not a customer payment, e.g. bank charges or a transfer
+// between our own accounts
+func (s *BankStore) Ignore(ctx context.Context, lineID string) (*models.BankStatementLine, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	var l *models.BankStatementLine
+	err := withTx(ctx, s.db, func(tx *sql.Tx) error {
+		var err error
+		l, err = lockBankLine(ctx, tx, lineID)
+		if err != nil {
+			return err
+		}
+
+		if l.Status != models.BankLineUnmatched {
+			return utils.NewConflictError("Line is already " + l.Status)
+		}
+
+		return setBankLineStatus(ctx, tx, l, models.BankLineIgnored)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// Put a line back to unmatched, deleting the payment a match created
+func (s *BankStore) Unmatch(ctx context.Context, lineID string) (*models.BankStatementLine, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	var l *models.BankStatementLine
+	err := withTx(ctx, s.db, func(tx *sql.Tx) error {
+		var err error
+		l, err = lockBankLine(ctx, tx, lineID)
+		if err != nil {
+			return err
+		}
+
+		switch l.Status {
+		case models.BankLineMatched:
+			if err := deletePayment(ctx, tx, l.TransactionID, l.PaymentID); err != nil {
+				return err
+			}
+			l.TransactionID, l.PaymentID = "", ""
+		case models.BankLineUnmatched:
+			return utils.NewConflictError("Line is not matched")
+		}
+
+		return setBankLineStatus(ctx, tx, l, models.BankLineUnmatched)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// How far back a sale can be for a transfer to be suggested as its payment,
+// and the score a suggestion needs to be shown
+const (
+	bankMatchWindowDays = 180
+	bankMatchMinScore = 40
+)
+
+// Amount counts for most: the exact outstanding balance scores 50, a part of
+// it 15. A sale within a week of the transfer adds 25, within a month 15 and
+// within three months 5. Up to 25 more for the customer's name appearing in
+// the transfer description.
+func scoreBankMatch(sg *models.BankMatchSuggestion, l *models.BankStatementLine, description string) {
+	sg.Reasons = []string{}
+
+	if math.Abs(sg.Balance - l.Amount) < 0.005 {
+		sg.Score += 50
+		sg.Reasons = append(sg.Reasons, "Amount equals the outstanding balance")
+	} else {
+		sg.Score += 15
+		sg.Reasons = append(sg.Reasons, fmt.Sprintf("Partial payment of the %.2f outstanding", sg.Balance))
+	}
+
+	days := int(l.TxnDate.Sub(sg.PurchaseDate).Hours() / 24)
+	switch {
+	case days <= 7:
+		sg.Score += 25
+	case days <= 30:
+		sg.Score += 15
+	case days <= 90:
+		sg.Score += 5
+	}
+	if days <= 90 {
+		sg.Reasons = append(sg.Reasons, fmt.Sprintf("Sold %d days before the transfer", days))
+	}
+
+	var words, found int
+	for _, w := range strings.Fields(strings.ToLower(sg.Customer)) {
+		// Skip honorifics and initials, they match far too much
+		if len(w) < 3 || w == "pak" || w == "bu" || w == "ibu" || w == "bpk" {
+			continue
+		}
+		words++
+		if strings.Contains(description, w) {
+			found++
+		}
+	}
+	if found > 0 {
+		sg.Score += 25 * found / words
+		sg.Reasons = append(sg.Reasons, "Customer name appears in the description")
+	}
+}
+
+func getBankStatement(ctx context.Context, q querier, stID string) (*models.BankStatement, error) {
+	var st models.BankStatement
+
+	err := q.QueryRowContext(ctx, `
+		SELECT ` + bankStatementColumns + `
+		FROM bank_statements s
+		` + bankStatementJoins + `
+		WHERE s.id = $1
+	`, stID).Scan(bankStatementFields(&st)...)
+
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("Bank statement")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+// Scan targets in the order of bankStatementColumns
+func bankStatementFields(st *models.BankStatement) []any {
+	return []any{
+		&st.ID,
+		&st.BankFormat,
+		&st.FileName,
+		&st.Note,
+		&st.LineCount,
+		&st.MatchedCount,
+		&st.PeriodStart,
+		&st.PeriodEnd,
+		&st.CreatedAt,
+	}
+}
+
+func getBankLine(ctx context.Context, q querier, lineID string) (*models.BankStatementLine, error) {
+	var l models.BankStatementLine
+
+	err := q.QueryRowContext(ctx, `
+		SELECT ` + bankLineColumns + `
+		FROM bank_statement_lines
+		WHERE id = $1
+	`, lineID).Scan(bankLineFields(&l)...)
+
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("Bank statement line")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+func lockBankLine(ctx context.Context, q querier, lineID string) (*models.BankStatementLine, error) {
+	if _, err := q.ExecContext(ctx, `SELECT id FROM bank_statement_lines WHERE id = $1 FOR UPDATE`, lineID); err != nil {
+		return nil, err
+	}
+	return getBankLine(ctx, q, lineID)
+}
+
+// Scan targets in the order of bankLineColumns
+func bankLineFields(l *models.BankStatementLine) []any {
+	return []any{
+		&l.ID,
+		&l.StatementID,
+		&l.LineNumber,
+		&l.TxnDate,
+		&l.Description,
+		&l.Reference,
+		&l.Amount,
+		&l.Status,
+		&l.TransactionID,
+		&l.PaymentID,
+		&l.CreatedAt,
+		&l.UpdatedAt,
+	}
+}
+
+func setBankLineStatus(ctx context.Context, q querier, l *models.BankStatementLine, status string) error {
+	return q.QueryRowContext(ctx, `
+		UPDATE bank_statement_lines
+		SET status = $2, transaction_id = NULLIF($3, ''), payment_id = NULLIF($4, ''), updated_at = NOW()
+		WHERE id = $1
+		RETURNING status, updated_at
+	`, l.ID, status, l.TransactionID, l.PaymentID).Scan(&l.Status, &l.UpdatedAt)
+}
\ No newline at end of file
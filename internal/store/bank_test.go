@@ -0,0 +1,43 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kevinbrivio/batako-backend/internal/models"
+)
+
+func TestScoreBankMatch(t *testing.T) {
+	transfer := time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		customer    string
+		balance     float64
+		soldDaysAgo int
+		description string
+		want        int
+	}{
+		{"exact amount, recent, full name", "Budi Santoso", 1500000, 3, "trsf e-banking budi santoso", 100},
+		{"partial amount within a month", "Budi Santoso", 2000000, 20, "trsf e-banking", 30},
+		{"honorific is ignored", "Pak Budi", 1500000, 60, "trf pak budi", 80},
+		{"half the name after three months", "Budi Santoso", 1500000, 120, "trf budi", 62},
+		{"honorific alone never matches", "Bu Ani", 2000000, 200, "trf bu", 15},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sg := models.BankMatchSuggestion{
+				Customer:     tt.customer,
+				Balance:      tt.balance,
+				PurchaseDate: transfer.AddDate(0, 0, -tt.soldDaysAgo),
+			}
+			l := models.BankStatementLine{TxnDate: transfer, Amount: 1500000}
+
+			scoreBankMatch(&sg, &l, tt.description)
+			if sg.Score != tt.want {
+				t.Errorf("score = %d, want %d (reasons %v)", sg.Score, tt.want, sg.Reasons)
+			}
+		})
+	}
+}
@@ -0,0 +1,433 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kevinbrivio/batako-backend/internal/models"
+	"github.com/kevinbrivio/batako-backend/internal/utils"
+)
+
+type CashStore struct {
+	db *sql.DB
+}
+
+const cashSessionColumns = `
+	s.id,
+	s.session_date,
+	s.status,
+	s.opening_float,
+	COALESCE(m.cash_in, 0),
+	COALESCE(m.cash_out, 0),
+	s.expected_cash,
+	s.counted_cash,
+	s.discrepancy,
+	s.note,
+	s.closed_at,
+	s.created_at,
+	s.updated_at
+`
+
+const cashSessionJoins = `
+	LEFT JOIN (
+		SELECT
+			movement_date,
+			SUM(amount) FILTER (WHERE amount > 0) as cash_in,
+			-SUM(amount) FILTER (WHERE amount < 0) as cash_out
+		FROM cash_movements
+		GROUP BY movement_date
+	) m ON m.movement_date = s.session_date
+`
+
+func (s *CashStore) Open(ctx context.Context, cs *models.CashSession) error {
+	cs.ID = uuid.New().String()
+	cs.Status = models.CashSessionOpen
+
+	query := `
+		INSERT INTO cash_sessions (id, session_date, opening_float, note)
+		VALUES ($1, $2, $3, $4)
+		RETURNING created_at, updated_at
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	err := s.db.QueryRowContext(ctx, query, cs.ID, cs.SessionDate, cs.OpeningFloat, cs.Note).Scan(&cs.CreatedAt, &cs.UpdatedAt)
+	if isUniqueViolation(err) {
+		return utils.NewConflictError("A cash session is already open for " + cs.SessionDate.Format("2006-01-02"))
+	}
+	if err != nil {
+		return err
+	}
+
+	session, err := getCashSession(ctx, s.db, cs.SessionDate)
+	if err != nil {
+		return err
+	}
+	*cs = *session
+	return nil
+}
+
+// Sessions newest first, without their movements
+func (s *CashStore) GetAll(ctx context.Context, limit, offset int) ([]models.CashSession, int, error) {
+	query := `
+		SELECT ` + cashSessionColumns + `, COUNT(*) OVER() as total_count
+		FROM cash_sessions s
+		` + cashSessionJoins + `
+		ORDER BY s.session_date DESC
+		LIMIT $1 OFFSET $2
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	sessions := []models.CashSession{}
+	var totalCount int
+
+	for rows.Next() {
+		var cs models.CashSession
+		if err := rows.Scan(append(cashSessionFields(&cs), &totalCount)...); err != nil {
+			return sessions, 0, err
+		}
+		setExpectedCash(&cs)
+		sessions = append(sessions, cs)
+	}
+	if err = rows.Err(); err != nil {
+		return sessions, 0, err
+	}
+
+	return sessions, totalCount, nil
+}
+
+// The session of a day with every movement in and out of the drawer
+func (s *CashStore) GetByDate(ctx context.Context, date time.Time) (*models.CashSession, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	cs, err := getCashSession(ctx, s.db, date)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("Cash session")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	cs.Movements, err = getCashMovements(ctx, s.db, date)
+	if err != nil {
+		return nil, err
+	}
+	return cs, nil
+}
+
+// Summary of a day's session for the daily reports, nil when the drawer wasn't opened
+func (s *CashStore) GetClosing(ctx context.Context, date time.Time) (*models.CashSession, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	cs, err := getCashSession(ctx, s.db, date)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return cs, nil
+}
+
+// Record the cashier's count. Any difference from the expected cash is posted
+// to Cash Over and Short so the ledger's Cash matches the drawer.
+func (s *CashStore) Close(ctx context.Context, date time.Time, counted float64, note string) (*models.CashSession, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	var cs *models.CashSession
+	err := withTx(ctx, s.db, func(tx *sql.Tx) error {
+		var err error
+		cs, err = lockCashSession(ctx, tx, date)
+		if err != nil {
+			return err
+		}
+
+		if cs.Status == models.CashSessionClosed {
+			return utils.NewConflictError("Cash for " + date.Format("2006-01-02") + " is already closed")
+		}
+
+		cs.CountedCash = counted
+		cs.Discrepancy = math.Round((counted - cs.ExpectedCash) * 100) / 100
+		if note != "" {
+			cs.Note = note
+		}
+
+		err = tx.QueryRowContext(ctx, `
+			UPDATE cash_sessions
+			SET status = $2, expected_cash = $3, counted_cash = $4, discrepancy = $5, note = $6, closed_at = NOW(), updated_at = NOW()
+			WHERE id = $1
+			RETURNING status, closed_at, updated_at
+		`, cs.ID, models.CashSessionClosed, cs.ExpectedCash, cs.CountedCash, cs.Discrepancy, cs.Note).Scan(&cs.Status, &cs.ClosedAt, &cs.UpdatedAt)
+		if err != nil {
+			return err
+		}
+
+		return postCashSessionJournal(ctx, tx, cs)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return cs, nil
+}
+
+// Reopen a closed day so its payments and expenses can be corrected; the
+// count has to be made again afterwards
+func (s *CashStore) Reopen(ctx context.Context, date time.Time) (*models.CashSession, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	var cs *models.CashSession
+	err := withTx(ctx, s.db, func(tx *sql.Tx) error {
+		var err error
+		cs, err = lockCashSession(ctx, tx, date)
+		if err != nil {
+			return err
+		}
+
+		if cs.Status == models.CashSessionOpen {
+			return utils.NewConflictError("Cash for " + date.Format("2006-01-02") + " is not closed")
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE cash_sessions
+			SET status = $2, expected_cash = 0, counted_cash = 0, discrepancy = 0, closed_at = NULL, updated_at = NOW()
+			WHERE id = $1
+		`, cs.ID, models.CashSessionOpen)
+		if err != nil {
+			return err
+		}
+
+		if err := reverseJournalEntries(ctx, tx, models.JournalSourceCashSession, cs.ID, "Cash session reopened"); err != nil {
+			return err
+		}
+
+		cs, err = getCashSession(ctx, tx, date)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return cs, nil
+}
+
+func (s *CashStore) Delete(ctx context.Context, date time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, `
+		DELETE FROM cash_sessions WHERE session_date = $1::date AND status = $2
+	`, date, models.CashSessionOpen)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		if _, err := getCashSession(ctx, s.db, date); err == sql.ErrNoRows {
+			return utils.NewNotFoundError("Cash session")
+		}
+		return utils.NewConflictError("A closed cash session cannot be deleted, reopen it first")
+	}
+	return nil
+}
+
+// Returns sql.ErrNoRows when no session was opened on the day
+func getCashSession(ctx context.Context, q querier, date time.Time) (*models.CashSession, error) {
+	var cs models.CashSession
+
+	err := q.QueryRowContext(ctx, `
+		SELECT ` + cashSessionColumns + `
+		FROM cash_sessions s
+		` + cashSessionJoins + `
+		WHERE s.session_date = $1::date
+	`, date).Scan(cashSessionFields(&cs)...)
+	if err != nil {
+		return nil, err
+	}
+
+	setExpectedCash(&cs)
+	return &cs, nil
+}
+
+func lockCashSession(ctx context.Context, q querier, date time.Time) (*models.CashSession, error) {
+	_, err := q.ExecContext(ctx, `SELECT id FROM cash_sessions WHERE session_date = $1::date FOR UPDATE`, date)
+	if err != nil {
+		return nil, err
+	}
+
+	cs, err := getCashSession(ctx, q, date)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("Cash session")
+	}
+	return cs, err
+}
+
+// Scan targets in the order of cashSessionColumns
+func cashSessionFields(cs *models.CashSession) []any {
+	return []any{
+		&cs.ID,
+		&cs.SessionDate,
+		&cs.Status,
+		&cs.OpeningFloat,
+		&cs.CashIn,
+		&cs.CashOut,
+		&cs.ExpectedCash,
+		&cs.CountedCash,
+		&cs.Discrepancy,
+		&cs.Note,
+		&cs.ClosedAt,
+		&cs.CreatedAt,
+		&cs.UpdatedAt,
+	}
+}
+
+// A closed session keeps the expected cash it was counted against; an open
+// one follows the movements as they come in
+func setExpectedCash(cs *models.CashSession) {
+	if cs.Status == models.CashSessionOpen {
+		cs.ExpectedCash = cs.OpeningFloat + cs.CashIn - cs.CashOut
+	}
+}
+
+func getCashMovements(ctx context.Context, q querier, date time.Time) ([]models.CashMovement, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT id, movement_date, source_type, source_id, amount, description, created_at
+		FROM cash_movements
+		WHERE movement_date = $1::date
+		ORDER BY created_at ASC
+	`, date)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	movements := []models.CashMovement{}
+	for rows.Next() {
+		var m models.CashMovement
+		if err := rows.Scan(&m.ID, &m.MovementDate, &m.SourceType, &m.SourceID, &m.Amount, &m.Description, &m.CreatedAt); err != nil {
+			return movements, err
+		}
+		movements = append(movements, m)
+	}
+
+	return movements, rows.Err()
+}
+
+// Refuse changes to the cash of a day that has been counted and closed. The
+// session row is share-locked so a closing can't slip in before the change commits.
+func ensureCashOpen(ctx context.Context, q querier, date time.Time) error {
+	var status string
+	err := q.QueryRowContext(ctx, `
+		SELECT status FROM cash_sessions WHERE session_date = $1::date FOR SHARE
+	`, date).Scan(&status)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if status == models.CashSessionClosed {
+		return utils.NewConflictError("Cash for " + date.Format("2006-01-02") + " is closed; reopen it to make changes")
+	}
+	return nil
+}
+
+// Replace what a source has put into or taken out of the drawer. An amount of
+// zero just removes it, e.g. when the payment or expense is deleted.
+func syncCashMovement(ctx context.Context, q querier, m *models.CashMovement) error {
+	rows, err := q.QueryContext(ctx, `
+		SELECT DISTINCT movement_date FROM cash_movements WHERE source_type = $1 AND source_id = $2
+	`, m.SourceType, m.SourceID)
+	if err != nil {
+		return err
+	}
+
+	dates := []time.Time{}
+	for rows.Next() {
+		var d time.Time
+		if err := rows.Scan(&d); err != nil {
+			rows.Close()
+			return err
+		}
+		dates = append(dates, d)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, d := range dates {
+		if err := ensureCashOpen(ctx, q, d); err != nil {
+			return err
+		}
+	}
+
+	_, err = q.ExecContext(ctx, `
+		DELETE FROM cash_movements WHERE source_type = $1 AND source_id = $2
+	`, m.SourceType, m.SourceID)
+	if err != nil {
+		return err
+	}
+
+	if m.Amount == 0 {
+		return nil
+	}
+
+	if err := ensureCashOpen(ctx, q, m.MovementDate); err != nil {
+		return err
+	}
+
+	m.ID = uuid.New().String()
+
+	return q.QueryRowContext(ctx, `
+		INSERT INTO cash_movements (id, movement_date, source_type, source_id, amount, description)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING created_at
+	`, m.ID, m.MovementDate, m.SourceType, m.SourceID, m.Amount, m.Description).Scan(&m.CreatedAt)
+}
+
+func postCashSessionJournal(ctx context.Context, q querier, cs *models.CashSession) error {
+	e := &models.JournalEntry{
+		EntryDate: cs.SessionDate,
+		SourceType: models.JournalSourceCashSession,
+		SourceID: cs.ID,
+		Description: "Cash count difference",
+	}
+
+	switch {
+	case cs.Discrepancy < 0:
+		e.Lines = []models.JournalLine{
+			{AccountCode: models.AccountCodeCashOverShort, Debit: -cs.Discrepancy},
+			{AccountCode: models.AccountCodeCash, Credit: -cs.Discrepancy},
+		}
+	case cs.Discrepancy > 0:
+		e.Lines = []models.JournalLine{
+			{AccountCode: models.AccountCodeCash, Debit: cs.Discrepancy},
+			{AccountCode: models.AccountCodeCashOverShort, Credit: cs.Discrepancy},
+		}
+	}
+
+	return syncJournalEntry(ctx, q, e, "Cash recounted")
+}
\ No newline at end of file
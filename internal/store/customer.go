@@ -0,0 +1,587 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kevinbrivio/batako-backend/internal/models"
+	"github.com/kevinbrivio/batako-backend/internal/utils"
+	"github.com/lib/pq"
+)
+
+type CustomerStore struct {
+	db *sql.DB
+}
+
+func (s *CustomerStore) Create(ctx context.Context, c *models.Customer) error {
+	c.ID = uuid.New().String()
+	c.Name = utils.CleanName(c.Name)
+
+	query := `
+		INSERT INTO customers (id, name, normalized_name, tax_exempt)
+		VALUES ($1, $2, $3, $4)
+		RETURNING created_at, updated_at
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(
+			ctx,
+			query,
+			c.ID,
+			c.Name,
+			utils.NormalizeName(c.Name),
+			c.TaxExempt,
+		).Scan(
+			&c.CreatedAt,
+			&c.UpdatedAt,
+		)
+
+		if isUniqueViolation(err) {
+			return utils.NewConflictError("Customer already exists")
+		}
+
+		if err != nil {
+			return err
+		}
+
+		// First address becomes the default unless one is chosen explicitly
+		hasDefault := false
+		for _, a := range c.Addresses {
+			hasDefault = hasDefault || a.IsDefault
+		}
+
+		for i := range c.Addresses {
+			c.Addresses[i].CustomerID = c.ID
+			if !hasDefault && i == 0 {
+				c.Addresses[i].IsDefault = true
+			}
+			if err := insertCustomerAddress(ctx, tx, &c.Addresses[i]); err != nil {
+				return err
+			}
+		}
+
+		for i := range c.Phones {
+			c.Phones[i].CustomerID = c.ID
+			if err := insertCustomerPhone(ctx, tx, &c.Phones[i]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (s *CustomerStore) GetAll(ctx context.Context, search string, limit, offset int) ([]models.Customer, int, error) {
+	query := `
+		SELECT
+			id,
+			name,
+			tax_exempt,
+			COUNT(*) OVER() as total_count,
+			created_at,
+			updated_at
+		FROM customers
+		WHERE normalized_name LIKE '%' || $1 || '%'
+		ORDER BY name ASC
+		LIMIT $2 OFFSET $3
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, utils.NormalizeName(search), limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	customers := []models.Customer{}
+	var totalCount int
+
+	for rows.Next() {
+		var c models.Customer
+		if err := rows.Scan(
+			&c.ID,
+			&c.Name,
+			&c.TaxExempt,
+			&totalCount,
+			&c.CreatedAt,
+			&c.UpdatedAt,
+		); err != nil {
+			return customers, 0, err
+		}
+		customers = append(customers, c)
+	}
+	if err = rows.Err(); err != nil {
+		return customers, 0, err
+	}
+
+	if err := s.loadContacts(ctx, customers); err != nil {
+		return customers, 0, err
+	}
+
+	return customers, totalCount, nil
+}
+
+func (s *CustomerStore) GetByID(ctx context.Context, cID string) (*models.Customer, error) {
+	query := `
+		SELECT id, name, tax_exempt, created_at, updated_at
+		FROM customers
+		WHERE id = $1
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	var c models.Customer
+
+	err := s.db.QueryRowContext(
+		ctx, query,
+		cID,
+	).Scan(
+		&c.ID,
+		&c.Name,
+		&c.TaxExempt,
+		&c.CreatedAt,
+		&c.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("Customer")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	customers := []models.Customer{c}
+	if err := s.loadContacts(ctx, customers); err != nil {
+		return nil, err
+	}
+
+	return &customers[0], nil
+}
+
+func (s *CustomerStore) Update(ctx context.Context, c *models.Customer) error {
+	c.Name = utils.CleanName(c.Name)
+
+	query := `
+		UPDATE customers
+		SET name = $2, normalized_name = $3, tax_exempt = $4, updated_at = NOW()
+		WHERE id = $1
+		RETURNING created_at, updated_at
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	err := s.db.QueryRowContext(
+		ctx,
+		query,
+		c.ID,
+		c.Name,
+		utils.NormalizeName(c.Name),
+		c.TaxExempt,
+	).Scan(
+		&c.CreatedAt,
+		&c.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return utils.NewNotFoundError("Customer")
+	}
+
+	if isUniqueViolation(err) {
+		return utils.NewConflictError("Another customer already has this name")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	customers := []models.Customer{*c}
+	if err := s.loadContacts(ctx, customers); err != nil {
+		return err
+	}
+	*c = customers[0]
+
+	return nil
+}
+
+func (s *CustomerStore) Delete(ctx context.Context, cID string) error {
+	query := `
+		DELETE FROM customers
+		WHERE id = $1;
+	`
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, cID)
+	if isForeignKeyViolation(err) {
+		return utils.NewConflictError("Customer has transactions and cannot be deleted")
+	}
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return utils.NewNotFoundError("Customer")
+	}
+	return nil
+}
+
+func (s *CustomerStore) AddAddress(ctx context.Context, a *models.CustomerAddress) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		if err := ensureCustomerExists(ctx, tx, a.CustomerID); err != nil {
+			return err
+		}
+
+		if a.IsDefault {
+			if err := clearDefaultAddress(ctx, tx, a.CustomerID); err != nil {
+				return err
+			}
+		}
+
+		return insertCustomerAddress(ctx, tx, a)
+	})
+}
+
+func (s *CustomerStore) UpdateAddress(ctx context.Context, a *models.CustomerAddress) error {
+	a.Address = strings.TrimSpace(a.Address)
+
+	query := `
+		UPDATE customer_addresses
+		SET label = $3, address = $4, is_default = $5, updated_at = NOW()
+		WHERE id = $1 AND customer_id = $2
+		RETURNING created_at, updated_at
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		if a.IsDefault {
+			if err := clearDefaultAddress(ctx, tx, a.CustomerID); err != nil {
+				return err
+			}
+		}
+
+		err := tx.QueryRowContext(
+			ctx,
+			query,
+			a.ID,
+			a.CustomerID,
+			a.Label,
+			a.Address,
+			a.IsDefault,
+		).Scan(
+			&a.CreatedAt,
+			&a.UpdatedAt,
+		)
+
+		if err == sql.ErrNoRows {
+			return utils.NewNotFoundError("Address")
+		}
+
+		if isUniqueViolation(err) {
+			return utils.NewConflictError("Customer already has this address")
+		}
+
+		return err
+	})
+}
+
+func (s *CustomerStore) DeleteAddress(ctx context.Context, cID, aID string) error {
+	query := `
+		DELETE FROM customer_addresses
+		WHERE id = $1 AND customer_id = $2;
+	`
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, aID, cID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return utils.NewNotFoundError("Address")
+	}
+	return nil
+}
+
+func (s *CustomerStore) AddPhone(ctx context.Context, p *models.CustomerPhone) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		if err := ensureCustomerExists(ctx, tx, p.CustomerID); err != nil {
+			return err
+		}
+
+		return insertCustomerPhone(ctx, tx, p)
+	})
+}
+
+func (s *CustomerStore) DeletePhone(ctx context.Context, cID, pID string) error {
+	query := `
+		DELETE FROM customer_phones
+		WHERE id = $1 AND customer_id = $2;
+	`
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, pID, cID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return utils.NewNotFoundError("Phone")
+	}
+	return nil
+}
+
+// Fill in addresses and phones for a page of customers with one query each
+func (s *CustomerStore) loadContacts(ctx context.Context, customers []models.Customer) error {
+	if len(customers) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(customers))
+	index := make(map[string]int, len(customers))
+	for i := range customers {
+		ids[i] = customers[i].ID
+		index[customers[i].ID] = i
+		customers[i].Addresses = []models.CustomerAddress{}
+		customers[i].Phones = []models.CustomerPhone{}
+	}
+
+	addrQuery := `
+		SELECT id, customer_id, label, address, is_default, created_at, updated_at
+		FROM customer_addresses
+		WHERE customer_id = ANY($1)
+		ORDER BY is_default DESC, created_at ASC
+	`
+
+	rows, err := s.db.QueryContext(ctx, addrQuery, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var a models.CustomerAddress
+		if err := rows.Scan(
+			&a.ID,
+			&a.CustomerID,
+			&a.Label,
+			&a.Address,
+			&a.IsDefault,
+			&a.CreatedAt,
+			&a.UpdatedAt,
+		); err != nil {
+			return err
+		}
+		i := index[a.CustomerID]
+		customers[i].Addresses = append(customers[i].Addresses, a)
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	phoneQuery := `
+		SELECT id, customer_id, label, phone, created_at, updated_at
+		FROM customer_phones
+		WHERE customer_id = ANY($1)
+		ORDER BY created_at ASC
+	`
+
+	phoneRows, err := s.db.QueryContext(ctx, phoneQuery, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer phoneRows.Close()
+
+	for phoneRows.Next() {
+		var p models.CustomerPhone
+		if err := phoneRows.Scan(
+			&p.ID,
+			&p.CustomerID,
+			&p.Label,
+			&p.Phone,
+			&p.CreatedAt,
+			&p.UpdatedAt,
+		); err != nil {
+			return err
+		}
+		i := index[p.CustomerID]
+		customers[i].Phones = append(customers[i].Phones, p)
+	}
+
+	return phoneRows.Err()
+}
+
+func insertCustomerAddress(ctx context.Context, q querier, a *models.CustomerAddress) error {
+	a.ID = uuid.New().String()
+	a.Address = strings.TrimSpace(a.Address)
+
+	query := `
+		INSERT INTO customer_addresses (id, customer_id, label, address, is_default)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at, updated_at
+	`
+
+	err := q.QueryRowContext(
+		ctx,
+		query,
+		a.ID,
+		a.CustomerID,
+		a.Label,
+		a.Address,
+		a.IsDefault,
+	).Scan(
+		&a.CreatedAt,
+		&a.UpdatedAt,
+	)
+
+	if isUniqueViolation(err) {
+		return utils.NewConflictError("Customer already has this address")
+	}
+
+	return err
+}
+
+func insertCustomerPhone(ctx context.Context, q querier, p *models.CustomerPhone) error {
+	p.ID = uuid.New().String()
+	p.Phone = strings.TrimSpace(p.Phone)
+
+	query := `
+		INSERT INTO customer_phones (id, customer_id, label, phone)
+		VALUES ($1, $2, $3, $4)
+		RETURNING created_at, updated_at
+	`
+
+	return q.QueryRowContext(
+		ctx,
+		query,
+		p.ID,
+		p.CustomerID,
+		p.Label,
+		p.Phone,
+	).Scan(
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	)
+}
+
+func clearDefaultAddress(ctx context.Context, q querier, cID string) error {
+	query := `
+		UPDATE customer_addresses
+		SET is_default = FALSE, updated_at = NOW()
+		WHERE customer_id = $1 AND is_default
+	`
+
+	_, err := q.ExecContext(ctx, query, cID)
+	return err
+}
+
+func ensureCustomerExists(ctx context.Context, q querier, cID string) error {
+	var exists bool
+	err := q.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM customers WHERE id = $1)`, cID).Scan(&exists)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return utils.NewNotFoundError("Customer")
+	}
+	return nil
+}
+
+// Fill in the customer and delivery address of a transaction. A known customer_id
+// uses its address book; a free-text customer name is matched case- and
+// space-insensitively and created on first sale, so "Pak Budi" and "pak budi "
+// end up as the same buyer.
+func resolveTransactionCustomer(ctx context.Context, q querier, t *models.Transaction) error {
+	if t.CustomerID == "" {
+		query := `
+			INSERT INTO customers (id, name, normalized_name)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (normalized_name) DO UPDATE SET normalized_name = EXCLUDED.normalized_name
+			RETURNING id, name
+		`
+
+		name := utils.CleanName(t.Customer)
+		err := q.QueryRowContext(ctx, query, uuid.New().String(), name, utils.NormalizeName(name)).Scan(&t.CustomerID, &t.Customer)
+		if err != nil {
+			return err
+		}
+	} else {
+		err := q.QueryRowContext(ctx, `SELECT name FROM customers WHERE id = $1`, t.CustomerID).Scan(&t.Customer)
+		if err == sql.ErrNoRows {
+			return utils.NewBadRequestError("Customer does not exist")
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	var err error
+	switch {
+	case t.AddressID != "":
+		err = q.QueryRowContext(ctx, `
+			SELECT address FROM customer_addresses
+			WHERE id = $1 AND customer_id = $2
+		`, t.AddressID, t.CustomerID).Scan(&t.Address)
+		if err == sql.ErrNoRows {
+			return utils.NewBadRequestError("Address does not belong to this customer")
+		}
+
+	case strings.TrimSpace(t.Address) != "":
+		// Reuse a matching address book entry or add a new one; the very first becomes default
+		err = q.QueryRowContext(ctx, `
+			INSERT INTO customer_addresses (id, customer_id, address, is_default)
+			VALUES ($1, $2, $3, NOT EXISTS (SELECT 1 FROM customer_addresses WHERE customer_id = $2))
+			ON CONFLICT (customer_id, lower(address)) DO UPDATE SET updated_at = customer_addresses.updated_at
+			RETURNING id, address
+		`, uuid.New().String(), t.CustomerID, strings.TrimSpace(t.Address)).Scan(&t.AddressID, &t.Address)
+
+	default:
+		err = q.QueryRowContext(ctx, `
+			SELECT id, address FROM customer_addresses
+			WHERE customer_id = $1
+			ORDER BY is_default DESC, created_at ASC
+			LIMIT 1
+		`, t.CustomerID).Scan(&t.AddressID, &t.Address)
+		if err == sql.ErrNoRows {
+			return utils.NewBadRequestError("Customer has no address, please provide one")
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,466 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kevinbrivio/batako-backend/internal/models"
+	"github.com/kevinbrivio/batako-backend/internal/utils"
+)
+
+type DeliveryStore struct {
+	db *sql.DB
+}
+
+// Allowed status changes, anything else is rejected
+var deliveryTransitions = map[string][]string{
+	models.DeliveryScheduled: {models.DeliveryLoaded},
+	models.DeliveryLoaded: {models.DeliveryDelivered, models.DeliveryFailed},
+}
+
+const deliveryColumns = `
+	d.id,
+	d.delivery_number,
+	d.transaction_id,
+	d.transaction_item_id,
+	t.customer,
+	t.address,
+	COALESCE(pr.name, ''),
+	d.scheduled_date,
+	d.quantity,
+	d.vehicle,
+	d.driver,
+	d.status,
+	d.note,
+	d.loaded_at,
+	d.delivered_at,
+	d.created_at,
+	d.updated_at
+`
+
+const deliveryJoins = `
+	FROM deliveries d
+	JOIN transactions t ON t.id = d.transaction_id
+	JOIN transaction_items i ON i.id = d.transaction_item_id
+	LEFT JOIN products pr ON pr.id = i.product_id
+`
+
+func (s *DeliveryStore) Create(ctx context.Context, d *models.Delivery) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		if err := ensureDeliverableQuantity(ctx, tx, d, ""); err != nil {
+			return err
+		}
+
+		d.ID = uuid.New().String()
+		d.Status = models.DeliveryScheduled
+
+		var err error
+		d.DeliveryNumber, err = nextDeliveryNumber(ctx, tx, time.Now())
+		if err != nil {
+			return err
+		}
+
+		query := `
+			INSERT INTO deliveries (id, delivery_number, transaction_id, transaction_item_id, scheduled_date, quantity, vehicle, driver, status, note)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			RETURNING created_at, updated_at
+		`
+
+		err = tx.QueryRowContext(
+			ctx,
+			query,
+			d.ID,
+			d.DeliveryNumber,
+			d.TransactionID,
+			d.TransactionItemID,
+			d.ScheduledDate,
+			d.Quantity,
+			d.Vehicle,
+			d.Driver,
+			d.Status,
+			d.Note,
+		).Scan(
+			&d.CreatedAt,
+			&d.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+
+		created, err := getDelivery(ctx, tx, d.ID)
+		if err != nil {
+			return err
+		}
+		*d = *created
+		return nil
+	})
+}
+
+// Deliveries across all sales, optionally for a single day and/or status
+func (s *DeliveryStore) GetAll(ctx context.Context, date, status string, limit, offset int) ([]models.Delivery, int, error) {
+	query := `
+		SELECT` + deliveryColumns + `,
+			COUNT(*) OVER() as total_count
+		` + deliveryJoins + `
+		WHERE ($1 = '' OR d.scheduled_date = NULLIF($1, '')::date)
+			AND ($2 = '' OR d.status = $2)
+		ORDER BY d.scheduled_date ASC, d.delivery_number ASC
+		LIMIT $3 OFFSET $4
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, date, status, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	deliveries := []models.Delivery{}
+	var totalCount int
+
+	for rows.Next() {
+		var d models.Delivery
+		var loadedAt, deliveredAt sql.NullTime
+		if err := rows.Scan(
+			&d.ID,
+			&d.DeliveryNumber,
+			&d.TransactionID,
+			&d.TransactionItemID,
+			&d.Customer,
+			&d.Address,
+			&d.Product,
+			&d.ScheduledDate,
+			&d.Quantity,
+			&d.Vehicle,
+			&d.Driver,
+			&d.Status,
+			&d.Note,
+			&loadedAt,
+			&deliveredAt,
+			&d.CreatedAt,
+			&d.UpdatedAt,
+			&totalCount,
+		); err != nil {
+			return deliveries, 0, err
+		}
+		setDeliveryTimes(&d, loadedAt, deliveredAt)
+		deliveries = append(deliveries, d)
+	}
+	if err = rows.Err(); err != nil {
+		return deliveries, 0, err
+	}
+
+	return deliveries, totalCount, nil
+}
+
+func (s *DeliveryStore) GetByTransaction(ctx context.Context, tID string) ([]models.Delivery, error) {
+	query := `
+		SELECT` + deliveryColumns + deliveryJoins + `
+		WHERE d.transaction_id = $1
+		ORDER BY d.scheduled_date ASC, d.delivery_number ASC
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, tID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []models.Delivery{}
+
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return deliveries, err
+		}
+		deliveries = append(deliveries, *d)
+	}
+	if err = rows.Err(); err != nil {
+		return deliveries, err
+	}
+
+	return deliveries, nil
+}
+
+func (s *DeliveryStore) GetByID(ctx context.Context, dID string) (*models.Delivery, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	return getDelivery(ctx, s.db, dID)
+}
+
+// Reschedule or reassign a delivery that hasn't left the yard yet
+func (s *DeliveryStore) Update(ctx context.Context, d *models.Delivery) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		current, err := getDeliveryForUpdate(ctx, tx, d.ID)
+		if err != nil {
+			return err
+		}
+
+		if current.Status != models.DeliveryScheduled {
+			return utils.NewConflictError("Only scheduled deliveries can be changed")
+		}
+
+		// A load stays on the line it was planned for
+		d.TransactionID, d.TransactionItemID = current.TransactionID, current.TransactionItemID
+		if err := ensureDeliverableQuantity(ctx, tx, d, d.ID); err != nil {
+			return err
+		}
+
+		query := `
+			UPDATE deliveries
+			SET scheduled_date = $2, quantity = $3, vehicle = $4, driver = $5, note = $6, updated_at = NOW()
+			WHERE id = $1
+		`
+
+		_, err = tx.ExecContext(ctx, query, d.ID, d.ScheduledDate, d.Quantity, d.Vehicle, d.Driver, d.Note)
+		if err != nil {
+			return err
+		}
+
+		updated, err := getDelivery(ctx, tx, d.ID)
+		if err != nil {
+			return err
+		}
+		*d = *updated
+		return nil
+	})
+}
+
+// Move a delivery along scheduled -> loaded -> delivered/failed
+func (s *DeliveryStore) UpdateStatus(ctx context.Context, dID string, u *models.DeliveryStatusUpdate) (*models.Delivery, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	var d *models.Delivery
+
+	err := withTx(ctx, s.db, func(tx *sql.Tx) error {
+		current, err := getDeliveryForUpdate(ctx, tx, dID)
+		if err != nil {
+			return err
+		}
+
+		if !canTransitionDelivery(current.Status, u.Status) {
+			return utils.NewConflictError(fmt.Sprintf("Delivery cannot go from %s to %s", current.Status, u.Status))
+		}
+
+		note := current.Note
+		if u.Note != "" {
+			note = u.Note
+		}
+
+		query := `
+			UPDATE deliveries
+			SET status = $2,
+				note = $3,
+				loaded_at = CASE WHEN $2 = 'loaded' THEN NOW() ELSE loaded_at END,
+				delivered_at = CASE WHEN $2 = 'delivered' THEN NOW() ELSE delivered_at END,
+				updated_at = NOW()
+			WHERE id = $1
+		`
+
+		if _, err := tx.ExecContext(ctx, query, dID, u.Status, note); err != nil {
+			return err
+		}
+
+		d, err = getDelivery(ctx, tx, dID)
+		return err
+	})
+
+	return d, err
+}
+
+// Only deliveries that are still just a plan can be removed
+func (s *DeliveryStore) Delete(ctx context.Context, dID string) error {
+	query := `
+		DELETE FROM deliveries
+		WHERE id = $1 AND status = 'scheduled'
+	`
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, dID)
+	if isForeignKeyViolation(err) {
+		return utils.NewConflictError("Delivery has proofs of delivery and cannot be deleted")
+	}
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		if _, err := getDelivery(ctx, s.db, dID); err != nil {
+			return err
+		}
+		return utils.NewConflictError("Only scheduled deliveries can be deleted")
+	}
+	return nil
+}
+
+func canTransitionDelivery(from, to string) bool {
+	for _, next := range deliveryTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Make sure the sale line still has enough undelivered quantity for another
+// load. Failed loads don't count, their quantity has to be delivered again.
+// When no line is given and the sale has a single product line, that one is used.
+func ensureDeliverableQuantity(ctx context.Context, q querier, d *models.Delivery, excludeID string) error {
+	var exists bool
+	err := q.QueryRowContext(ctx, `SELECT TRUE FROM transactions WHERE id = $1 FOR UPDATE`, d.TransactionID).Scan(&exists)
+	if err == sql.ErrNoRows {
+		return utils.NewNotFoundError("Transaction")
+	}
+	if err != nil {
+		return err
+	}
+
+	rows, err := q.QueryContext(ctx, `
+		SELECT id, quantity
+		FROM transaction_items
+		WHERE transaction_id = $1 AND product_id IS NOT NULL AND ($2 = '' OR id = $2)
+	`, d.TransactionID, d.TransactionItemID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var ordered, lines int
+	for rows.Next() {
+		if err := rows.Scan(&d.TransactionItemID, &ordered); err != nil {
+			return err
+		}
+		lines++
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if lines == 0 {
+		return utils.NewBadRequestError("Product line does not exist on this transaction")
+	}
+	if lines > 1 {
+		return utils.NewBadRequestError("Transaction item is required when a sale has several product lines")
+	}
+
+	var planned int
+	err = q.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(quantity), 0)
+		FROM deliveries
+		WHERE transaction_item_id = $1 AND status <> 'failed' AND id <> $2
+	`, d.TransactionItemID, excludeID).Scan(&planned)
+	if err != nil {
+		return err
+	}
+
+	if remaining := ordered - planned; d.Quantity > remaining {
+		return utils.NewConflictError(fmt.Sprintf("Only %d left to deliver, %d requested", remaining, d.Quantity))
+	}
+	return nil
+}
+
+func getDelivery(ctx context.Context, q querier, dID string) (*models.Delivery, error) {
+	query := `
+		SELECT` + deliveryColumns + deliveryJoins + `
+		WHERE d.id = $1
+	`
+
+	d, err := scanDelivery(q.QueryRowContext(ctx, query, dID))
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("Delivery")
+	}
+	return d, err
+}
+
+func getDeliveryForUpdate(ctx context.Context, q querier, dID string) (*models.Delivery, error) {
+	var d models.Delivery
+	err := q.QueryRowContext(ctx, `
+		SELECT id, transaction_id, transaction_item_id, status, note FROM deliveries WHERE id = $1 FOR UPDATE
+	`, dID).Scan(&d.ID, &d.TransactionID, &d.TransactionItemID, &d.Status, &d.Note)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("Delivery")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanDelivery(row rowScanner) (*models.Delivery, error) {
+	var d models.Delivery
+	var loadedAt, deliveredAt sql.NullTime
+	if err := row.Scan(
+		&d.ID,
+		&d.DeliveryNumber,
+		&d.TransactionID,
+		&d.TransactionItemID,
+		&d.Customer,
+		&d.Address,
+		&d.Product,
+		&d.ScheduledDate,
+		&d.Quantity,
+		&d.Vehicle,
+		&d.Driver,
+		&d.Status,
+		&d.Note,
+		&loadedAt,
+		&deliveredAt,
+		&d.CreatedAt,
+		&d.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	setDeliveryTimes(&d, loadedAt, deliveredAt)
+	return &d, nil
+}
+
+func setDeliveryTimes(d *models.Delivery, loadedAt, deliveredAt sql.NullTime) {
+	if loadedAt.Valid {
+		d.LoadedAt = &loadedAt.Time
+	}
+	if deliveredAt.Valid {
+		d.DeliveredAt = &deliveredAt.Time
+	}
+}
+
+// Same scheme as invoice numbers: per month, allocated inside the caller's transaction
+func nextDeliveryNumber(ctx context.Context, q querier, date time.Time) (string, error) {
+	var next int
+	err := q.QueryRowContext(ctx, `
+		INSERT INTO delivery_sequences (period, last_number)
+		VALUES ($1, 1)
+		ON CONFLICT (period) DO UPDATE SET last_number = delivery_sequences.last_number + 1
+		RETURNING last_number
+	`, date.Format("2006-01")).Scan(&next)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("SJ/%04d/%02d/%04d", date.Year(), int(date.Month()), next), nil
+}
@@ -0,0 +1,181 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/kevinbrivio/batako-backend/internal/models"
+	"github.com/kevinbrivio/batako-backend/internal/utils"
+)
+
+type DeliveryProofStore struct {
+	db *sql.DB
+}
+
+// Record an uploaded proof. The files are already in blob storage, the ID is
+// chosen by the caller since it is part of their keys.
+func (s *DeliveryProofStore) Create(ctx context.Context, p *models.DeliveryProof) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	if p.DeliveryID != "" {
+		var tID string
+		err := s.db.QueryRowContext(ctx, `SELECT transaction_id FROM deliveries WHERE id = $1`, p.DeliveryID).Scan(&tID)
+		if err == sql.ErrNoRows || (err == nil && tID != p.TransactionID) {
+			return utils.NewBadRequestError("Delivery does not belong to this transaction")
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	query := `
+		INSERT INTO delivery_proofs (id, transaction_id, delivery_id, kind, file_name, content_type, size, storage_key, thumbnail_key)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $7, $8, $9)
+		RETURNING created_at
+	`
+
+	err := s.db.QueryRowContext(
+		ctx,
+		query,
+		p.ID,
+		p.TransactionID,
+		p.DeliveryID,
+		p.Kind,
+		p.FileName,
+		p.ContentType,
+		p.Size,
+		p.StorageKey,
+		p.ThumbnailKey,
+	).Scan(
+		&p.CreatedAt,
+	)
+
+	if isForeignKeyViolation(err) {
+		return utils.NewNotFoundError("Transaction")
+	}
+	return err
+}
+
+func (s *DeliveryProofStore) GetByTransaction(ctx context.Context, tID string) ([]models.DeliveryProof, error) {
+	query := `
+		SELECT
+			id,
+			transaction_id,
+			COALESCE(delivery_id, ''),
+			kind,
+			file_name,
+			content_type,
+			size,
+			storage_key,
+			thumbnail_key,
+			created_at
+		FROM delivery_proofs
+		WHERE transaction_id = $1
+		ORDER BY created_at ASC
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, tID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	proofs := []models.DeliveryProof{}
+
+	for rows.Next() {
+		var p models.DeliveryProof
+		if err := rows.Scan(
+			&p.ID,
+			&p.TransactionID,
+			&p.DeliveryID,
+			&p.Kind,
+			&p.FileName,
+			&p.ContentType,
+			&p.Size,
+			&p.StorageKey,
+			&p.ThumbnailKey,
+			&p.CreatedAt,
+		); err != nil {
+			return proofs, err
+		}
+		proofs = append(proofs, p)
+	}
+	if err = rows.Err(); err != nil {
+		return proofs, err
+	}
+
+	return proofs, nil
+}
+
+func (s *DeliveryProofStore) GetByID(ctx context.Context, tID, pID string) (*models.DeliveryProof, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	return getDeliveryProof(ctx, s.db, tID, pID)
+}
+
+// Remove the record and hand it back so the caller can clean up the files
+func (s *DeliveryProofStore) Delete(ctx context.Context, tID, pID string) (*models.DeliveryProof, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	var p *models.DeliveryProof
+
+	err := withTx(ctx, s.db, func(tx *sql.Tx) error {
+		var err error
+		p, err = getDeliveryProof(ctx, tx, tID, pID)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `DELETE FROM delivery_proofs WHERE id = $1`, pID)
+		return err
+	})
+
+	return p, err
+}
+
+func getDeliveryProof(ctx context.Context, q querier, tID, pID string) (*models.DeliveryProof, error) {
+	query := `
+		SELECT
+			id,
+			transaction_id,
+			COALESCE(delivery_id, ''),
+			kind,
+			file_name,
+			content_type,
+			size,
+			storage_key,
+			thumbnail_key,
+			created_at
+		FROM delivery_proofs
+		WHERE id = $1 AND transaction_id = $2
+	`
+
+	var p models.DeliveryProof
+	err := q.QueryRowContext(ctx, query, pID, tID).Scan(
+		&p.ID,
+		&p.TransactionID,
+		&p.DeliveryID,
+		&p.Kind,
+		&p.FileName,
+		&p.ContentType,
+		&p.Size,
+		&p.StorageKey,
+		&p.ThumbnailKey,
+		&p.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("Proof of delivery")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
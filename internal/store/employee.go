@@ -0,0 +1,275 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kevinbrivio/batako-backend/internal/models"
+	"github.com/kevinbrivio/batako-backend/internal/utils"
+)
+
+type EmployeeStore struct {
+	db *sql.DB
+}
+
+func (s *EmployeeStore) Create(ctx context.Context, e *models.Employee) error {
+	e.ID = uuid.New().String()
+	e.Name = utils.CleanName(e.Name)
+
+	query := `
+		INSERT INTO employees (id, name, phone, role, daily_wage, overtime_rate, is_active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING created_at, updated_at
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	return s.db.QueryRowContext(
+		ctx,
+		query,
+		e.ID,
+		e.Name,
+		e.Phone,
+		e.Role,
+		e.DailyWage,
+		e.OvertimeRate,
+		e.IsActive,
+	).Scan(
+		&e.CreatedAt,
+		&e.UpdatedAt,
+	)
+}
+
+// All employees, or only the ones still working when activeOnly is set
+func (s *EmployeeStore) GetAll(ctx context.Context, activeOnly bool, limit, offset int) ([]models.Employee, int, error) {
+	query := `
+		SELECT
+			id,
+			name,
+			phone,
+			role,
+			daily_wage,
+			overtime_rate,
+			is_active,
+			COUNT(*) OVER() as total_count,
+			created_at,
+			updated_at
+		FROM employees
+		WHERE NOT $1 OR is_active
+		ORDER BY name ASC
+		LIMIT $2 OFFSET $3
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, activeOnly, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	employees := []models.Employee{}
+	var totalCount int
+
+	for rows.Next() {
+		var e models.Employee
+		if err := rows.Scan(
+			&e.ID,
+			&e.Name,
+			&e.Phone,
+			&e.Role,
+			&e.DailyWage,
+			&e.OvertimeRate,
+			&e.IsActive,
+			&totalCount,
+			&e.CreatedAt,
+			&e.UpdatedAt,
+		); err != nil {
+			return employees, 0, err
+		}
+		employees = append(employees, e)
+	}
+	if err = rows.Err(); err != nil {
+		return employees, 0, err
+	}
+
+	return employees, totalCount, nil
+}
+
+func (s *EmployeeStore) GetByID(ctx context.Context, eID string) (*models.Employee, error) {
+	query := `
+		SELECT id, name, phone, role, daily_wage, overtime_rate, is_active, created_at, updated_at
+		FROM employees
+		WHERE id = $1
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	var e models.Employee
+
+	err := s.db.QueryRowContext(
+		ctx, query,
+		eID,
+	).Scan(
+		&e.ID,
+		&e.Name,
+		&e.Phone,
+		&e.Role,
+		&e.DailyWage,
+		&e.OvertimeRate,
+		&e.IsActive,
+		&e.CreatedAt,
+		&e.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("Employee")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &e, nil
+}
+
+func (s *EmployeeStore) Update(ctx context.Context, e *models.Employee) error {
+	e.Name = utils.CleanName(e.Name)
+
+	query := `
+		UPDATE employees
+		SET name = $2, phone = $3, role = $4, daily_wage = $5, overtime_rate = $6, is_active = $7, updated_at = NOW()
+		WHERE id = $1
+		RETURNING created_at, updated_at
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	err := s.db.QueryRowContext(
+		ctx,
+		query,
+		e.ID,
+		e.Name,
+		e.Phone,
+		e.Role,
+		e.DailyWage,
+		e.OvertimeRate,
+		e.IsActive,
+	).Scan(
+		&e.CreatedAt,
+		&e.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return utils.NewNotFoundError("Employee")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *EmployeeStore) Delete(ctx context.Context, eID string) error {
+	query := `
+		DELETE FROM employees
+		WHERE id = $1;
+	`
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, eID)
+	if isForeignKeyViolation(err) {
+		return utils.NewConflictError("Employee has production or attendance records; mark them inactive instead")
+	}
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return utils.NewNotFoundError("Employee")
+	}
+	return nil
+}
+
+// Replace the crew of a production with p.Crew, filling in the names. Workers
+// who left can stay on the batches they already worked on.
+func replaceProductionCrew(ctx context.Context, q querier, p *models.Production) error {
+	for i := range p.Crew {
+		member := &p.Crew[i]
+
+		var active bool
+		err := q.QueryRowContext(ctx, `
+			SELECT name, is_active OR EXISTS (
+				SELECT 1 FROM production_crew WHERE production_id = $2 AND employee_id = employees.id
+			)
+			FROM employees
+			WHERE id = $1
+		`, member.EmployeeID, p.ID).Scan(&member.EmployeeName, &active)
+		if err == sql.ErrNoRows {
+			return utils.NewBadRequestError("Employee does not exist")
+		}
+		if err != nil {
+			return err
+		}
+
+		if !active {
+			return utils.NewBadRequestError(member.EmployeeName + " is no longer active")
+		}
+	}
+
+	if _, err := q.ExecContext(ctx, `DELETE FROM production_crew WHERE production_id = $1`, p.ID); err != nil {
+		return err
+	}
+
+	for _, member := range p.Crew {
+		_, err := q.ExecContext(ctx, `
+			INSERT INTO production_crew (production_id, employee_id)
+			VALUES ($1, $2)
+		`, p.ID, member.EmployeeID)
+		if isUniqueViolation(err) {
+			return utils.NewBadRequestError("Each employee can only appear once in a crew")
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func loadProductionCrew(ctx context.Context, q querier, p *models.Production) error {
+	rows, err := q.QueryContext(ctx, `
+		SELECT c.employee_id, e.name
+		FROM production_crew c
+		JOIN employees e ON e.id = c.employee_id
+		WHERE c.production_id = $1
+		ORDER BY e.name ASC
+	`, p.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	p.Crew = []models.ProductionCrew{}
+	for rows.Next() {
+		var member models.ProductionCrew
+		if err := rows.Scan(&member.EmployeeID, &member.EmployeeName); err != nil {
+			return err
+		}
+		p.Crew = append(p.Crew, member)
+	}
+
+	return rows.Err()
+}
\ No newline at end of file
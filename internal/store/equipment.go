@@ -0,0 +1,659 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kevinbrivio/batako-backend/internal/models"
+	"github.com/kevinbrivio/batako-backend/internal/utils"
+)
+
+type EquipmentStore struct {
+	db *sql.DB
+}
+
+// Blocks and press cycles from the productions each machine was used for, and
+// the blocks of the last 30 days to estimate when block-based maintenance is due
+const equipmentUsage = `
+	LEFT JOIN (
+		SELECT
+			pe.equipment_id,
+			SUM(p.quantity) as blocks,
+			SUM(CEIL(p.quantity::float8 / eq.blocks_per_cycle)) as cycles,
+			COALESCE(SUM(p.quantity) FILTER (WHERE p.production_date > CURRENT_DATE - 30), 0) as recent_blocks
+		FROM production_equipment pe
+		JOIN productions p ON p.id = pe.production_id
+		JOIN equipment eq ON eq.id = pe.equipment_id
+		GROUP BY pe.equipment_id
+	) u ON u.equipment_id = e.id
+`
+
+const equipmentColumns = `
+	e.id,
+	e.name,
+	e.type,
+	e.blocks_per_cycle,
+	e.initial_blocks,
+	e.initial_blocks + COALESCE(u.blocks, 0) as blocks,
+	(e.initial_blocks / e.blocks_per_cycle + COALESCE(u.cycles, 0))::int as cycles,
+	e.commissioned_date,
+	e.is_active,
+	e.note,
+	e.created_at,
+	e.updated_at
+`
+
+func (s *EquipmentStore) Create(ctx context.Context, e *models.Equipment) error {
+	e.ID = uuid.New().String()
+	e.Name = utils.CleanName(e.Name)
+
+	query := `
+		INSERT INTO equipment (id, name, type, blocks_per_cycle, initial_blocks, commissioned_date, is_active, note)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING created_at, updated_at
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	err := s.db.QueryRowContext(
+		ctx,
+		query,
+		e.ID,
+		e.Name,
+		e.Type,
+		e.BlocksPerCycle,
+		e.InitialBlocks,
+		e.CommissionedDate,
+		e.IsActive,
+		e.Note,
+	).Scan(
+		&e.CreatedAt,
+		&e.UpdatedAt,
+	)
+
+	if isUniqueViolation(err) {
+		return utils.NewConflictError("Equipment already exists")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	e.Blocks = e.InitialBlocks
+	e.Cycles = e.InitialBlocks / e.BlocksPerCycle
+	return nil
+}
+
+func (s *EquipmentStore) GetAll(ctx context.Context, limit, offset int) ([]models.Equipment, int, error) {
+	query := `
+		SELECT ` + equipmentColumns + `, COUNT(*) OVER() as total_count
+		FROM equipment e
+		` + equipmentUsage + `
+		ORDER BY e.type ASC, e.name ASC
+		LIMIT $1 OFFSET $2
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	equipment := []models.Equipment{}
+	var totalCount int
+
+	for rows.Next() {
+		var e models.Equipment
+		if err := rows.Scan(
+			&e.ID,
+			&e.Name,
+			&e.Type,
+			&e.BlocksPerCycle,
+			&e.InitialBlocks,
+			&e.Blocks,
+			&e.Cycles,
+			&e.CommissionedDate,
+			&e.IsActive,
+			&e.Note,
+			&e.CreatedAt,
+			&e.UpdatedAt,
+			&totalCount,
+		); err != nil {
+			return equipment, 0, err
+		}
+		equipment = append(equipment, e)
+	}
+	if err = rows.Err(); err != nil {
+		return equipment, 0, err
+	}
+
+	return equipment, totalCount, nil
+}
+
+func (s *EquipmentStore) GetByID(ctx context.Context, eID string) (*models.Equipment, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	var e models.Equipment
+
+	err := s.db.QueryRowContext(ctx, `
+		SELECT ` + equipmentColumns + `
+		FROM equipment e
+		` + equipmentUsage + `
+		WHERE e.id = $1
+	`, eID).Scan(
+		&e.ID,
+		&e.Name,
+		&e.Type,
+		&e.BlocksPerCycle,
+		&e.InitialBlocks,
+		&e.Blocks,
+		&e.Cycles,
+		&e.CommissionedDate,
+		&e.IsActive,
+		&e.Note,
+		&e.CreatedAt,
+		&e.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("Equipment")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	e.Schedules, err = getMaintenanceSchedules(ctx, s.db, eID, time.Now(), 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return &e, nil
+}
+
+func (s *EquipmentStore) Update(ctx context.Context, e *models.Equipment) error {
+	e.Name = utils.CleanName(e.Name)
+
+	query := `
+		UPDATE equipment
+		SET name = $2, type = $3, blocks_per_cycle = $4, initial_blocks = $5, commissioned_date = $6, is_active = $7, note = $8, updated_at = NOW()
+		WHERE id = $1
+		RETURNING created_at, updated_at
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	err := s.db.QueryRowContext(
+		ctx,
+		query,
+		e.ID,
+		e.Name,
+		e.Type,
+		e.BlocksPerCycle,
+		e.InitialBlocks,
+		e.CommissionedDate,
+		e.IsActive,
+		e.Note,
+	).Scan(
+		&e.CreatedAt,
+		&e.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return utils.NewNotFoundError("Equipment")
+	}
+
+	if isUniqueViolation(err) {
+		return utils.NewConflictError("Another machine already has this name")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *EquipmentStore) Delete(ctx context.Context, eID string) error {
+	query := `
+		DELETE FROM equipment
+		WHERE id = $1;
+	`
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, eID)
+	if isForeignKeyViolation(err) {
+		return utils.NewConflictError("Equipment was used in productions; mark it inactive instead")
+	}
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return utils.NewNotFoundError("Equipment")
+	}
+	return nil
+}
+
+// Add a recurring task. The block counter at the last done date is taken from
+// the productions up to that day.
+func (s *EquipmentStore) CreateSchedule(ctx context.Context, ms *models.MaintenanceSchedule) error {
+	ms.ID = uuid.New().String()
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	var err error
+	ms.LastDoneBlocks, err = equipmentBlocksAt(ctx, s.db, ms.EquipmentID, ms.LastDoneDate)
+	if err != nil {
+		return err
+	}
+
+	err = s.db.QueryRowContext(ctx, `
+		INSERT INTO maintenance_schedules (id, equipment_id, task, interval_blocks, interval_days, last_done_date, last_done_blocks)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING created_at, updated_at
+	`, ms.ID, ms.EquipmentID, ms.Task, ms.IntervalBlocks, ms.IntervalDays, ms.LastDoneDate, ms.LastDoneBlocks).Scan(&ms.CreatedAt, &ms.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	return s.refreshSchedule(ctx, ms)
+}
+
+func (s *EquipmentStore) UpdateSchedule(ctx context.Context, ms *models.MaintenanceSchedule) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	var err error
+	ms.LastDoneBlocks, err = equipmentBlocksAt(ctx, s.db, ms.EquipmentID, ms.LastDoneDate)
+	if err != nil {
+		return err
+	}
+
+	err = s.db.QueryRowContext(ctx, `
+		UPDATE maintenance_schedules
+		SET task = $3, interval_blocks = $4, interval_days = $5, last_done_date = $6, last_done_blocks = $7, updated_at = NOW()
+		WHERE id = $1 AND equipment_id = $2
+		RETURNING created_at, updated_at
+	`, ms.ID, ms.EquipmentID, ms.Task, ms.IntervalBlocks, ms.IntervalDays, ms.LastDoneDate, ms.LastDoneBlocks).Scan(&ms.CreatedAt, &ms.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return utils.NewNotFoundError("Maintenance schedule")
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.refreshSchedule(ctx, ms)
+}
+
+func (s *EquipmentStore) DeleteSchedule(ctx context.Context, eID, sID string) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, `
+		DELETE FROM maintenance_schedules WHERE id = $1 AND equipment_id = $2
+	`, sID, eID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return utils.NewNotFoundError("Maintenance schedule")
+	}
+	return nil
+}
+
+// Record maintenance that was done. When it was for a schedule, the schedule
+// starts counting again from this date.
+func (s *EquipmentStore) LogMaintenance(ctx context.Context, l *models.MaintenanceLog) error {
+	l.ID = uuid.New().String()
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		var err error
+		l.BlocksAt, err = equipmentBlocksAt(ctx, tx, l.EquipmentID, l.PerformedDate)
+		if err != nil {
+			return err
+		}
+
+		if l.ScheduleID != "" {
+			var task string
+			var lastDone time.Time
+			err := tx.QueryRowContext(ctx, `
+				SELECT task, last_done_date FROM maintenance_schedules
+				WHERE id = $1 AND equipment_id = $2
+				FOR UPDATE
+			`, l.ScheduleID, l.EquipmentID).Scan(&task, &lastDone)
+			if err == sql.ErrNoRows {
+				return utils.NewBadRequestError("Maintenance schedule does not belong to this equipment")
+			}
+			if err != nil {
+				return err
+			}
+
+			if l.Task == "" {
+				l.Task = task
+			}
+
+			// Logging an older job doesn't move the schedule back
+			if !l.PerformedDate.Before(lastDone) {
+				_, err = tx.ExecContext(ctx, `
+					UPDATE maintenance_schedules
+					SET last_done_date = $2, last_done_blocks = $3, updated_at = NOW()
+					WHERE id = $1
+				`, l.ScheduleID, l.PerformedDate, l.BlocksAt)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		if l.Task == "" {
+			return utils.NewBadRequestError("Task cannot be empty")
+		}
+
+		return tx.QueryRowContext(ctx, `
+			INSERT INTO maintenance_logs (id, equipment_id, schedule_id, task, performed_date, blocks_at, cost, note)
+			VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $7, $8)
+			RETURNING created_at
+		`, l.ID, l.EquipmentID, l.ScheduleID, l.Task, l.PerformedDate, l.BlocksAt, l.Cost, l.Note).Scan(&l.CreatedAt)
+	})
+}
+
+func (s *EquipmentStore) GetMaintenanceLogs(ctx context.Context, eID string, limit, offset int) ([]models.MaintenanceLog, int, error) {
+	query := `
+		SELECT id, equipment_id, COALESCE(schedule_id, ''), task, performed_date, blocks_at, cost, note, created_at, COUNT(*) OVER() as total_count
+		FROM maintenance_logs
+		WHERE equipment_id = $1
+		ORDER BY performed_date DESC, created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, eID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	logs := []models.MaintenanceLog{}
+	var totalCount int
+
+	for rows.Next() {
+		var l models.MaintenanceLog
+		if err := rows.Scan(
+			&l.ID,
+			&l.EquipmentID,
+			&l.ScheduleID,
+			&l.Task,
+			&l.PerformedDate,
+			&l.BlocksAt,
+			&l.Cost,
+			&l.Note,
+			&l.CreatedAt,
+			&totalCount,
+		); err != nil {
+			return logs, 0, err
+		}
+		logs = append(logs, l)
+	}
+	if err = rows.Err(); err != nil {
+		return logs, 0, err
+	}
+
+	return logs, totalCount, nil
+}
+
+// Maintenance of active equipment that is overdue or due within days of today,
+// overdue first
+func (s *EquipmentStore) GetMaintenanceDue(ctx context.Context, today time.Time, days int) ([]models.MaintenanceSchedule, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	schedules, err := getMaintenanceSchedules(ctx, s.db, "", today, days)
+	if err != nil {
+		return nil, err
+	}
+
+	due := []models.MaintenanceSchedule{}
+	for _, ms := range schedules {
+		if ms.Status == models.MaintenanceOverdue {
+			due = append(due, ms)
+		}
+	}
+	for _, ms := range schedules {
+		if ms.Status == models.MaintenanceDueSoon {
+			due = append(due, ms)
+		}
+	}
+
+	return due, nil
+}
+
+func (s *EquipmentStore) refreshSchedule(ctx context.Context, ms *models.MaintenanceSchedule) error {
+	schedules, err := getMaintenanceSchedules(ctx, s.db, ms.EquipmentID, time.Now(), 0)
+	if err != nil {
+		return err
+	}
+
+	for _, current := range schedules {
+		if current.ID == ms.ID {
+			*ms = current
+		}
+	}
+	return nil
+}
+
+// Schedules of one machine, or of all active ones when eID is empty, with how
+// far each is from being due
+func getMaintenanceSchedules(ctx context.Context, q querier, eID string, today time.Time, days int) ([]models.MaintenanceSchedule, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT
+			s.id,
+			s.equipment_id,
+			e.name,
+			s.task,
+			s.interval_blocks,
+			s.interval_days,
+			s.last_done_date,
+			s.last_done_blocks,
+			e.initial_blocks + COALESCE(u.blocks, 0),
+			COALESCE(u.recent_blocks, 0),
+			s.created_at,
+			s.updated_at
+		FROM maintenance_schedules s
+		JOIN equipment e ON e.id = s.equipment_id
+		` + equipmentUsage + `
+		WHERE ($1 = '' AND e.is_active) OR s.equipment_id = $1
+		ORDER BY e.name ASC, s.task ASC
+	`, eID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	today, _ = utils.GetDayRange(today)
+
+	schedules := []models.MaintenanceSchedule{}
+	for rows.Next() {
+		var ms models.MaintenanceSchedule
+		var blocks, recent int
+		if err := rows.Scan(
+			&ms.ID,
+			&ms.EquipmentID,
+			&ms.Equipment,
+			&ms.Task,
+			&ms.IntervalBlocks,
+			&ms.IntervalDays,
+			&ms.LastDoneDate,
+			&ms.LastDoneBlocks,
+			&blocks,
+			&recent,
+			&ms.CreatedAt,
+			&ms.UpdatedAt,
+		); err != nil {
+			return schedules, err
+		}
+
+		setMaintenanceStatus(&ms, blocks, float64(recent) / 30, today, days)
+		schedules = append(schedules, ms)
+	}
+
+	return schedules, rows.Err()
+}
+
+// Overdue once either interval has passed; due soon when the date or the
+// estimate from recent production falls within days, or less than a tenth of
+// the block interval is left
+func setMaintenanceStatus(ms *models.MaintenanceSchedule, blocks int, dailyBlocks float64, today time.Time, days int) {
+	ms.Status = models.MaintenanceOK
+	horizon := today.AddDate(0, 0, days)
+
+	if ms.IntervalBlocks > 0 {
+		remaining := ms.LastDoneBlocks + ms.IntervalBlocks - blocks
+		ms.RemainingBlocks = &remaining
+
+		if remaining <= 0 {
+			ms.Status = models.MaintenanceOverdue
+		} else {
+			if remaining * 10 <= ms.IntervalBlocks {
+				ms.Status = models.MaintenanceDueSoon
+			}
+
+			if dailyBlocks > 0 {
+				estimate := today.AddDate(0, 0, int(math.Ceil(float64(remaining) / dailyBlocks)))
+				ms.EstimatedDueDate = &estimate
+				if !estimate.After(horizon) {
+					ms.Status = models.MaintenanceDueSoon
+				}
+			}
+		}
+	}
+
+	if ms.IntervalDays > 0 {
+		last := time.Date(ms.LastDoneDate.Year(), ms.LastDoneDate.Month(), ms.LastDoneDate.Day(), 0, 0, 0, 0, today.Location())
+		next := last.AddDate(0, 0, ms.IntervalDays)
+		ms.NextDueDate = &next
+
+		if !next.After(today) {
+			ms.Status = models.MaintenanceOverdue
+		} else if !next.After(horizon) && ms.Status != models.MaintenanceOverdue {
+			ms.Status = models.MaintenanceDueSoon
+		}
+	}
+}
+
+// Blocks a machine had made by the end of date
+func equipmentBlocksAt(ctx context.Context, q querier, eID string, date time.Time) (int, error) {
+	var blocks int
+	err := q.QueryRowContext(ctx, `
+		SELECT e.initial_blocks + COALESCE((
+			SELECT SUM(p.quantity)
+			FROM production_equipment pe
+			JOIN productions p ON p.id = pe.production_id
+			WHERE pe.equipment_id = e.id AND p.production_date <= $2::date
+		), 0)
+		FROM equipment e
+		WHERE e.id = $1
+	`, eID, date).Scan(&blocks)
+	if err == sql.ErrNoRows {
+		return 0, utils.NewNotFoundError("Equipment")
+	}
+
+	return blocks, err
+}
+
+// Replace the equipment a production was made with, filling in names. Retired
+// machines can stay on the batches they were already used for.
+func replaceProductionEquipment(ctx context.Context, q querier, p *models.Production) error {
+	for i := range p.Equipment {
+		item := &p.Equipment[i]
+
+		var active bool
+		err := q.QueryRowContext(ctx, `
+			SELECT name, type, is_active OR EXISTS (
+				SELECT 1 FROM production_equipment WHERE production_id = $2 AND equipment_id = equipment.id
+			)
+			FROM equipment
+			WHERE id = $1
+		`, item.EquipmentID, p.ID).Scan(&item.Name, &item.Type, &active)
+		if err == sql.ErrNoRows {
+			return utils.NewBadRequestError("Equipment does not exist")
+		}
+		if err != nil {
+			return err
+		}
+
+		if !active {
+			return utils.NewBadRequestError(item.Name + " is no longer in use")
+		}
+	}
+
+	if _, err := q.ExecContext(ctx, `DELETE FROM production_equipment WHERE production_id = $1`, p.ID); err != nil {
+		return err
+	}
+
+	for _, item := range p.Equipment {
+		_, err := q.ExecContext(ctx, `
+			INSERT INTO production_equipment (production_id, equipment_id)
+			VALUES ($1, $2)
+		`, p.ID, item.EquipmentID)
+		if isUniqueViolation(err) {
+			return utils.NewBadRequestError("Each machine can only appear once on a production")
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func loadProductionEquipment(ctx context.Context, q querier, p *models.Production) error {
+	rows, err := q.QueryContext(ctx, `
+		SELECT pe.equipment_id, e.name, e.type
+		FROM production_equipment pe
+		JOIN equipment e ON e.id = pe.equipment_id
+		WHERE pe.production_id = $1
+		ORDER BY e.type ASC, e.name ASC
+	`, p.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	p.Equipment = []models.ProductionEquipment{}
+	for rows.Next() {
+		var item models.ProductionEquipment
+		if err := rows.Scan(&item.EquipmentID, &item.Name, &item.Type); err != nil {
+			return err
+		}
+		p.Equipment = append(p.Equipment, item)
+	}
+
+	return rows.Err()
+}
\ No newline at end of file
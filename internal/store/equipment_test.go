@@ -0,0 +1,65 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kevinbrivio/batako-backend/internal/models"
+)
+
+func TestSetMaintenanceStatus(t *testing.T) {
+	today := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	date := func(month time.Month, day int) time.Time {
+		return time.Date(2026, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name        string
+		schedule    models.MaintenanceSchedule
+		blocks      int
+		dailyBlocks float64
+		want        string
+	}{
+		{"blocks left", models.MaintenanceSchedule{IntervalBlocks: 10000}, 2000, 0, models.MaintenanceOK},
+		{"last tenth of blocks", models.MaintenanceSchedule{IntervalBlocks: 10000}, 9100, 0, models.MaintenanceDueSoon},
+		{"blocks used up", models.MaintenanceSchedule{IntervalBlocks: 10000}, 10000, 0, models.MaintenanceOverdue},
+		{"blocks counted from last service", models.MaintenanceSchedule{IntervalBlocks: 10000, LastDoneBlocks: 9000}, 12000, 0, models.MaintenanceOK},
+		{"estimate within days", models.MaintenanceSchedule{IntervalBlocks: 10000}, 5000, 1000, models.MaintenanceDueSoon},
+		{"estimate beyond days", models.MaintenanceSchedule{IntervalBlocks: 10000}, 5000, 100, models.MaintenanceOK},
+		{"date passed", models.MaintenanceSchedule{IntervalDays: 30, LastDoneDate: date(2, 1)}, 0, 0, models.MaintenanceOverdue},
+		{"date within days", models.MaintenanceSchedule{IntervalDays: 30, LastDoneDate: date(2, 13)}, 0, 0, models.MaintenanceDueSoon},
+		{"date beyond days", models.MaintenanceSchedule{IntervalDays: 30, LastDoneDate: date(3, 2)}, 0, 0, models.MaintenanceOK},
+		{"overdue blocks win over due date", models.MaintenanceSchedule{IntervalBlocks: 10000, IntervalDays: 30, LastDoneDate: date(2, 13)}, 10500, 0, models.MaintenanceOverdue},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms := tt.schedule
+			setMaintenanceStatus(&ms, tt.blocks, tt.dailyBlocks, today, 7)
+			if ms.Status != tt.want {
+				t.Errorf("status = %q, want %q", ms.Status, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetMaintenanceStatusDates(t *testing.T) {
+	today := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	ms := models.MaintenanceSchedule{
+		IntervalBlocks: 10000,
+		IntervalDays:   30,
+		LastDoneDate:   time.Date(2026, 2, 13, 15, 30, 0, 0, time.UTC),
+	}
+	setMaintenanceStatus(&ms, 5000, 1000, today, 7)
+
+	if ms.RemainingBlocks == nil || *ms.RemainingBlocks != 5000 {
+		t.Errorf("remaining blocks = %v, want 5000", ms.RemainingBlocks)
+	}
+	if want := time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC); ms.EstimatedDueDate == nil || !ms.EstimatedDueDate.Equal(want) {
+		t.Errorf("estimated due date = %v, want %v", ms.EstimatedDueDate, want)
+	}
+	if want := time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC); ms.NextDueDate == nil || !ms.NextDueDate.Equal(want) {
+		t.Errorf("next due date = %v, want %v", ms.NextDueDate, want)
+	}
+}
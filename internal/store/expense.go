@@ -0,0 +1,537 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kevinbrivio/batako-backend/internal/models"
+	"github.com/kevinbrivio/batako-backend/internal/utils"
+)
+
+type ExpenseStore struct {
+	db *sql.DB
+}
+
+const expenseColumns = `
+	x.id,
+	x.category_id,
+	c.name,
+	COALESCE(x.supplier_id, ''),
+	COALESCE(s.name, ''),
+	x.expense_date,
+	x.amount,
+	x.description,
+	x.unpaid,
+	x.receipt_file_name,
+	x.receipt_content_type,
+	x.receipt_key,
+	x.created_at,
+	x.updated_at
+`
+
+const expenseJoins = `
+	JOIN expense_categories c ON c.id = x.category_id
+	LEFT JOIN suppliers s ON s.id = x.supplier_id
+`
+
+func (s *ExpenseStore) Create(ctx context.Context, x *models.Expense) error {
+	x.ID = uuid.New().String()
+
+	query := `
+		INSERT INTO expenses (id, category_id, supplier_id, expense_date, amount, description, unpaid)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $7)
+		RETURNING created_at, updated_at
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		if err := resolveExpenseLinks(ctx, tx, x); err != nil {
+			return err
+		}
+
+		err := tx.QueryRowContext(
+			ctx,
+			query,
+			x.ID,
+			x.CategoryID,
+			x.SupplierID,
+			x.ExpenseDate,
+			x.Amount,
+			x.Description,
+			x.Unpaid,
+		).Scan(
+			&x.CreatedAt,
+			&x.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+
+		if err := syncExpenseCash(ctx, tx, x); err != nil {
+			return err
+		}
+
+		return postExpenseJournal(ctx, tx, x)
+	})
+}
+
+// Expenses newest first, optionally of a single category
+func (s *ExpenseStore) GetAll(ctx context.Context, categoryID string, limit, offset int) ([]models.Expense, int, error) {
+	query := `
+		SELECT ` + expenseColumns + `, COUNT(*) OVER() as total_count
+		FROM expenses x
+		` + expenseJoins + `
+		WHERE $1 = '' OR x.category_id = $1
+		ORDER BY x.expense_date DESC, x.created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, categoryID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	expenses := []models.Expense{}
+	var totalCount int
+
+	for rows.Next() {
+		var x models.Expense
+		if err := rows.Scan(append(expenseFields(&x), &totalCount)...); err != nil {
+			return expenses, 0, err
+		}
+		x.HasReceipt = x.ReceiptKey != ""
+		expenses = append(expenses, x)
+	}
+	if err = rows.Err(); err != nil {
+		return expenses, 0, err
+	}
+
+	return expenses, totalCount, nil
+}
+
+func (s *ExpenseStore) GetAllDaily(ctx context.Context, date time.Time) ([]models.Expense, models.ExpenseSummary, error) {
+	start, end := utils.GetDayRange(date)
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	return getExpensesBetween(ctx, s.db, start, end)
+}
+
+func (s *ExpenseStore) GetAllWeekly(ctx context.Context, weekOffset int) ([]models.Expense, models.ExpenseSummary, error) {
+	start, end := utils.GetWeekRange(time.Now(), weekOffset)
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	return getExpensesBetween(ctx, s.db, start, end)
+}
+
+func (s *ExpenseStore) GetAllMonthly(ctx context.Context, monthOffset int) ([]models.Expense, models.ExpenseSummary, error) {
+	start, end := utils.GetMonthRange(time.Now(), monthOffset)
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	return getExpensesBetween(ctx, s.db, start, end)
+}
+
+func (s *ExpenseStore) GetByID(ctx context.Context, xID string) (*models.Expense, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	return getExpense(ctx, s.db, xID)
+}
+
+func (s *ExpenseStore) Update(ctx context.Context, x *models.Expense) error {
+	query := `
+		UPDATE expenses
+		SET category_id = $2, supplier_id = NULLIF($3, ''), expense_date = $4, amount = $5, description = $6, unpaid = $7, updated_at = NOW()
+		WHERE id = $1
+		RETURNING receipt_file_name, receipt_content_type, receipt_key, created_at, updated_at
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		if err := resolveExpenseLinks(ctx, tx, x); err != nil {
+			return err
+		}
+
+		err := tx.QueryRowContext(
+			ctx,
+			query,
+			x.ID,
+			x.CategoryID,
+			x.SupplierID,
+			x.ExpenseDate,
+			x.Amount,
+			x.Description,
+			x.Unpaid,
+		).Scan(
+			&x.ReceiptFileName,
+			&x.ReceiptContentType,
+			&x.ReceiptKey,
+			&x.CreatedAt,
+			&x.UpdatedAt,
+		)
+
+		if err == sql.ErrNoRows {
+			return utils.NewNotFoundError("Expense")
+		}
+
+		if err != nil {
+			return err
+		}
+
+		x.HasReceipt = x.ReceiptKey != ""
+		if err := syncExpenseCash(ctx, tx, x); err != nil {
+			return err
+		}
+
+		return postExpenseJournal(ctx, tx, x)
+	})
+}
+
+// Remove an expense and hand back what it was so its receipt can be cleaned up
+func (s *ExpenseStore) Delete(ctx context.Context, xID string) (*models.Expense, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	var x *models.Expense
+	err := withTx(ctx, s.db, func(tx *sql.Tx) error {
+		var err error
+		x, err = getExpense(ctx, tx, xID)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM expenses WHERE id = $1`, xID); err != nil {
+			return err
+		}
+
+		if err := syncCashMovement(ctx, tx, &models.CashMovement{SourceType: models.CashSourceExpense, SourceID: xID}); err != nil {
+			return err
+		}
+
+		return reverseJournalEntries(ctx, tx, models.JournalSourceExpense, xID, "Expense deleted")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return x, nil
+}
+
+// Point the expense at a newly stored receipt, returning the key of the one it
+// replaces, if any
+func (s *ExpenseStore) SetReceipt(ctx context.Context, x *models.Expense) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	var oldKey string
+	err := withTx(ctx, s.db, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx, `
+			SELECT receipt_key FROM expenses WHERE id = $1 FOR UPDATE
+		`, x.ID).Scan(&oldKey)
+		if err == sql.ErrNoRows {
+			return utils.NewNotFoundError("Expense")
+		}
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE expenses
+			SET receipt_file_name = $2, receipt_content_type = $3, receipt_key = $4, updated_at = NOW()
+			WHERE id = $1
+		`, x.ID, x.ReceiptFileName, x.ReceiptContentType, x.ReceiptKey)
+		return err
+	})
+
+	return oldKey, err
+}
+
+// Detach the receipt, returning its key so the file can be removed
+func (s *ExpenseStore) ClearReceipt(ctx context.Context, xID string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	x, err := getExpense(ctx, s.db, xID)
+	if err != nil {
+		return "", err
+	}
+
+	if !x.HasReceipt {
+		return "", utils.NewNotFoundError("Receipt")
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		UPDATE expenses
+		SET receipt_file_name = '', receipt_content_type = '', receipt_key = '', updated_at = NOW()
+		WHERE id = $1 AND receipt_key = $2
+	`, xID, x.ReceiptKey)
+	if err != nil {
+		return "", err
+	}
+
+	return x.ReceiptKey, nil
+}
+
+func (s *ExpenseStore) CreateCategory(ctx context.Context, c *models.ExpenseCategory) error {
+	c.ID = uuid.New().String()
+	c.Name = utils.CleanName(c.Name)
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	if err := resolveCategoryAccount(ctx, s.db, c); err != nil {
+		return err
+	}
+
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO expense_categories (id, name, account_id)
+		VALUES ($1, $2, NULLIF($3, ''))
+		RETURNING created_at, updated_at
+	`, c.ID, c.Name, c.AccountID).Scan(&c.CreatedAt, &c.UpdatedAt)
+
+	if isUniqueViolation(err) {
+		return utils.NewConflictError("Expense category already exists")
+	}
+
+	return err
+}
+
+func (s *ExpenseStore) GetCategories(ctx context.Context) ([]models.ExpenseCategory, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT c.id, c.name, COALESCE(c.account_id, ''), COALESCE(a.name, ''), c.created_at, c.updated_at
+		FROM expense_categories c
+		LEFT JOIN accounts a ON a.id = c.account_id
+		ORDER BY c.name ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := []models.ExpenseCategory{}
+	for rows.Next() {
+		var c models.ExpenseCategory
+		if err := rows.Scan(&c.ID, &c.Name, &c.AccountID, &c.AccountName, &c.CreatedAt, &c.UpdatedAt); err != nil {
+			return categories, err
+		}
+		categories = append(categories, c)
+	}
+
+	return categories, rows.Err()
+}
+
+func (s *ExpenseStore) UpdateCategory(ctx context.Context, c *models.ExpenseCategory) error {
+	c.Name = utils.CleanName(c.Name)
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	if err := resolveCategoryAccount(ctx, s.db, c); err != nil {
+		return err
+	}
+
+	err := s.db.QueryRowContext(ctx, `
+		UPDATE expense_categories
+		SET name = $2, account_id = NULLIF($3, ''), updated_at = NOW()
+		WHERE id = $1
+		RETURNING created_at, updated_at
+	`, c.ID, c.Name, c.AccountID).Scan(&c.CreatedAt, &c.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return utils.NewNotFoundError("Expense category")
+	}
+
+	if isUniqueViolation(err) {
+		return utils.NewConflictError("Another expense category already has this name")
+	}
+
+	return err
+}
+
+func (s *ExpenseStore) DeleteCategory(ctx context.Context, cID string) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, `DELETE FROM expense_categories WHERE id = $1`, cID)
+	if isForeignKeyViolation(err) {
+		return utils.NewConflictError("Expense category is in use and cannot be deleted")
+	}
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return utils.NewNotFoundError("Expense category")
+	}
+	return nil
+}
+
+// Expenses between start and end with totals per category
+func getExpensesBetween(ctx context.Context, q querier, start, end time.Time) ([]models.Expense, models.ExpenseSummary, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT ` + expenseColumns + `
+		FROM expenses x
+		` + expenseJoins + `
+		WHERE x.expense_date BETWEEN $1 AND $2
+		ORDER BY x.expense_date DESC, x.created_at DESC
+	`, start, end)
+	if err != nil {
+		return nil, models.ExpenseSummary{}, err
+	}
+	defer rows.Close()
+
+	expenses := []models.Expense{}
+	summary := models.ExpenseSummary{Categories: []models.ExpenseCategorySummary{}}
+	index := map[string]int{}
+
+	for rows.Next() {
+		var x models.Expense
+		if err := rows.Scan(expenseFields(&x)...); err != nil {
+			return expenses, models.ExpenseSummary{}, err
+		}
+		x.HasReceipt = x.ReceiptKey != ""
+		expenses = append(expenses, x)
+
+		summary.TotalCount++
+		summary.TotalAmount += x.Amount
+
+		i, ok := index[x.CategoryID]
+		if !ok {
+			i = len(summary.Categories)
+			index[x.CategoryID] = i
+			summary.Categories = append(summary.Categories, models.ExpenseCategorySummary{CategoryID: x.CategoryID, Category: x.Category})
+		}
+		summary.Categories[i].Count++
+		summary.Categories[i].Amount += x.Amount
+	}
+	if err = rows.Err(); err != nil {
+		return expenses, models.ExpenseSummary{}, err
+	}
+
+	return expenses, summary, nil
+}
+
+func getExpense(ctx context.Context, q querier, xID string) (*models.Expense, error) {
+	var x models.Expense
+
+	err := q.QueryRowContext(ctx, `
+		SELECT ` + expenseColumns + `
+		FROM expenses x
+		` + expenseJoins + `
+		WHERE x.id = $1
+	`, xID).Scan(expenseFields(&x)...)
+
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("Expense")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	x.HasReceipt = x.ReceiptKey != ""
+	return &x, nil
+}
+
+// Scan targets in the order of expenseColumns
+func expenseFields(x *models.Expense) []any {
+	return []any{
+		&x.ID,
+		&x.CategoryID,
+		&x.Category,
+		&x.SupplierID,
+		&x.Supplier,
+		&x.ExpenseDate,
+		&x.Amount,
+		&x.Description,
+		&x.Unpaid,
+		&x.ReceiptFileName,
+		&x.ReceiptContentType,
+		&x.ReceiptKey,
+		&x.CreatedAt,
+		&x.UpdatedAt,
+	}
+}
+
+// Check the category and supplier exist and fill in their names
+func resolveExpenseLinks(ctx context.Context, q querier, x *models.Expense) error {
+	err := q.QueryRowContext(ctx, `SELECT name FROM expense_categories WHERE id = $1`, x.CategoryID).Scan(&x.Category)
+	if err == sql.ErrNoRows {
+		return utils.NewBadRequestError("Expense category does not exist")
+	}
+	if err != nil {
+		return err
+	}
+
+	x.Supplier = ""
+	if x.SupplierID == "" {
+		return nil
+	}
+
+	err = q.QueryRowContext(ctx, `SELECT name FROM suppliers WHERE id = $1`, x.SupplierID).Scan(&x.Supplier)
+	if err == sql.ErrNoRows {
+		return utils.NewBadRequestError("Supplier does not exist")
+	}
+	return err
+}
+
+// Expenses are paid out of the drawer, unpaid ones once they are settled
+func syncExpenseCash(ctx context.Context, q querier, x *models.Expense) error {
+	amount := -x.Amount
+	if x.Unpaid {
+		amount = 0
+	}
+
+	return syncCashMovement(ctx, q, &models.CashMovement{
+		MovementDate: x.ExpenseDate,
+		SourceType: models.CashSourceExpense,
+		SourceID: x.ID,
+		Amount: amount,
+		Description: x.Category + " expense",
+	})
+}
+
+// Check the category's ledger account, when it has one, is an expense account
+func resolveCategoryAccount(ctx context.Context, q querier, c *models.ExpenseCategory) error {
+	c.AccountName = ""
+	if c.AccountID == "" {
+		return nil
+	}
+
+	var accountType string
+	err := q.QueryRowContext(ctx, `SELECT name, type FROM accounts WHERE id = $1`, c.AccountID).Scan(&c.AccountName, &accountType)
+	if err == sql.ErrNoRows {
+		return utils.NewBadRequestError("Account does not exist")
+	}
+	if err != nil {
+		return err
+	}
+
+	if accountType != models.AccountExpense {
+		return utils.NewBadRequestError("Expense categories can only post to expense accounts")
+	}
+	return nil
+}
\ No newline at end of file
@@ -0,0 +1,117 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kevinbrivio/batako-backend/internal/models"
+	"github.com/kevinbrivio/batako-backend/internal/utils"
+)
+
+type InvoiceStore struct {
+	db *sql.DB
+}
+
+// Issue the transaction's invoice with the next number of the current month.
+// Issuing again returns the invoice it already has.
+func (s *InvoiceStore) Issue(ctx context.Context, tID string) (*models.Invoice, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	var inv *models.Invoice
+
+	err := withTx(ctx, s.db, func(tx *sql.Tx) error {
+		// Lock the sale so two requests can't both issue an invoice for it
+		var exists bool
+		err := tx.QueryRowContext(ctx, `SELECT TRUE FROM transactions WHERE id = $1 FOR UPDATE`, tID).Scan(&exists)
+		if err == sql.ErrNoRows {
+			return utils.NewNotFoundError("Transaction")
+		}
+		if err != nil {
+			return err
+		}
+
+		// Already issued (or failed to look it up)
+		inv, err = getInvoiceByTransaction(ctx, tx, tID)
+		if err != sql.ErrNoRows {
+			return err
+		}
+
+		inv = &models.Invoice{
+			ID: uuid.New().String(),
+			TransactionID: tID,
+			IssueDate: time.Now(),
+		}
+
+		inv.InvoiceNumber, err = nextInvoiceNumber(ctx, tx, inv.IssueDate)
+		if err != nil {
+			return err
+		}
+
+		return tx.QueryRowContext(ctx, `
+			INSERT INTO invoices (id, transaction_id, invoice_number, issue_date)
+			VALUES ($1, $2, $3, $4)
+			RETURNING issue_date, created_at
+		`, inv.ID, inv.TransactionID, inv.InvoiceNumber, inv.IssueDate).Scan(&inv.IssueDate, &inv.CreatedAt)
+	})
+
+	return inv, err
+}
+
+func (s *InvoiceStore) GetByTransaction(ctx context.Context, tID string) (*models.Invoice, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	inv, err := getInvoiceByTransaction(ctx, s.db, tID)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("Invoice")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return inv, nil
+}
+
+func getInvoiceByTransaction(ctx context.Context, q querier, tID string) (*models.Invoice, error) {
+	var inv models.Invoice
+
+	err := q.QueryRowContext(ctx, `
+		SELECT id, transaction_id, invoice_number, issue_date, created_at
+		FROM invoices
+		WHERE transaction_id = $1
+	`, tID).Scan(
+		&inv.ID,
+		&inv.TransactionID,
+		&inv.InvoiceNumber,
+		&inv.IssueDate,
+		&inv.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &inv, nil
+}
+
+// Allocate the next number for the month. The upsert holds a row lock on the
+// month's counter until the surrounding transaction ends, and a rollback puts
+// the number back, so numbers never skip or repeat.
+func nextInvoiceNumber(ctx context.Context, q querier, issueDate time.Time) (string, error) {
+	var next int
+	err := q.QueryRowContext(ctx, `
+		INSERT INTO invoice_sequences (period, last_number)
+		VALUES ($1, 1)
+		ON CONFLICT (period) DO UPDATE SET last_number = invoice_sequences.last_number + 1
+		RETURNING last_number
+	`, issueDate.Format("2006-01")).Scan(&next)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("INV/%04d/%02d/%04d", issueDate.Year(), int(issueDate.Month()), next), nil
+}
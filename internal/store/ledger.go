@@ -0,0 +1,857 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kevinbrivio/batako-backend/internal/models"
+	"github.com/kevinbrivio/batako-backend/internal/utils"
+	"github.com/lib/pq"
+)
+
+type LedgerStore struct {
+	db *sql.DB
+}
+
+const accountColumns = `
+	id,
+	code,
+	name,
+	type,
+	is_system,
+	created_at,
+	updated_at
+`
+
+func (s *LedgerStore) CreateAccount(ctx context.Context, a *models.Account) error {
+	a.ID = uuid.New().String()
+	a.Code = strings.TrimSpace(a.Code)
+	a.Name = utils.CleanName(a.Name)
+	a.IsSystem = false
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO accounts (id, code, name, type)
+		VALUES ($1, $2, $3, $4)
+		RETURNING created_at, updated_at
+	`, a.ID, a.Code, a.Name, a.Type).Scan(&a.CreatedAt, &a.UpdatedAt)
+
+	if isUniqueViolation(err) {
+		return utils.NewConflictError("Account code already exists")
+	}
+
+	return err
+}
+
+func (s *LedgerStore) GetAccounts(ctx context.Context) ([]models.Account, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT ` + accountColumns + `
+		FROM accounts
+		ORDER BY code ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	accounts := []models.Account{}
+	for rows.Next() {
+		var a models.Account
+		if err := rows.Scan(accountFields(&a)...); err != nil {
+			return accounts, err
+		}
+		accounts = append(accounts, a)
+	}
+
+	return accounts, rows.Err()
+}
+
+// Renaming is always allowed. The code of a system account is fixed, and the
+// type of an account can't change once something has been posted to it.
+func (s *LedgerStore) UpdateAccount(ctx context.Context, a *models.Account) error {
+	a.Code = strings.TrimSpace(a.Code)
+	a.Name = utils.CleanName(a.Name)
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		current, err := getAccount(ctx, tx, a.ID)
+		if err != nil {
+			return err
+		}
+
+		if current.IsSystem && a.Code != current.Code {
+			return utils.NewBadRequestError("The code of a system account cannot be changed")
+		}
+
+		if a.Type != current.Type {
+			var used bool
+			err := tx.QueryRowContext(ctx, `
+				SELECT EXISTS (SELECT 1 FROM journal_lines WHERE account_id = $1)
+			`, a.ID).Scan(&used)
+			if err != nil {
+				return err
+			}
+			if used {
+				return utils.NewBadRequestError("The type of an account with postings cannot be changed")
+			}
+		}
+
+		err = tx.QueryRowContext(ctx, `
+			UPDATE accounts
+			SET code = $2, name = $3, type = $4, updated_at = NOW()
+			WHERE id = $1
+			RETURNING is_system, created_at, updated_at
+		`, a.ID, a.Code, a.Name, a.Type).Scan(&a.IsSystem, &a.CreatedAt, &a.UpdatedAt)
+
+		if isUniqueViolation(err) {
+			return utils.NewConflictError("Another account already has this code")
+		}
+
+		return err
+	})
+}
+
+func (s *LedgerStore) DeleteAccount(ctx context.Context, aID string) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, `DELETE FROM accounts WHERE id = $1 AND NOT is_system`, aID)
+	if isForeignKeyViolation(err) {
+		return utils.NewConflictError("Account has postings or expense categories and cannot be deleted")
+	}
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		if _, err := getAccount(ctx, s.db, aID); err != nil {
+			return err
+		}
+		return utils.NewConflictError("System accounts cannot be deleted")
+	}
+	return nil
+}
+
+// Post a manual entry, such as opening balances or owner's capital
+func (s *LedgerStore) CreateEntry(ctx context.Context, e *models.JournalEntry) error {
+	e.SourceType = models.JournalSourceManual
+	e.SourceID = ""
+	e.ReversalOf = ""
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		return postJournalEntry(ctx, tx, e)
+	})
+}
+
+// Entries newest first between from and to, optionally of one source type
+func (s *LedgerStore) GetEntries(ctx context.Context, from, to time.Time, sourceType string, limit, offset int) ([]models.JournalEntry, int, error) {
+	query := `
+		SELECT
+			id,
+			entry_date,
+			source_type,
+			source_id,
+			description,
+			COALESCE(reversal_of, ''),
+			COUNT(*) OVER() as total_count,
+			created_at
+		FROM journal_entries
+		WHERE entry_date BETWEEN $1::date AND $2::date AND ($3 = '' OR source_type = $3)
+		ORDER BY entry_date DESC, created_at DESC
+		LIMIT $4 OFFSET $5
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, from, to, sourceType, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	entries := []models.JournalEntry{}
+	var totalCount int
+
+	for rows.Next() {
+		var e models.JournalEntry
+		if err := rows.Scan(
+			&e.ID,
+			&e.EntryDate,
+			&e.SourceType,
+			&e.SourceID,
+			&e.Description,
+			&e.ReversalOf,
+			&totalCount,
+			&e.CreatedAt,
+		); err != nil {
+			return entries, 0, err
+		}
+		entries = append(entries, e)
+	}
+	if err = rows.Err(); err != nil {
+		return entries, 0, err
+	}
+
+	if err := loadJournalLines(ctx, s.db, entries); err != nil {
+		return entries, 0, err
+	}
+
+	return entries, totalCount, nil
+}
+
+func (s *LedgerStore) GetEntryByID(ctx context.Context, eID string) (*models.JournalEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	return getJournalEntry(ctx, s.db, eID)
+}
+
+// Cancel a manual entry. Automatic entries follow their source documents and
+// are corrected by editing or deleting those instead.
+func (s *LedgerStore) ReverseEntry(ctx context.Context, eID string) (*models.JournalEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	var reversal *models.JournalEntry
+	err := withTx(ctx, s.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `SELECT id FROM journal_entries WHERE id = $1 FOR UPDATE`, eID); err != nil {
+			return err
+		}
+
+		e, err := getJournalEntry(ctx, tx, eID)
+		if err != nil {
+			return err
+		}
+
+		if e.SourceType != models.JournalSourceManual {
+			return utils.NewBadRequestError(fmt.Sprintf("Entry was posted automatically from a %s and cannot be reversed by hand", e.SourceType))
+		}
+		if e.ReversalOf != "" {
+			return utils.NewBadRequestError("A reversal cannot itself be reversed")
+		}
+
+		reversal, err = postJournalReversal(ctx, tx, e, "Reversal of " + e.Description)
+		return err
+	})
+
+	return reversal, err
+}
+
+func (s *LedgerStore) GetTrialBalance(ctx context.Context, date time.Time) (*models.TrialBalance, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	balances, err := getAccountBalances(ctx, s.db, date)
+	if err != nil {
+		return nil, err
+	}
+
+	tb := &models.TrialBalance{Date: date, Accounts: []models.TrialBalanceLine{}}
+	for _, b := range balances {
+		if b.debit == 0 && b.credit == 0 {
+			continue
+		}
+
+		line := models.TrialBalanceLine{AccountID: b.ID, Code: b.Code, Name: b.Name, Type: b.Type}
+		if net := b.debit - b.credit; net >= 0 {
+			line.Debit = net
+		} else {
+			line.Credit = -net
+		}
+
+		tb.TotalDebit += line.Debit
+		tb.TotalCredit += line.Credit
+		tb.Accounts = append(tb.Accounts, line)
+	}
+	tb.Balanced = math.Abs(tb.TotalDebit - tb.TotalCredit) < 0.005
+
+	return tb, nil
+}
+
+// Postings to one account between from and to, with the balance brought
+// forward from before from
+func (s *LedgerStore) GetAccountLedger(ctx context.Context, aID string, from, to time.Time) (*models.AccountLedger, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	a, err := getAccount(ctx, s.db, aID)
+	if err != nil {
+		return nil, err
+	}
+
+	sign := 1.0
+	if !debitNormal(a.Type) {
+		sign = -1
+	}
+
+	ledger := &models.AccountLedger{Account: *a, Lines: []models.LedgerLine{}}
+
+	err = s.db.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(l.debit - l.credit), 0)
+		FROM journal_lines l
+		JOIN journal_entries e ON e.id = l.entry_id
+		WHERE l.account_id = $1 AND e.entry_date < $2::date
+	`, aID, from).Scan(&ledger.OpeningBalance)
+	if err != nil {
+		return nil, err
+	}
+	ledger.OpeningBalance *= sign
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT e.id, e.entry_date, e.source_type, e.source_id, e.description, l.debit, l.credit
+		FROM journal_lines l
+		JOIN journal_entries e ON e.id = l.entry_id
+		WHERE l.account_id = $1 AND e.entry_date BETWEEN $2::date AND $3::date
+		ORDER BY e.entry_date ASC, e.created_at ASC, l.id ASC
+	`, aID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	balance := ledger.OpeningBalance
+	for rows.Next() {
+		var l models.LedgerLine
+		if err := rows.Scan(&l.EntryID, &l.EntryDate, &l.SourceType, &l.SourceID, &l.Description, &l.Debit, &l.Credit); err != nil {
+			return nil, err
+		}
+
+		balance += sign * (l.Debit - l.Credit)
+		l.Balance = balance
+
+		ledger.TotalDebit += l.Debit
+		ledger.TotalCredit += l.Credit
+		ledger.Lines = append(ledger.Lines, l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	ledger.ClosingBalance = balance
+	return ledger, nil
+}
+
+func (s *LedgerStore) GetBalanceSheet(ctx context.Context, date time.Time) (*models.BalanceSheet, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	balances, err := getAccountBalances(ctx, s.db, date)
+	if err != nil {
+		return nil, err
+	}
+
+	bs := &models.BalanceSheet{
+		Date: date,
+		Assets: []models.BalanceSheetLine{},
+		Liabilities: []models.BalanceSheetLine{},
+		Equity: []models.BalanceSheetLine{},
+	}
+
+	for _, b := range balances {
+		net := b.debit - b.credit
+		line := models.BalanceSheetLine{AccountID: b.ID, Code: b.Code, Name: b.Name, Balance: -net}
+
+		switch b.Type {
+		case models.AccountAsset:
+			line.Balance = net
+			bs.TotalAssets += net
+			bs.Assets = append(bs.Assets, line)
+		case models.AccountLiability:
+			bs.TotalLiabilities += line.Balance
+			bs.Liabilities = append(bs.Liabilities, line)
+		case models.AccountEquity:
+			bs.TotalEquity += line.Balance
+			bs.Equity = append(bs.Equity, line)
+		default:
+			bs.CurrentEarnings -= net
+		}
+	}
+
+	bs.TotalEquity += bs.CurrentEarnings
+	bs.Balanced = math.Abs(bs.TotalAssets - bs.TotalLiabilities - bs.TotalEquity) < 0.005
+
+	return bs, nil
+}
+
+type accountBalance struct {
+	models.Account
+	debit float64
+	credit float64
+}
+
+// Debit and credit totals of every account up to and including date
+func getAccountBalances(ctx context.Context, q querier, date time.Time) ([]accountBalance, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT a.id, a.code, a.name, a.type, COALESCE(b.debit, 0), COALESCE(b.credit, 0)
+		FROM accounts a
+		LEFT JOIN (
+			SELECT l.account_id, SUM(l.debit) as debit, SUM(l.credit) as credit
+			FROM journal_lines l
+			JOIN journal_entries e ON e.id = l.entry_id
+			WHERE e.entry_date <= $1::date
+			GROUP BY l.account_id
+		) b ON b.account_id = a.id
+		ORDER BY a.code ASC
+	`, date)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	balances := []accountBalance{}
+	for rows.Next() {
+		var b accountBalance
+		if err := rows.Scan(&b.ID, &b.Code, &b.Name, &b.Type, &b.debit, &b.credit); err != nil {
+			return nil, err
+		}
+		balances = append(balances, b)
+	}
+
+	return balances, rows.Err()
+}
+
+func getAccount(ctx context.Context, q querier, aID string) (*models.Account, error) {
+	var a models.Account
+
+	err := q.QueryRowContext(ctx, `
+		SELECT ` + accountColumns + `
+		FROM accounts
+		WHERE id = $1
+	`, aID).Scan(accountFields(&a)...)
+
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("Account")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// Scan targets in the order of accountColumns
+func accountFields(a *models.Account) []any {
+	return []any{
+		&a.ID,
+		&a.Code,
+		&a.Name,
+		&a.Type,
+		&a.IsSystem,
+		&a.CreatedAt,
+		&a.UpdatedAt,
+	}
+}
+
+func debitNormal(accountType string) bool {
+	return accountType == models.AccountAsset || accountType == models.AccountExpense
+}
+
+func getJournalEntry(ctx context.Context, q querier, eID string) (*models.JournalEntry, error) {
+	var e models.JournalEntry
+
+	err := q.QueryRowContext(ctx, `
+		SELECT id, entry_date, source_type, source_id, description, COALESCE(reversal_of, ''), created_at
+		FROM journal_entries
+		WHERE id = $1
+	`, eID).Scan(
+		&e.ID,
+		&e.EntryDate,
+		&e.SourceType,
+		&e.SourceID,
+		&e.Description,
+		&e.ReversalOf,
+		&e.CreatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("Journal entry")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []models.JournalEntry{e}
+	if err := loadJournalLines(ctx, q, entries); err != nil {
+		return nil, err
+	}
+
+	return &entries[0], nil
+}
+
+func loadJournalLines(ctx context.Context, q querier, entries []models.JournalEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(entries))
+	index := make(map[string]int, len(entries))
+	for i := range entries {
+		ids[i] = entries[i].ID
+		index[entries[i].ID] = i
+		entries[i].Lines = []models.JournalLine{}
+	}
+
+	rows, err := q.QueryContext(ctx, `
+		SELECT l.id, l.entry_id, l.account_id, a.code, a.name, l.debit, l.credit
+		FROM journal_lines l
+		JOIN accounts a ON a.id = l.account_id
+		WHERE l.entry_id = ANY($1)
+		ORDER BY l.credit ASC, a.code ASC
+	`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var l models.JournalLine
+		var eID string
+		if err := rows.Scan(&l.ID, &eID, &l.AccountID, &l.AccountCode, &l.AccountName, &l.Debit, &l.Credit); err != nil {
+			return err
+		}
+		i := index[eID]
+		entries[i].Lines = append(entries[i].Lines, l)
+	}
+
+	return rows.Err()
+}
+
+// Check an entry balances and write it. Lines may name their account by code
+// instead of id, which is how the automatic postings refer to system accounts.
+func postJournalEntry(ctx context.Context, q querier, e *models.JournalEntry) error {
+	if len(e.Lines) < 2 {
+		return utils.NewBadRequestError("A journal entry needs at least two lines")
+	}
+
+	var debit, credit float64
+	for i := range e.Lines {
+		l := &e.Lines[i]
+		if l.Debit < 0 || l.Credit < 0 || (l.Debit == 0) == (l.Credit == 0) {
+			return utils.NewBadRequestError(fmt.Sprintf("Line %d must have either a debit or a credit", i + 1))
+		}
+		if err := resolveJournalAccount(ctx, q, l); err != nil {
+			return err
+		}
+		debit += l.Debit
+		credit += l.Credit
+	}
+
+	if math.Abs(debit - credit) >= 0.005 {
+		return utils.NewBadRequestError(fmt.Sprintf("Entry does not balance: debits %.2f, credits %.2f", debit, credit))
+	}
+
+	e.ID = uuid.New().String()
+
+	err := q.QueryRowContext(ctx, `
+		INSERT INTO journal_entries (id, entry_date, source_type, source_id, description, reversal_of)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''))
+		RETURNING created_at
+	`, e.ID, e.EntryDate, e.SourceType, e.SourceID, e.Description, e.ReversalOf).Scan(&e.CreatedAt)
+	if isUniqueViolation(err) {
+		return utils.NewConflictError("Journal entry has already been reversed")
+	}
+	if err != nil {
+		return err
+	}
+
+	for i := range e.Lines {
+		l := &e.Lines[i]
+		l.ID = uuid.New().String()
+
+		_, err := q.ExecContext(ctx, `
+			INSERT INTO journal_lines (id, entry_id, account_id, debit, credit)
+			VALUES ($1, $2, $3, $4, $5)
+		`, l.ID, e.ID, l.AccountID, l.Debit, l.Credit)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func resolveJournalAccount(ctx context.Context, q querier, l *models.JournalLine) error {
+	query := `SELECT id, code, name FROM accounts WHERE id = $1`
+	key := l.AccountID
+	if key == "" {
+		query = `SELECT id, code, name FROM accounts WHERE code = $1`
+		key = l.AccountCode
+	}
+
+	err := q.QueryRowContext(ctx, query, key).Scan(&l.AccountID, &l.AccountCode, &l.AccountName)
+	if err == sql.ErrNoRows {
+		return utils.NewBadRequestError(fmt.Sprintf("Account %s does not exist", key))
+	}
+	return err
+}
+
+// Post the mirror image of an entry, dated today like the other reversals
+func postJournalReversal(ctx context.Context, q querier, e *models.JournalEntry, description string) (*models.JournalEntry, error) {
+	reversal := &models.JournalEntry{
+		EntryDate: time.Now(),
+		SourceType: e.SourceType,
+		SourceID: e.SourceID,
+		Description: description,
+		ReversalOf: e.ID,
+	}
+
+	for _, l := range e.Lines {
+		reversal.Lines = append(reversal.Lines, models.JournalLine{AccountID: l.AccountID, Debit: l.Credit, Credit: l.Debit})
+	}
+
+	if err := postJournalEntry(ctx, q, reversal); err != nil {
+		return nil, err
+	}
+	return reversal, nil
+}
+
+// Entries of a source that are still in effect, i.e. not reversals and not
+// reversed yet
+func getActiveJournalEntries(ctx context.Context, q querier, sourceType, sourceID string) ([]models.JournalEntry, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT e.id, e.entry_date, e.source_type, e.source_id, e.description, e.created_at
+		FROM journal_entries e
+		WHERE e.source_type = $1 AND e.source_id = $2 AND e.reversal_of IS NULL
+			AND NOT EXISTS (SELECT 1 FROM journal_entries r WHERE r.reversal_of = e.id)
+		ORDER BY e.created_at ASC
+	`, sourceType, sourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []models.JournalEntry{}
+	for rows.Next() {
+		var e models.JournalEntry
+		if err := rows.Scan(&e.ID, &e.EntryDate, &e.SourceType, &e.SourceID, &e.Description, &e.CreatedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := loadJournalLines(ctx, q, entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Reverse whatever a source has posted so far
+func reverseJournalEntries(ctx context.Context, q querier, sourceType, sourceID, description string) error {
+	entries, err := getActiveJournalEntries(ctx, q, sourceType, sourceID)
+	if err != nil {
+		return err
+	}
+
+	for i := range entries {
+		if _, err := postJournalReversal(ctx, q, &entries[i], description); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Bring the ledger in line with a source document: reverse what it posted
+// before and post e instead. Nothing is written when the entry in effect
+// already says the same thing, so editing a customer's address doesn't clutter
+// the journal. An entry without lines only reverses.
+func syncJournalEntry(ctx context.Context, q querier, e *models.JournalEntry, reversalNote string) error {
+	for i := range e.Lines {
+		if err := resolveJournalAccount(ctx, q, &e.Lines[i]); err != nil {
+			return err
+		}
+	}
+
+	active, err := getActiveJournalEntries(ctx, q, e.SourceType, e.SourceID)
+	if err != nil {
+		return err
+	}
+
+	if len(active) == 1 && sameJournalEntry(&active[0], e) {
+		return nil
+	}
+
+	for i := range active {
+		if _, err := postJournalReversal(ctx, q, &active[i], reversalNote); err != nil {
+			return err
+		}
+	}
+
+	if len(e.Lines) == 0 {
+		return nil
+	}
+	return postJournalEntry(ctx, q, e)
+}
+
+func sameJournalEntry(a, b *models.JournalEntry) bool {
+	if a.EntryDate.Format("2006-01-02") != b.EntryDate.Format("2006-01-02") || a.Description != b.Description {
+		return false
+	}
+	return journalLinesKey(a.Lines) == journalLinesKey(b.Lines)
+}
+
+func journalLinesKey(lines []models.JournalLine) string {
+	keys := make([]string, len(lines))
+	for i, l := range lines {
+		keys[i] = fmt.Sprintf("%s:%.2f:%.2f", l.AccountID, l.Debit, l.Credit)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
+
+// Credit sale: the customer owes us the total
+func postTransactionJournal(ctx context.Context, q querier, t *models.Transaction) error {
+	e := &models.JournalEntry{
+		EntryDate: t.PurchaseDate,
+		SourceType: models.JournalSourceTransaction,
+		SourceID: t.ID,
+		Description: "Sale to " + t.Customer,
+	}
+
+	// PPN collected is owed to the tax office, only the subtotal is revenue
+	if t.TotalPrice > 0 {
+		e.Lines = []models.JournalLine{
+			{AccountCode: models.AccountCodeReceivable, Debit: t.TotalPrice},
+			{AccountCode: models.AccountCodeSales, Credit: t.Subtotal},
+		}
+		if t.TaxAmount > 0 {
+			e.Lines = append(e.Lines, models.JournalLine{AccountCode: models.AccountCodeTaxPayable, Credit: t.TaxAmount})
+		}
+	}
+
+	return syncJournalEntry(ctx, q, e, "Sale updated")
+}
+
+// Money in settles the receivable; transfers land in the bank, the rest in the drawer
+func postPaymentJournal(ctx context.Context, q querier, p *models.Payment, customer string) error {
+	account := models.AccountCodeCash
+	if p.Method == models.PaymentMethodTransfer {
+		account = models.AccountCodeBank
+	}
+
+	return postJournalEntry(ctx, q, &models.JournalEntry{
+		EntryDate: p.PaymentDate,
+		SourceType: models.JournalSourcePayment,
+		SourceID: p.ID,
+		Description: "Payment from " + customer,
+		Lines: []models.JournalLine{
+			{AccountCode: account, Debit: p.Amount},
+			{AccountCode: models.AccountCodeReceivable, Credit: p.Amount},
+		},
+	})
+}
+
+// Materials bought from a supplier are owed to them; without a supplier they
+// were paid for from the drawer
+func postReceiptJournal(ctx context.Context, q querier, r *models.MaterialReceipt) error {
+	amount := r.Quantity * r.UnitCost
+	if amount <= 0 {
+		return nil
+	}
+
+	account := models.AccountCodeCash
+	if r.SupplierID != "" {
+		account = models.AccountCodePayable
+	}
+
+	var material string
+	if err := q.QueryRowContext(ctx, `SELECT name FROM materials WHERE id = $1`, r.MaterialID).Scan(&material); err != nil {
+		return err
+	}
+
+	return postJournalEntry(ctx, q, &models.JournalEntry{
+		EntryDate: r.ReceivedDate,
+		SourceType: models.JournalSourceReceipt,
+		SourceID: r.ID,
+		Description: "Received " + material,
+		Lines: []models.JournalLine{
+			{AccountCode: models.AccountCodeInventory, Debit: amount},
+			{AccountCode: account, Credit: amount},
+		},
+	})
+}
+
+// Materials a production used leave inventory at their average received cost.
+// Re-posted whenever the production changes; nothing used means only a reversal.
+func postProductionJournal(ctx context.Context, q querier, p *models.Production, consumed []models.ProductionMaterial) error {
+	var amount float64
+	for _, c := range consumed {
+		cost, err := getMaterialUnitCost(ctx, q, c.MaterialID, p.ProductionDate)
+		if err != nil {
+			return err
+		}
+		amount += c.Quantity * cost
+	}
+	amount = math.Round(amount * 100) / 100
+
+	e := &models.JournalEntry{
+		EntryDate: p.ProductionDate,
+		SourceType: models.JournalSourceProduction,
+		SourceID: p.ID,
+		Description: "Materials used in production",
+	}
+
+	if amount > 0 {
+		e.Lines = []models.JournalLine{
+			{AccountCode: models.AccountCodeMaterialsUsed, Debit: amount},
+			{AccountCode: models.AccountCodeInventory, Credit: amount},
+		}
+	}
+
+	return syncJournalEntry(ctx, q, e, "Production updated")
+}
+
+// Expenses are paid from the drawer into the category's expense account.
+// Unpaid ones are owed to the supplier until they are settled.
+func postExpenseJournal(ctx context.Context, q querier, x *models.Expense) error {
+	var accountID string
+	err := q.QueryRowContext(ctx, `
+		SELECT COALESCE(c.account_id, (SELECT id FROM accounts WHERE code = $2))
+		FROM expense_categories c
+		WHERE c.id = $1
+	`, x.CategoryID, models.AccountCodeOtherExpenses).Scan(&accountID)
+	if err != nil {
+		return err
+	}
+
+	credit := models.AccountCodeCash
+	if x.Unpaid && x.SupplierID != "" {
+		credit = models.AccountCodePayable
+	}
+
+	return syncJournalEntry(ctx, q, &models.JournalEntry{
+		EntryDate: x.ExpenseDate,
+		SourceType: models.JournalSourceExpense,
+		SourceID: x.ID,
+		Description: x.Category + " expense",
+		Lines: []models.JournalLine{
+			{AccountID: accountID, Debit: x.Amount},
+			{AccountCode: credit, Credit: x.Amount},
+		},
+	}, "Expense updated")
+}
\ No newline at end of file
@@ -0,0 +1,659 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kevinbrivio/batako-backend/internal/models"
+	"github.com/kevinbrivio/batako-backend/internal/utils"
+)
+
+type MaterialStore struct {
+	db *sql.DB
+}
+
+func (s *MaterialStore) Create(ctx context.Context, m *models.Material) error {
+	m.ID = uuid.New().String()
+	m.Code = strings.ToLower(strings.TrimSpace(m.Code))
+
+	query := `
+		INSERT INTO materials (id, code, name, unit, low_stock_threshold)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING on_hand, created_at, updated_at
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	err := s.db.QueryRowContext(
+		ctx,
+		query,
+		m.ID,
+		m.Code,
+		m.Name,
+		m.Unit,
+		m.LowStockThreshold,
+	).Scan(
+		&m.OnHand,
+		&m.CreatedAt,
+		&m.UpdatedAt,
+	)
+
+	if isUniqueViolation(err) {
+		return utils.NewConflictError("Material code already exists")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	m.IsLowStock = m.LowStockThreshold > 0 && m.OnHand <= m.LowStockThreshold
+	return nil
+}
+
+func (s *MaterialStore) GetAll(ctx context.Context, limit, offset int) ([]models.Material, int, error) {
+	query := `
+		SELECT
+			id,
+			code,
+			name,
+			unit,
+			on_hand,
+			low_stock_threshold,
+			low_stock_threshold > 0 AND on_hand <= low_stock_threshold as is_low_stock,
+			COUNT(*) OVER() as total_count,
+			created_at,
+			updated_at
+		FROM materials
+		ORDER BY name ASC
+		LIMIT $1 OFFSET $2
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	materials := []models.Material{}
+	var totalCount int
+
+	for rows.Next() {
+		var m models.Material
+		if err := rows.Scan(
+			&m.ID,
+			&m.Code,
+			&m.Name,
+			&m.Unit,
+			&m.OnHand,
+			&m.LowStockThreshold,
+			&m.IsLowStock,
+			&totalCount,
+			&m.CreatedAt,
+			&m.UpdatedAt,
+		); err != nil {
+			return materials, 0, err
+		}
+		materials = append(materials, m)
+	}
+	if err = rows.Err(); err != nil {
+		return materials, 0, err
+	}
+
+	return materials, totalCount, nil
+}
+
+// Materials at or below their reorder threshold, the emptiest first
+func (s *MaterialStore) GetLowStock(ctx context.Context) ([]models.Material, error) {
+	query := `
+		SELECT
+			id,
+			code,
+			name,
+			unit,
+			on_hand,
+			low_stock_threshold,
+			created_at,
+			updated_at
+		FROM materials
+		WHERE low_stock_threshold > 0 AND on_hand <= low_stock_threshold
+		ORDER BY on_hand / low_stock_threshold ASC
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	materials := []models.Material{}
+
+	for rows.Next() {
+		m := models.Material{IsLowStock: true}
+		if err := rows.Scan(
+			&m.ID,
+			&m.Code,
+			&m.Name,
+			&m.Unit,
+			&m.OnHand,
+			&m.LowStockThreshold,
+			&m.CreatedAt,
+			&m.UpdatedAt,
+		); err != nil {
+			return materials, err
+		}
+		materials = append(materials, m)
+	}
+	if err = rows.Err(); err != nil {
+		return materials, err
+	}
+
+	return materials, nil
+}
+
+func (s *MaterialStore) GetByID(ctx context.Context, mID string) (*models.Material, error) {
+	query := `
+		SELECT
+			id,
+			code,
+			name,
+			unit,
+			on_hand,
+			low_stock_threshold,
+			low_stock_threshold > 0 AND on_hand <= low_stock_threshold as is_low_stock,
+			created_at,
+			updated_at
+		FROM materials
+		WHERE id = $1
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	var m models.Material
+
+	err := s.db.QueryRowContext(
+		ctx, query,
+		mID,
+	).Scan(
+		&m.ID,
+		&m.Code,
+		&m.Name,
+		&m.Unit,
+		&m.OnHand,
+		&m.LowStockThreshold,
+		&m.IsLowStock,
+		&m.CreatedAt,
+		&m.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("Material")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &m, nil
+}
+
+func (s *MaterialStore) Update(ctx context.Context, m *models.Material) error {
+	m.Code = strings.ToLower(strings.TrimSpace(m.Code))
+
+	query := `
+		UPDATE materials
+		SET code = $2, name = $3, unit = $4, low_stock_threshold = $5, updated_at = NOW()
+		WHERE id = $1
+		RETURNING on_hand, created_at, updated_at
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	err := s.db.QueryRowContext(
+		ctx,
+		query,
+		m.ID,
+		m.Code,
+		m.Name,
+		m.Unit,
+		m.LowStockThreshold,
+	).Scan(
+		&m.OnHand,
+		&m.CreatedAt,
+		&m.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return utils.NewNotFoundError("Material")
+	}
+
+	if isUniqueViolation(err) {
+		return utils.NewConflictError("Material code already exists")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	m.IsLowStock = m.LowStockThreshold > 0 && m.OnHand <= m.LowStockThreshold
+	return nil
+}
+
+func (s *MaterialStore) Delete(ctx context.Context, mID string) error {
+	query := `
+		DELETE FROM materials
+		WHERE id = $1;
+	`
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, mID)
+	if isForeignKeyViolation(err) {
+		return utils.NewConflictError("Material has stock history and cannot be deleted")
+	}
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return utils.NewNotFoundError("Material")
+	}
+	return nil
+}
+
+func (s *MaterialStore) CreateReceipt(ctx context.Context, r *models.MaterialReceipt) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		return insertMaterialReceipt(ctx, tx, r)
+	})
+}
+
+func (s *MaterialStore) GetReceipts(ctx context.Context, mID string, limit, offset int) ([]models.MaterialReceipt, int, error) {
+	query := `
+		SELECT
+			id,
+			material_id,
+			COALESCE(supplier_id, ''),
+			COALESCE(purchase_order_item_id, ''),
+			quantity,
+			unit_cost,
+			received_date,
+			note,
+			COUNT(*) OVER() as total_count,
+			created_at,
+			updated_at
+		FROM material_receipts
+		WHERE material_id = $1
+		ORDER BY received_date DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, mID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	receipts := []models.MaterialReceipt{}
+	var totalCount int
+
+	for rows.Next() {
+		var r models.MaterialReceipt
+		if err := rows.Scan(
+			&r.ID,
+			&r.MaterialID,
+			&r.SupplierID,
+			&r.PurchaseOrderItemID,
+			&r.Quantity,
+			&r.UnitCost,
+			&r.ReceivedDate,
+			&r.Note,
+			&totalCount,
+			&r.CreatedAt,
+			&r.UpdatedAt,
+		); err != nil {
+			return receipts, 0, err
+		}
+		receipts = append(receipts, r)
+	}
+	if err = rows.Err(); err != nil {
+		return receipts, 0, err
+	}
+
+	return receipts, totalCount, nil
+}
+
+func (s *MaterialStore) GetMovements(ctx context.Context, mID string, limit, offset int) ([]models.MaterialMovement, int, error) {
+	query := `
+		SELECT
+			id,
+			material_id,
+			movement_date,
+			source_type,
+			source_id,
+			quantity,
+			note,
+			COUNT(*) OVER() as total_count,
+			created_at
+		FROM material_movements
+		WHERE material_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, mID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	movements := []models.MaterialMovement{}
+	var totalCount int
+
+	for rows.Next() {
+		var m models.MaterialMovement
+		if err := rows.Scan(
+			&m.ID,
+			&m.MaterialID,
+			&m.MovementDate,
+			&m.SourceType,
+			&m.SourceID,
+			&m.Quantity,
+			&m.Note,
+			&totalCount,
+			&m.CreatedAt,
+		); err != nil {
+			return movements, 0, err
+		}
+		movements = append(movements, m)
+	}
+	if err = rows.Err(); err != nil {
+		return movements, 0, err
+	}
+
+	return movements, totalCount, nil
+}
+
+func insertMaterialReceipt(ctx context.Context, q querier, r *models.MaterialReceipt) error {
+	r.ID = uuid.New().String()
+
+	query := `
+		INSERT INTO material_receipts (id, material_id, supplier_id, purchase_order_item_id, quantity, unit_cost, received_date, note)
+		VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), $5, $6, $7, $8)
+		RETURNING created_at, updated_at
+	`
+
+	err := q.QueryRowContext(
+		ctx,
+		query,
+		r.ID,
+		r.MaterialID,
+		r.SupplierID,
+		r.PurchaseOrderItemID,
+		r.Quantity,
+		r.UnitCost,
+		r.ReceivedDate,
+		r.Note,
+	).Scan(
+		&r.CreatedAt,
+		&r.UpdatedAt,
+	)
+
+	if isForeignKeyViolation(err) {
+		return utils.NewBadRequestError("Material or supplier does not exist")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	err = postMaterialMovement(ctx, q, &models.MaterialMovement{
+		MaterialID: r.MaterialID,
+		MovementDate: r.ReceivedDate,
+		SourceType: models.MaterialSourceReceipt,
+		SourceID: r.ID,
+		Quantity: r.Quantity,
+		Note: r.Note,
+	})
+	if err != nil {
+		return err
+	}
+
+	return postReceiptJournal(ctx, q, r)
+}
+
+// Record a movement and keep the material's running on-hand quantity in step
+func postMaterialMovement(ctx context.Context, q querier, m *models.MaterialMovement) error {
+	m.ID = uuid.New().String()
+
+	query := `
+		INSERT INTO material_movements (id, material_id, movement_date, source_type, source_id, quantity, note)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING created_at
+	`
+
+	err := q.QueryRowContext(
+		ctx,
+		query,
+		m.ID,
+		m.MaterialID,
+		m.MovementDate,
+		m.SourceType,
+		m.SourceID,
+		m.Quantity,
+		m.Note,
+	).Scan(&m.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.ExecContext(ctx, `
+		UPDATE materials
+		SET on_hand = on_hand + $2, updated_at = NOW()
+		WHERE id = $1
+	`, m.MaterialID, m.Quantity)
+	return err
+}
+
+// Cancel the net effect a source has had on every material it touched
+func reverseMaterialMovements(ctx context.Context, q querier, sourceType, sourceID, note string) error {
+	rows, err := q.QueryContext(ctx, `
+		SELECT material_id, SUM(quantity)
+		FROM material_movements
+		WHERE source_type = $1 AND source_id = $2
+		GROUP BY material_id
+		HAVING SUM(quantity) <> 0
+	`, sourceType, sourceID)
+	if err != nil {
+		return err
+	}
+
+	reversals := []models.MaterialMovement{}
+	for rows.Next() {
+		var materialID string
+		var net float64
+		if err := rows.Scan(&materialID, &net); err != nil {
+			rows.Close()
+			return err
+		}
+		reversals = append(reversals, models.MaterialMovement{
+			MaterialID: materialID,
+			MovementDate: time.Now(),
+			SourceType: sourceType,
+			SourceID: sourceID,
+			Quantity: -net,
+			Note: note,
+		})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for i := range reversals {
+		if err := postMaterialMovement(ctx, q, &reversals[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Store the materials listed on a production and deduct them, together with
+// cement_used, from raw material stock
+func consumeProductionMaterials(ctx context.Context, q querier, p *models.Production) error {
+	consumed := []models.ProductionMaterial{}
+
+	if p.CementUsed > 0 {
+		var cementID string
+		err := q.QueryRowContext(ctx, `SELECT id FROM materials WHERE code = $1`, models.MaterialCodeCement).Scan(&cementID)
+		if err == sql.ErrNoRows {
+			return utils.NewBadRequestError("Cement material is not configured")
+		}
+		if err != nil {
+			return err
+		}
+		consumed = append(consumed, models.ProductionMaterial{MaterialID: cementID, Quantity: p.CementUsed})
+	}
+
+	for i := range p.Materials {
+		err := q.QueryRowContext(ctx, `
+			SELECT name, unit FROM materials WHERE id = $1 AND code <> $2
+		`, p.Materials[i].MaterialID, models.MaterialCodeCement).Scan(&p.Materials[i].MaterialName, &p.Materials[i].Unit)
+		if err == sql.ErrNoRows {
+			return utils.NewBadRequestError("Unknown material, cement is recorded through cement_used")
+		}
+		if err != nil {
+			return err
+		}
+
+		_, err = q.ExecContext(ctx, `
+			INSERT INTO production_materials (id, production_id, material_id, quantity)
+			VALUES ($1, $2, $3, $4)
+		`, uuid.New().String(), p.ID, p.Materials[i].MaterialID, p.Materials[i].Quantity)
+		if err != nil {
+			return err
+		}
+
+		consumed = append(consumed, p.Materials[i])
+	}
+
+	if err := ensureMaterialsAvailable(ctx, q, consumed); err != nil {
+		return err
+	}
+
+	for _, c := range consumed {
+		err := postMaterialMovement(ctx, q, &models.MaterialMovement{
+			MaterialID: c.MaterialID,
+			MovementDate: p.ProductionDate,
+			SourceType: models.MaterialSourceProduction,
+			SourceID: p.ID,
+			Quantity: -c.Quantity,
+			Note: "Used in production",
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return postProductionJournal(ctx, q, p, consumed)
+}
+
+// Serialise material-consuming transactions so availability checks can't race each other
+func lockMaterialLedger(ctx context.Context, q querier) error {
+	_, err := q.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext('material_movements'))`)
+	return err
+}
+
+// Check on-hand stock per material, summing lines that use the same material
+func ensureMaterialsAvailable(ctx context.Context, q querier, consumed []models.ProductionMaterial) error {
+	if err := lockMaterialLedger(ctx, q); err != nil {
+		return err
+	}
+
+	var materialIDs []string
+	quantities := map[string]float64{}
+	for _, c := range consumed {
+		if _, ok := quantities[c.MaterialID]; !ok {
+			materialIDs = append(materialIDs, c.MaterialID)
+		}
+		quantities[c.MaterialID] += c.Quantity
+	}
+
+	for _, mID := range materialIDs {
+		var name, unit string
+		var onHand float64
+		err := q.QueryRowContext(ctx, `
+			SELECT name, unit, on_hand FROM materials WHERE id = $1
+		`, mID).Scan(&name, &unit, &onHand)
+		if err != nil {
+			return err
+		}
+
+		if quantities[mID] > onHand {
+			return utils.NewConflictError(fmt.Sprintf("Insufficient %s: %.2f %s on hand, %.2f %s needed", name, onHand, unit, quantities[mID], unit))
+		}
+	}
+	return nil
+}
+
+// Weighted average cost of everything received up to date. Materials never
+// received at a cost are worth 0, as they never entered inventory at a value.
+func getMaterialUnitCost(ctx context.Context, q querier, materialID string, date time.Time) (float64, error) {
+	var cost float64
+	err := q.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(quantity * unit_cost) / NULLIF(SUM(quantity), 0), 0)
+		FROM material_receipts
+		WHERE material_id = $1 AND unit_cost > 0 AND received_date <= $2::date
+	`, materialID, date).Scan(&cost)
+	return cost, err
+}
+
+func loadProductionMaterials(ctx context.Context, q querier, p *models.Production) error {
+	rows, err := q.QueryContext(ctx, `
+		SELECT pm.material_id, m.name, m.unit, pm.quantity
+		FROM production_materials pm
+		JOIN materials m ON m.id = pm.material_id
+		WHERE pm.production_id = $1
+		ORDER BY m.name ASC
+	`, p.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	p.Materials = []models.ProductionMaterial{}
+	for rows.Next() {
+		var pm models.ProductionMaterial
+		if err := rows.Scan(&pm.MaterialID, &pm.MaterialName, &pm.Unit, &pm.Quantity); err != nil {
+			return err
+		}
+		p.Materials = append(p.Materials, pm)
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,191 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kevinbrivio/batako-backend/internal/models"
+	"github.com/kevinbrivio/batako-backend/internal/utils"
+)
+
+type PaymentStore struct {
+	db *sql.DB
+}
+
+func (s *PaymentStore) Create(ctx context.Context, p *models.Payment) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		return insertPayment(ctx, tx, p)
+	})
+}
+
+func (s *PaymentStore) GetByTransaction(ctx context.Context, tID string) ([]models.Payment, error) {
+	query := `
+		SELECT
+			id,
+			transaction_id,
+			amount,
+			payment_date,
+			method,
+			reference,
+			note,
+			created_at,
+			updated_at
+		FROM payments
+		WHERE transaction_id = $1
+		ORDER BY payment_date ASC, created_at ASC
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, tID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	payments := []models.Payment{}
+
+	for rows.Next() {
+		var p models.Payment
+		if err := rows.Scan(
+			&p.ID,
+			&p.TransactionID,
+			&p.Amount,
+			&p.PaymentDate,
+			&p.Method,
+			&p.Reference,
+			&p.Note,
+			&p.CreatedAt,
+			&p.UpdatedAt,
+		); err != nil {
+			return payments, err
+		}
+		payments = append(payments, p)
+	}
+	if err = rows.Err(); err != nil {
+		return payments, err
+	}
+
+	return payments, nil
+}
+
+func (s *PaymentStore) Delete(ctx context.Context, tID, pID string) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		return deletePayment(ctx, tx, tID, pID)
+	})
+}
+
+// Record a payment against a transaction without letting it exceed the balance.
+// The transaction row is locked so two cashiers can't both take the last rupiah.
+func insertPayment(ctx context.Context, q querier, p *models.Payment) error {
+	var totalPrice, paid float64
+	var customer string
+	err := q.QueryRowContext(ctx, `
+		SELECT total_price, customer FROM transactions WHERE id = $1 FOR UPDATE
+	`, p.TransactionID).Scan(&totalPrice, &customer)
+	if err == sql.ErrNoRows {
+		return utils.NewNotFoundError("Transaction")
+	}
+	if err != nil {
+		return err
+	}
+
+	err = q.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(amount), 0) FROM payments WHERE transaction_id = $1
+	`, p.TransactionID).Scan(&paid)
+	if err != nil {
+		return err
+	}
+
+	if balance := totalPrice - paid; p.Amount > balance {
+		return utils.NewBadRequestError(fmt.Sprintf("Payment exceeds the outstanding balance of %.2f", balance))
+	}
+
+	p.ID = uuid.New().String()
+
+	query := `
+		INSERT INTO payments (id, transaction_id, amount, payment_date, method, reference, note)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING created_at, updated_at
+	`
+
+	err = q.QueryRowContext(
+		ctx,
+		query,
+		p.ID,
+		p.TransactionID,
+		p.Amount,
+		p.PaymentDate,
+		p.Method,
+		p.Reference,
+		p.Note,
+	).Scan(
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	if p.Method == models.PaymentMethodCash {
+		err := syncCashMovement(ctx, q, &models.CashMovement{
+			MovementDate: p.PaymentDate,
+			SourceType: models.CashSourcePayment,
+			SourceID: p.ID,
+			Amount: p.Amount,
+			Description: "Payment from " + customer,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return postPaymentJournal(ctx, q, p, customer)
+}
+
+
+// Remove a payment and undo everything it posted. A bank statement line it was
+// matched from goes back to unmatched.
+func deletePayment(ctx context.Context, q querier, tID, pID string) error {
+	_, err := q.ExecContext(ctx, `
+		UPDATE bank_statement_lines
+		SET status = $2, transaction_id = NULL, payment_id = NULL, updated_at = NOW()
+		WHERE payment_id = $1
+	`, pID, models.BankLineUnmatched)
+	if err != nil {
+		return err
+	}
+
+	res, err := q.ExecContext(ctx, `
+		DELETE FROM payments
+		WHERE id = $1 AND transaction_id = $2;
+	`, pID, tID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return utils.NewNotFoundError("Payment")
+	}
+
+	if err := syncCashMovement(ctx, q, &models.CashMovement{SourceType: models.CashSourcePayment, SourceID: pID}); err != nil {
+		return err
+	}
+
+	return reverseJournalEntries(ctx, q, models.JournalSourcePayment, pID, "Payment deleted")
+}
\ No newline at end of file
@@ -0,0 +1,365 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kevinbrivio/batako-backend/internal/models"
+	"github.com/kevinbrivio/batako-backend/internal/utils"
+)
+
+type PayrollStore struct {
+	db *sql.DB
+}
+
+// Good blocks of each batch made between $1 and $2, the piece rate in effect on
+// its production date and how many workers share it
+const payrollBatches = `
+	WITH batches AS (
+		SELECT
+			p.id,
+			p.quantity - COALESCE(rj.rejected, 0) as good,
+			(
+				SELECT r.rate FROM piece_rates r
+				WHERE r.product_id = p.product_id AND r.effective_from <= p.production_date
+				ORDER BY r.effective_from DESC
+				LIMIT 1
+			) as rate,
+			(SELECT COUNT(*) FROM production_crew c WHERE c.production_id = p.id) as crew_size
+		FROM productions p
+		LEFT JOIN (
+			SELECT production_id, SUM(quantity) as rejected
+			FROM production_rejects
+			GROUP BY production_id
+		) rj ON rj.production_id = p.id
+		WHERE p.production_date BETWEEN $1 AND $2
+	)
+`
+
+// The approved run of a period, or a draft computed from the work done in it
+func (s *PayrollStore) GetPeriod(ctx context.Context, period string, start, end time.Time) (*models.PayrollRun, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	run, err := getPayrollRun(ctx, s.db, period)
+	if err == sql.ErrNoRows {
+		return computePayroll(ctx, s.db, period, start, end)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return run, nil
+}
+
+// Store the period's pay as computed now. Productions and attendance in the period
+// are locked until the run is reopened.
+func (s *PayrollStore) Approve(ctx context.Context, period string, start, end time.Time) (*models.PayrollRun, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	var run *models.PayrollRun
+	err := withTx(ctx, s.db, func(tx *sql.Tx) error {
+		if err := lockPayroll(ctx, tx); err != nil {
+			return err
+		}
+
+		// A week and a month can cover the same days; nobody gets paid twice
+		var other string
+		err := tx.QueryRowContext(ctx, `
+			SELECT period FROM payroll_runs
+			WHERE period_start <= $2 AND period_end >= $1
+			LIMIT 1
+		`, start, end).Scan(&other)
+		if err == nil && other == period {
+			return utils.NewConflictError("Payroll for " + period + " is already approved")
+		}
+		if err == nil {
+			return utils.NewConflictError(fmt.Sprintf("Period overlaps the approved payroll for %s", other))
+		}
+		if err != sql.ErrNoRows {
+			return err
+		}
+
+		run, err = computePayroll(ctx, tx, period, start, end)
+		if err != nil {
+			return err
+		}
+
+		if run.UnpricedBatches > 0 {
+			return utils.NewBadRequestError(fmt.Sprintf("%d batches in this period have no piece rate", run.UnpricedBatches))
+		}
+
+		if run.OpenAttendance > 0 {
+			return utils.NewBadRequestError(fmt.Sprintf("%d attendance records in this period have no check-out", run.OpenAttendance))
+		}
+
+		if len(run.Lines) == 0 {
+			return utils.NewBadRequestError("Nobody worked in this period")
+		}
+
+		run.ID = uuid.New().String()
+		run.Status = models.PayrollApproved
+		run.ApprovedAt = new(time.Time)
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO payroll_runs (id, period, period_start, period_end, status, total, unassigned_batches)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING approved_at
+		`, run.ID, run.Period, run.PeriodStart, run.PeriodEnd, run.Status, run.Total, run.UnassignedBatches).Scan(run.ApprovedAt)
+		if err != nil {
+			return err
+		}
+
+		for _, line := range run.Lines {
+			_, err := tx.ExecContext(ctx, `
+				INSERT INTO payroll_lines (run_id, employee_id, employee, batches, quantity, piece_amount, days, overtime_hours, wage_amount, amount)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			`, run.ID, line.EmployeeID, line.Employee, line.Batches, line.Quantity, line.PieceAmount, line.Days, line.OvertimeHours, line.WageAmount, line.Amount)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return run, nil
+}
+
+func (s *PayrollStore) MarkPaid(ctx context.Context, period string) (*models.PayrollRun, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE payroll_runs SET status = $2, paid_at = NOW()
+		WHERE period = $1 AND status = $3
+	`, period, models.PayrollPaid, models.PayrollApproved)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	run, err := getPayrollRun(ctx, s.db, period)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewBadRequestError("Approve the payroll for " + period + " before paying it")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if rows == 0 {
+		return nil, utils.NewConflictError("Payroll for " + period + " is already paid")
+	}
+
+	return run, nil
+}
+
+// Drop an approved run so the period can be corrected. Paid runs stay.
+func (s *PayrollStore) Reopen(ctx context.Context, period string) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		if err := lockPayroll(ctx, tx); err != nil {
+			return err
+		}
+
+		var status string
+		err := tx.QueryRowContext(ctx, `
+			SELECT status FROM payroll_runs WHERE period = $1 FOR UPDATE
+		`, period).Scan(&status)
+		if err == sql.ErrNoRows {
+			return utils.NewNotFoundError("Payroll run")
+		}
+		if err != nil {
+			return err
+		}
+
+		if status == models.PayrollPaid {
+			return utils.NewConflictError("Payroll for " + period + " is already paid and cannot be reopened")
+		}
+
+		_, err = tx.ExecContext(ctx, `DELETE FROM payroll_runs WHERE period = $1`, period)
+		return err
+	})
+}
+
+func computePayroll(ctx context.Context, q querier, period string, start, end time.Time) (*models.PayrollRun, error) {
+	run := &models.PayrollRun{
+		Period: period,
+		PeriodStart: start,
+		PeriodEnd: end,
+		Status: models.PayrollDraft,
+		Lines: []models.PayrollLine{},
+	}
+
+	err := q.QueryRowContext(ctx, payrollBatches + `
+		SELECT
+			COUNT(*) FILTER (WHERE crew_size = 0),
+			COUNT(*) FILTER (WHERE crew_size > 0 AND rate IS NULL)
+		FROM batches
+	`, start, end).Scan(&run.UnassignedBatches, &run.UnpricedBatches)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := q.QueryContext(ctx, payrollBatches + `
+		SELECT
+			e.id,
+			e.name,
+			COUNT(*),
+			SUM(b.good::float8 / b.crew_size),
+			SUM(b.good::float8 / b.crew_size * COALESCE(b.rate, 0))
+		FROM batches b
+		JOIN production_crew c ON c.production_id = b.id
+		JOIN employees e ON e.id = c.employee_id
+		GROUP BY e.id, e.name
+		ORDER BY e.name ASC
+	`, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lines := map[string]*models.PayrollLine{}
+	for rows.Next() {
+		var line models.PayrollLine
+		if err := rows.Scan(&line.EmployeeID, &line.Employee, &line.Batches, &line.Quantity, &line.PieceAmount); err != nil {
+			return nil, err
+		}
+		lines[line.EmployeeID] = &line
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Daily helpers are paid for the shifts they worked
+	wages, err := getAttendanceWages(ctx, q, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, wage := range wages {
+		line, ok := lines[wage.EmployeeID]
+		if !ok {
+			line = &models.PayrollLine{EmployeeID: wage.EmployeeID, Employee: wage.Employee}
+			lines[wage.EmployeeID] = line
+		}
+		line.Days = wage.Days
+		line.OvertimeHours = wage.OvertimeHours
+		line.WageAmount = wage.Wage
+	}
+
+	err = q.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM attendance WHERE work_date BETWEEN $1 AND $2 AND check_out IS NULL
+	`, start, end).Scan(&run.OpenAttendance)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, line := range lines {
+		line.Amount = line.PieceAmount + line.WageAmount
+		run.Total += line.Amount
+		run.Lines = append(run.Lines, *line)
+	}
+
+	sort.Slice(run.Lines, func(i, j int) bool {
+		return run.Lines[i].Employee < run.Lines[j].Employee
+	})
+
+	return run, nil
+}
+
+func getPayrollRun(ctx context.Context, q querier, period string) (*models.PayrollRun, error) {
+	var run models.PayrollRun
+	run.ApprovedAt = new(time.Time)
+
+	err := q.QueryRowContext(ctx, `
+		SELECT id, period, period_start, period_end, status, total, unassigned_batches, approved_at, paid_at
+		FROM payroll_runs
+		WHERE period = $1
+	`, period).Scan(
+		&run.ID,
+		&run.Period,
+		&run.PeriodStart,
+		&run.PeriodEnd,
+		&run.Status,
+		&run.Total,
+		&run.UnassignedBatches,
+		run.ApprovedAt,
+		&run.PaidAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := q.QueryContext(ctx, `
+		SELECT employee_id, employee, batches, quantity, piece_amount, days, overtime_hours, wage_amount, amount
+		FROM payroll_lines
+		WHERE run_id = $1
+		ORDER BY employee ASC
+	`, run.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	run.Lines = []models.PayrollLine{}
+	for rows.Next() {
+		var line models.PayrollLine
+		if err := rows.Scan(
+			&line.EmployeeID,
+			&line.Employee,
+			&line.Batches,
+			&line.Quantity,
+			&line.PieceAmount,
+			&line.Days,
+			&line.OvertimeHours,
+			&line.WageAmount,
+			&line.Amount,
+		); err != nil {
+			return nil, err
+		}
+		run.Lines = append(run.Lines, line)
+	}
+
+	return &run, rows.Err()
+}
+
+// Serialises approving a payroll run with changes to the work it covers
+func lockPayroll(ctx context.Context, q querier) error {
+	_, err := q.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext('payroll_runs'))`)
+	return err
+}
+
+// Fail when date falls in a period whose payroll is already approved
+func ensurePayrollOpen(ctx context.Context, q querier, date time.Time) error {
+	if err := lockPayroll(ctx, q); err != nil {
+		return err
+	}
+
+	var period string
+	err := q.QueryRowContext(ctx, `
+		SELECT period FROM payroll_runs
+		WHERE $1::date BETWEEN period_start AND period_end
+		LIMIT 1
+	`, date).Scan(&period)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return utils.NewConflictError("Payroll for " + period + " is approved; work in it can no longer change")
+}
\ No newline at end of file
@@ -0,0 +1,259 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kevinbrivio/batako-backend/internal/models"
+	"github.com/kevinbrivio/batako-backend/internal/utils"
+)
+
+type PriceListStore struct {
+	db *sql.DB
+}
+
+func (s *PriceListStore) Create(ctx context.Context, p *models.PriceList) error {
+	p.ID = uuid.New().String()
+
+	query := `
+		INSERT INTO price_lists (id, product_id, unit_price, effective_from)
+		VALUES ($1, $2, $3, $4)
+		RETURNING created_at, updated_at
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	product, err := resolveProduct(ctx, s.db, p.ProductID)
+	if err != nil {
+		return err
+	}
+	p.ProductID = product.ID
+
+	err = s.db.QueryRowContext(
+		ctx,
+		query,
+		p.ID,
+		p.ProductID,
+		p.UnitPrice,
+		p.EffectiveFrom,
+	).Scan(
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	)
+
+	if isUniqueViolation(err) {
+		return utils.NewConflictError("A price for this product is already effective from this date")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *PriceListStore) GetAll(ctx context.Context, productID string, limit, offset int) ([]models.PriceList, int, error) {
+	query := `
+		SELECT
+			id,
+			product_id,
+			unit_price,
+			effective_from,
+			COUNT(*) OVER() as total_count,
+			created_at,
+			updated_at
+		FROM price_lists
+		WHERE $1 = '' OR product_id = $1
+		ORDER BY effective_from DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, productID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	prices := []models.PriceList{}
+	var totalCount int
+
+	for rows.Next() {
+		var p models.PriceList
+		if err := rows.Scan(
+			&p.ID,
+			&p.ProductID,
+			&p.UnitPrice,
+			&p.EffectiveFrom,
+			&totalCount,
+			&p.CreatedAt,
+			&p.UpdatedAt,
+		); err != nil {
+			return prices, 0, err
+		}
+		prices = append(prices, p)
+	}
+	if err = rows.Err(); err != nil {
+		return prices, 0, err
+	}
+
+	return prices, totalCount, nil
+}
+
+func (s *PriceListStore) GetByID(ctx context.Context, pID string) (*models.PriceList, error) {
+	query := `
+		SELECT id, product_id, unit_price, effective_from, created_at, updated_at
+		FROM price_lists
+		WHERE id = $1
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	var p models.PriceList
+
+	err := s.db.QueryRowContext(
+		ctx, query,
+		pID,
+	).Scan(
+		&p.ID,
+		&p.ProductID,
+		&p.UnitPrice,
+		&p.EffectiveFrom,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("Price")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// Price of a product (the default one when empty) that applies on the given date,
+// i.e. the latest one whose effective_from is not after it
+func (s *PriceListStore) GetEffective(ctx context.Context, productID string, date time.Time) (*models.PriceList, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	product, err := resolveProduct(ctx, s.db, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	return getEffectivePrice(ctx, s.db, product.ID, date)
+}
+
+func (s *PriceListStore) Update(ctx context.Context, p *models.PriceList) error {
+	query := `
+		UPDATE price_lists
+		SET unit_price = $2, effective_from = $3, updated_at = NOW()
+		WHERE id = $1
+		RETURNING product_id, created_at, updated_at
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	err := s.db.QueryRowContext(
+		ctx,
+		query,
+		p.ID,
+		p.UnitPrice,
+		p.EffectiveFrom,
+	).Scan(
+		&p.ProductID,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return utils.NewNotFoundError("Price")
+	}
+
+	if isUniqueViolation(err) {
+		return utils.NewConflictError("A price for this product is already effective from this date")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *PriceListStore) Delete(ctx context.Context, pID string) error {
+	query := `
+		DELETE FROM price_lists
+		WHERE id = $1;
+	`
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, pID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return utils.NewNotFoundError("Price")
+	}
+	return nil
+}
+
+// Falls back to the product's default price when no price list entry applies yet
+func getEffectivePrice(ctx context.Context, q querier, productID string, date time.Time) (*models.PriceList, error) {
+	query := `
+		SELECT id, product_id, unit_price, effective_from, created_at, updated_at
+		FROM price_lists
+		WHERE product_id = $1 AND effective_from <= $2::date
+		ORDER BY effective_from DESC
+		LIMIT 1
+	`
+
+	var p models.PriceList
+
+	err := q.QueryRowContext(ctx, query, productID, date).Scan(
+		&p.ID,
+		&p.ProductID,
+		&p.UnitPrice,
+		&p.EffectiveFrom,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		err = q.QueryRowContext(ctx, `SELECT default_price FROM products WHERE id = $1`, productID).Scan(&p.UnitPrice)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, err
+		}
+
+		if p.UnitPrice <= 0 {
+			return nil, utils.NewBadRequestError("No price is effective on " + date.Format("2006-01-02"))
+		}
+
+		p.ProductID = productID
+		return &p, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
@@ -0,0 +1,430 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kevinbrivio/batako-backend/internal/models"
+	"github.com/kevinbrivio/batako-backend/internal/utils"
+)
+
+type ProductStore struct {
+	db *sql.DB
+}
+
+func (s *ProductStore) Create(ctx context.Context, p *models.Product) error {
+	p.ID = uuid.New().String()
+	p.Name = utils.CleanName(p.Name)
+
+	query := `
+		INSERT INTO products (id, name, length_mm, width_mm, height_mm, unit, default_price, curing_days, strength_class, min_strength, is_default)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING created_at, updated_at
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		if p.IsDefault {
+			if err := clearDefaultProduct(ctx, tx); err != nil {
+				return err
+			}
+		}
+
+		err := tx.QueryRowContext(
+			ctx,
+			query,
+			p.ID,
+			p.Name,
+			p.LengthMM,
+			p.WidthMM,
+			p.HeightMM,
+			p.Unit,
+			p.DefaultPrice,
+			p.CuringDays,
+			p.StrengthClass,
+			p.MinStrength,
+			p.IsDefault,
+		).Scan(
+			&p.CreatedAt,
+			&p.UpdatedAt,
+		)
+
+		if isUniqueViolation(err) {
+			return utils.NewConflictError("Product already exists")
+		}
+
+		if err != nil {
+			return err
+		}
+
+		return replaceProductRecipe(ctx, tx, p)
+	})
+}
+
+func (s *ProductStore) GetAll(ctx context.Context, limit, offset int) ([]models.Product, int, error) {
+	query := `
+		SELECT
+			id,
+			name,
+			length_mm,
+			width_mm,
+			height_mm,
+			unit,
+			default_price,
+			curing_days,
+			strength_class,
+			min_strength,
+			is_default,
+			COUNT(*) OVER() as total_count,
+			created_at,
+			updated_at
+		FROM products
+		ORDER BY name ASC
+		LIMIT $1 OFFSET $2
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	products := []models.Product{}
+	var totalCount int
+
+	for rows.Next() {
+		var p models.Product
+		if err := rows.Scan(
+			&p.ID,
+			&p.Name,
+			&p.LengthMM,
+			&p.WidthMM,
+			&p.HeightMM,
+			&p.Unit,
+			&p.DefaultPrice,
+			&p.CuringDays,
+			&p.StrengthClass,
+			&p.MinStrength,
+			&p.IsDefault,
+			&totalCount,
+			&p.CreatedAt,
+			&p.UpdatedAt,
+		); err != nil {
+			return products, 0, err
+		}
+		products = append(products, p)
+	}
+	if err = rows.Err(); err != nil {
+		return products, 0, err
+	}
+
+	return products, totalCount, nil
+}
+
+func (s *ProductStore) GetByID(ctx context.Context, pID string) (*models.Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	p, err := getProduct(ctx, s.db, pID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := loadProductRecipe(ctx, s.db, p); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func (s *ProductStore) Update(ctx context.Context, p *models.Product) error {
+	p.Name = utils.CleanName(p.Name)
+
+	query := `
+		UPDATE products
+		SET name = $2, length_mm = $3, width_mm = $4, height_mm = $5, unit = $6, default_price = $7, curing_days = $8, strength_class = $9, min_strength = $10, is_default = $11, updated_at = NOW()
+		WHERE id = $1
+		RETURNING created_at, updated_at
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		current, err := getProduct(ctx, tx, p.ID)
+		if err != nil {
+			return err
+		}
+
+		if current.IsDefault && !p.IsDefault {
+			return utils.NewBadRequestError("Make another product the default instead")
+		}
+
+		if p.IsDefault && !current.IsDefault {
+			if err := clearDefaultProduct(ctx, tx); err != nil {
+				return err
+			}
+		}
+
+		err = tx.QueryRowContext(
+			ctx,
+			query,
+			p.ID,
+			p.Name,
+			p.LengthMM,
+			p.WidthMM,
+			p.HeightMM,
+			p.Unit,
+			p.DefaultPrice,
+			p.CuringDays,
+			p.StrengthClass,
+			p.MinStrength,
+			p.IsDefault,
+		).Scan(
+			&p.CreatedAt,
+			&p.UpdatedAt,
+		)
+
+		if isUniqueViolation(err) {
+			return utils.NewConflictError("Product already exists")
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM product_recipes WHERE product_id = $1`, p.ID); err != nil {
+			return err
+		}
+
+		return replaceProductRecipe(ctx, tx, p)
+	})
+}
+
+func (s *ProductStore) Delete(ctx context.Context, pID string) error {
+	query := `
+		DELETE FROM products
+		WHERE id = $1 AND NOT is_default;
+	`
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, pID)
+	if isForeignKeyViolation(err) {
+		return utils.NewConflictError("Product has productions or sales and cannot be deleted")
+	}
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		if _, err := getProduct(ctx, s.db, pID); err != nil {
+			return err
+		}
+		return utils.NewConflictError("The default product cannot be deleted")
+	}
+	return nil
+}
+
+// The product a production or sale is for, the default one when none is given
+func resolveProduct(ctx context.Context, q querier, pID string) (*models.Product, error) {
+	var id string
+	err := q.QueryRowContext(ctx, `
+		SELECT id FROM products WHERE ($1 = '' AND is_default) OR id = $1
+	`, pID).Scan(&id)
+	if err == sql.ErrNoRows && pID == "" {
+		return nil, utils.NewBadRequestError("Product cannot be empty")
+	}
+	if err == sql.ErrNoRows {
+		return nil, utils.NewBadRequestError("Product does not exist")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return getProduct(ctx, q, id)
+}
+
+func getProduct(ctx context.Context, q querier, pID string) (*models.Product, error) {
+	query := `
+		SELECT id, name, length_mm, width_mm, height_mm, unit, default_price, curing_days, strength_class, min_strength, is_default, created_at, updated_at
+		FROM products
+		WHERE id = $1
+	`
+
+	var p models.Product
+
+	err := q.QueryRowContext(ctx, query, pID).Scan(
+		&p.ID,
+		&p.Name,
+		&p.LengthMM,
+		&p.WidthMM,
+		&p.HeightMM,
+		&p.Unit,
+		&p.DefaultPrice,
+		&p.CuringDays,
+		&p.StrengthClass,
+		&p.MinStrength,
+		&p.IsDefault,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("Product")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+func clearDefaultProduct(ctx context.Context, q querier) error {
+	_, err := q.ExecContext(ctx, `UPDATE products SET is_default = FALSE, updated_at = NOW() WHERE is_default`)
+	return err
+}
+
+func replaceProductRecipe(ctx context.Context, q querier, p *models.Product) error {
+	for i := range p.Recipe {
+		item := &p.Recipe[i]
+
+		err := q.QueryRowContext(ctx, `
+			SELECT name, unit FROM materials WHERE id = $1
+		`, item.MaterialID).Scan(&item.MaterialName, &item.Unit)
+		if err == sql.ErrNoRows {
+			return utils.NewBadRequestError("Material does not exist")
+		}
+		if err != nil {
+			return err
+		}
+
+		_, err = q.ExecContext(ctx, `
+			INSERT INTO product_recipes (product_id, material_id, quantity_per_unit)
+			VALUES ($1, $2, $3)
+		`, p.ID, item.MaterialID, item.QuantityPerUnit)
+		if isUniqueViolation(err) {
+			return utils.NewBadRequestError("Each material can only appear once in a recipe")
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func loadProductRecipe(ctx context.Context, q querier, p *models.Product) error {
+	rows, err := q.QueryContext(ctx, `
+		SELECT r.material_id, m.name, m.unit, r.quantity_per_unit
+		FROM product_recipes r
+		JOIN materials m ON m.id = r.material_id
+		WHERE r.product_id = $1
+		ORDER BY m.name ASC
+	`, p.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	p.Recipe = []models.ProductRecipeItem{}
+	for rows.Next() {
+		var item models.ProductRecipeItem
+		if err := rows.Scan(&item.MaterialID, &item.MaterialName, &item.Unit, &item.QuantityPerUnit); err != nil {
+			return err
+		}
+		p.Recipe = append(p.Recipe, item)
+	}
+
+	return rows.Err()
+}
+
+
+func (s *ProductStore) CreatePieceRate(ctx context.Context, pr *models.PieceRate) error {
+	pr.ID = uuid.New().String()
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	if _, err := getProduct(ctx, s.db, pr.ProductID); err != nil {
+		return err
+	}
+
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO piece_rates (id, product_id, rate, effective_from)
+		VALUES ($1, $2, $3, $4)
+		RETURNING created_at
+	`, pr.ID, pr.ProductID, pr.Rate, pr.EffectiveFrom).Scan(&pr.CreatedAt)
+
+	if isUniqueViolation(err) {
+		return utils.NewConflictError("A piece rate already starts on this date")
+	}
+
+	return err
+}
+
+// Piece rates of a product, newest first
+func (s *ProductStore) GetPieceRates(ctx context.Context, pID string) ([]models.PieceRate, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	if _, err := getProduct(ctx, s.db, pID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, product_id, rate, effective_from, created_at
+		FROM piece_rates
+		WHERE product_id = $1
+		ORDER BY effective_from DESC
+	`, pID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rates := []models.PieceRate{}
+	for rows.Next() {
+		var pr models.PieceRate
+		if err := rows.Scan(&pr.ID, &pr.ProductID, &pr.Rate, &pr.EffectiveFrom, &pr.CreatedAt); err != nil {
+			return rates, err
+		}
+		rates = append(rates, pr)
+	}
+
+	return rates, rows.Err()
+}
+
+func (s *ProductStore) DeletePieceRate(ctx context.Context, pID, rID string) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, `
+		DELETE FROM piece_rates WHERE id = $1 AND product_id = $2
+	`, rID, pID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return utils.NewNotFoundError("Piece rate")
+	}
+	return nil
+}
\ No newline at end of file
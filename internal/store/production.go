@@ -3,6 +3,7 @@ package store
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -19,43 +20,92 @@ func (s *ProductionStore) Create(ctx context.Context, p *models.Production) erro
 	p.ID = uuid.New().String()
 	
 	query := `
-		INSERT INTO productions (id, quantity, cement_used, production_date)
-		VALUES ($1, $2, $3, $4) RETURNING created_at, updated_at
+		INSERT INTO productions (id, product_id, quantity, cement_used, production_date, curing_days)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING ready_date, created_at, updated_at
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
 	defer cancel()
 
-	err := s.db.QueryRowContext(
-		ctx,
-		query,
-		p.ID,
-		p.Quantity,
-		p.CementUsed,
-		p.ProductionDate,
-	).Scan(
-		&p.CreatedAt,
-		&p.UpdatedAt,
-	)
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		if err := resolveProductionProduct(ctx, tx, p); err != nil {
+			return err
+		}
 
-	if err != nil {
-		return err
-	}
+		if err := ensurePayrollOpen(ctx, tx, p.ProductionDate); err != nil {
+			return err
+		}
 
-	return nil
+		err := tx.QueryRowContext(
+			ctx,
+			query,
+			p.ID,
+			p.ProductID,
+			p.Quantity,
+			p.CementUsed,
+			p.ProductionDate,
+			p.CuringDays,
+		).Scan(
+			&p.ReadyDate,
+			&p.CreatedAt,
+			&p.UpdatedAt,
+		)
+
+		if err != nil {
+			return err
+		}
+		setCured(p)
+
+		if err := replaceProductionCrew(ctx, tx, p); err != nil {
+			return err
+		}
+
+		if err := replaceProductionEquipment(ctx, tx, p); err != nil {
+			return err
+		}
+
+		if err := consumeProductionMaterials(ctx, tx, p); err != nil {
+			return err
+		}
+
+		if err := lockStockLedger(ctx, tx); err != nil {
+			return err
+		}
+
+		// Finished blocks go into the yard
+		return postStockMovement(ctx, tx, &models.StockMovement{
+			ProductID: p.ProductID,
+			MovementDate: p.ProductionDate,
+			SourceType: models.StockSourceProduction,
+			SourceID: p.ID,
+			Quantity: p.Quantity,
+			Note: "Production",
+		})
+	})
 }
 
 func (s *ProductionStore) GetAll(ctx context.Context, limit, offset int) ([]models.Production, int, error) {
 	query := `
 		SELECT 
-			id, 
+			productions.id, 
+			product_id,
+			pr.name,
 			quantity,
+			COALESCE(rj.rejected, 0) as rejected,
 			cement_used,
 			production_date,
+			productions.curing_days,
+			ready_date,
 			COUNT(*) OVER() as total_count,
-			created_at,
-			updated_at
+			productions.created_at,
+			productions.updated_at
 		FROM productions
+		JOIN products pr ON pr.id = productions.product_id
+		LEFT JOIN (
+			SELECT production_id, SUM(quantity) as rejected
+			FROM production_rejects
+			GROUP BY production_id
+		) rj ON rj.production_id = productions.id
 		ORDER BY production_date DESC
 		LIMIT $1 OFFSET $2
 	`
@@ -77,15 +127,21 @@ func (s *ProductionStore) GetAll(ctx context.Context, limit, offset int) ([]mode
 		var p models.Production
 		if err := rows.Scan(
 			&p.ID,
+			&p.ProductID,
+			&p.Product,
 			&p.Quantity,
+			&p.Rejected,
 			&p.CementUsed,
 			&p.ProductionDate,
+			&p.CuringDays,
+			&p.ReadyDate,
 			&totalCount, 
 			&p.CreatedAt,
 			&p.UpdatedAt,
 		); err != nil {
 			return productions, 0, err
 		}
+		setCured(&p)
 		productions = append(productions, p)
 	}
 	if err = rows.Err(); err != nil {
@@ -95,21 +151,33 @@ func (s *ProductionStore) GetAll(ctx context.Context, limit, offset int) ([]mode
 	return productions, totalCount, nil
 }
 
-func (s *ProductionStore) GetAllMonthly(ctx context.Context, monthOffset int) ([]models.Production, int, int, error) {
+func (s *ProductionStore) GetAllMonthly(ctx context.Context, monthOffset int) ([]models.Production, models.ProductionSummary, error) {
 	today := time.Now()
 
 	start, end := utils.GetMonthRange(today, monthOffset)
 	
 	query := `
 		SELECT 
-			id, 
+			productions.id, 
+			product_id,
+			pr.name,
 			quantity,
+			COALESCE(rj.rejected, 0) as rejected,
 			COUNT(*) OVER() as total_count,
 			SUM(quantity) OVER() as total_quantity,
+			SUM(COALESCE(rj.rejected, 0)) OVER() as total_rejected,
 			production_date,
-			created_at,
-			updated_at
+			productions.curing_days,
+			ready_date,
+			productions.created_at,
+			productions.updated_at
 		FROM productions
+		JOIN products pr ON pr.id = productions.product_id
+		LEFT JOIN (
+			SELECT production_id, SUM(quantity) as rejected
+			FROM production_rejects
+			GROUP BY production_id
+		) rj ON rj.production_id = productions.id
 		WHERE production_date BETWEEN $1 AND $2
 		ORDER BY production_date ASC;
 	`
@@ -119,39 +187,71 @@ func (s *ProductionStore) GetAllMonthly(ctx context.Context, monthOffset int) ([
 
 	rows, err := s.db.QueryContext(ctx, query, start, end)
 	if err != nil {
-		return nil, 0, 0, err
+		return nil, models.ProductionSummary{}, err
 	}
 	defer rows.Close()
 
 	productions := []models.Production{}
-	var totalCount, totalQuantity int
+	var summary models.ProductionSummary
 
 	for rows.Next() {
 		var p models.Production
 		if err := rows.Scan(
 			&p.ID,
+			&p.ProductID,
+			&p.Product,
 			&p.Quantity,
-			&totalCount, 
-			&totalQuantity,
+			&p.Rejected,
+			&summary.TotalCount, 
+			&summary.TotalQuantity,
+			&summary.TotalRejected,
 			&p.ProductionDate,
+			&p.CuringDays,
+			&p.ReadyDate,
 			&p.CreatedAt,
 			&p.UpdatedAt,
 		); err != nil {
-			return productions, 0, 0, err
+			return productions, models.ProductionSummary{}, err
 		}
+		setCured(&p)
 		productions = append(productions, p)
 	}
 	if err = rows.Err(); err != nil {
-		return productions, 0, 0, err
+		return productions, models.ProductionSummary{}, err
+	}
+
+	summary.TotalGood = summary.TotalQuantity - summary.TotalRejected
+
+	summary.Products, err = getProductionProductSummary(ctx, s.db, start, end)
+	if err != nil {
+		return productions, models.ProductionSummary{}, err
 	}
 
-	return productions, totalCount, totalQuantity, nil
+	return productions, summary, nil
 }
 
 func (s *ProductionStore) GetByID(ctx context.Context, pID string) (*models.Production, error) {
 	query := `
-		SELECT * FROM productions
-		WHERE id = $1
+		SELECT
+			productions.id,
+			product_id,
+			pr.name,
+			quantity,
+			COALESCE(rj.rejected, 0) as rejected,
+			cement_used,
+			production_date,
+			productions.curing_days,
+			ready_date,
+			productions.created_at,
+			productions.updated_at
+		FROM productions
+		JOIN products pr ON pr.id = productions.product_id
+		LEFT JOIN (
+			SELECT production_id, SUM(quantity) as rejected
+			FROM production_rejects
+			GROUP BY production_id
+		) rj ON rj.production_id = productions.id
+		WHERE productions.id = $1
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
@@ -164,9 +264,14 @@ func (s *ProductionStore) GetByID(ctx context.Context, pID string) (*models.Prod
 		pID,
 	).Scan(
 		&p.ID,
+		&p.ProductID,
+		&p.Product,
 		&p.Quantity,
+		&p.Rejected,
 		&p.CementUsed,
 		&p.ProductionDate,
+		&p.CuringDays,
+		&p.ReadyDate,
 		&p.CreatedAt,
 		&p.UpdatedAt,
 	)
@@ -178,6 +283,23 @@ func (s *ProductionStore) GetByID(ctx context.Context, pID string) (*models.Prod
 	if err != nil {
 		return nil, err
 	}
+	setCured(&p)
+
+	if err := loadProductionMaterials(ctx, s.db, &p); err != nil {
+		return nil, err
+	}
+
+	if err := loadProductionRejects(ctx, s.db, &p); err != nil {
+		return nil, err
+	}
+
+	if err := loadProductionCrew(ctx, s.db, &p); err != nil {
+		return nil, err
+	}
+
+	if err := loadProductionEquipment(ctx, s.db, &p); err != nil {
+		return nil, err
+	}
 
 	return &p, nil
 }
@@ -185,35 +307,93 @@ func (s *ProductionStore) GetByID(ctx context.Context, pID string) (*models.Prod
 func (s *ProductionStore) Update(ctx context.Context, p *models.Production) error {
 	query := `
 		UPDATE productions
-		SET quantity = $2, cement_used = $3, production_date = $4, updated_at = NOW()
+		SET product_id = $2, quantity = $3, cement_used = $4, production_date = $5, curing_days = $6, updated_at = NOW()
 		WHERE id = $1
-		RETURNING created_at, updated_at
+		RETURNING ready_date, created_at, updated_at
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
 	defer cancel()
 
-	err := s.db.QueryRowContext(
-		ctx,
-		query,
-		p.ID,
-		p.Quantity,
-		p.CementUsed,
-		p.ProductionDate,
-	).Scan(
-		&p.CreatedAt,
-		&p.UpdatedAt,
-	)
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		if err := resolveProductionProduct(ctx, tx, p); err != nil {
+			return err
+		}
 
-	if err == sql.ErrNoRows {
-		return utils.NewNotFoundError("Production")
-	}
+		current, err := getProductionForUpdate(ctx, tx, p.ID)
+		if err != nil {
+			return err
+		}
+		p.Rejected = current.Rejected
 
-	if err != nil {
-		return err
-	}
+		// Neither the period it was in nor the one it moves to may be paid out
+		if err := ensurePayrollOpen(ctx, tx, current.ProductionDate); err != nil {
+			return err
+		}
 
-	return nil
+		if err := ensurePayrollOpen(ctx, tx, p.ProductionDate); err != nil {
+			return err
+		}
+
+		if p.Quantity < p.Rejected {
+			return utils.NewBadRequestError(fmt.Sprintf("Quantity cannot be lower than the %d already rejected", p.Rejected))
+		}
+
+		err = tx.QueryRowContext(
+			ctx,
+			query,
+			p.ID,
+			p.ProductID,
+			p.Quantity,
+			p.CementUsed,
+			p.ProductionDate,
+			p.CuringDays,
+		).Scan(
+			&p.ReadyDate,
+			&p.CreatedAt,
+			&p.UpdatedAt,
+		)
+
+		if err == sql.ErrNoRows {
+			return utils.NewNotFoundError("Production")
+		}
+
+		if err != nil {
+			return err
+		}
+		setCured(p)
+
+		if err := replaceProductionCrew(ctx, tx, p); err != nil {
+			return err
+		}
+
+		if err := replaceProductionEquipment(ctx, tx, p); err != nil {
+			return err
+		}
+
+		// Give back what the old version consumed, then consume again
+		if err := lockMaterialLedger(ctx, tx); err != nil {
+			return err
+		}
+
+		if err := reverseMaterialMovements(ctx, tx, models.MaterialSourceProduction, p.ID, "Production updated"); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM production_materials WHERE production_id = $1`, p.ID); err != nil {
+			return err
+		}
+
+		if err := consumeProductionMaterials(ctx, tx, p); err != nil {
+			return err
+		}
+
+		if err := lockStockLedger(ctx, tx); err != nil {
+			return err
+		}
+
+		return restockProduction(ctx, tx, p, "Production updated")
+	})
 }
 
 func (s *ProductionStore) Delete(ctx context.Context, pID string) error {
@@ -224,18 +404,411 @@ func (s *ProductionStore) Delete(ctx context.Context, pID string) error {
 	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
 	defer cancel()
 
-	res, err := s.db.ExecContext(ctx, query, pID)
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		p, err := getProductionForUpdate(ctx, tx, pID)
+		if err != nil {
+			return err
+		}
+
+		if err := ensurePayrollOpen(ctx, tx, p.ProductionDate); err != nil {
+			return err
+		}
+
+		res, err := tx.ExecContext(ctx, query, pID)
+		if isForeignKeyViolation(err) {
+			return utils.NewConflictError("Production has quality tests and cannot be deleted")
+		}
+		if err != nil {
+			return err
+		}
+
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if rows == 0 {
+			return utils.NewNotFoundError("Production")
+		}
+
+		if err := reverseMaterialMovements(ctx, tx, models.MaterialSourceProduction, pID, "Production deleted"); err != nil {
+			return err
+		}
+
+		if err := reverseJournalEntries(ctx, tx, models.JournalSourceProduction, pID, "Production deleted"); err != nil {
+			return err
+		}
+
+		if err := lockStockLedger(ctx, tx); err != nil {
+			return err
+		}
+
+		return reverseStockMovements(ctx, tx, models.StockSourceProduction, pID, "Production deleted")
+	})
+}
+
+// Record blocks of a batch as thrown away; only the good ones stay in stock
+func (s *ProductionStore) AddReject(ctx context.Context, r *models.ProductionReject) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		p, err := getProductionForUpdate(ctx, tx, r.ProductionID)
+		if err != nil {
+			return err
+		}
+
+		if err := ensurePayrollOpen(ctx, tx, p.ProductionDate); err != nil {
+			return err
+		}
+
+		if r.RejectDate.Before(p.ProductionDate) {
+			return utils.NewBadRequestError("Reject date cannot be before the production date")
+		}
+
+		if r.Quantity > p.GoodQuantity {
+			return utils.NewBadRequestError(fmt.Sprintf("Only %d good blocks left in this batch, %d rejected", p.GoodQuantity, r.Quantity))
+		}
+
+		if err := lockStockLedger(ctx, tx); err != nil {
+			return err
+		}
+
+		// Blocks that were already sold can't be rejected anymore
+		var onHand int
+		err = tx.QueryRowContext(ctx, `
+			SELECT COALESCE(SUM(quantity), 0) FROM stock_movements WHERE product_id = $1
+		`, p.ProductID).Scan(&onHand)
+		if err != nil {
+			return err
+		}
+
+		if r.Quantity > onHand {
+			return utils.NewConflictError(fmt.Sprintf("Only %d %s in stock, %d rejected", onHand, p.Product, r.Quantity))
+		}
+
+		r.ID = uuid.New().String()
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO production_rejects (id, production_id, reason, quantity, reject_date, note)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING created_at
+		`, r.ID, r.ProductionID, r.Reason, r.Quantity, r.RejectDate, r.Note).Scan(&r.CreatedAt)
+		if err != nil {
+			return err
+		}
+
+		p.Rejected += r.Quantity
+		setCured(p)
+		return restockProduction(ctx, tx, p, "Rejects recorded")
+	})
+}
+
+func (s *ProductionStore) DeleteReject(ctx context.Context, pID, rID string) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		p, err := getProductionForUpdate(ctx, tx, pID)
+		if err != nil {
+			return err
+		}
+
+		if err := ensurePayrollOpen(ctx, tx, p.ProductionDate); err != nil {
+			return err
+		}
+
+		var quantity int
+		err = tx.QueryRowContext(ctx, `
+			DELETE FROM production_rejects
+			WHERE id = $1 AND production_id = $2
+			RETURNING quantity
+		`, rID, pID).Scan(&quantity)
+		if err == sql.ErrNoRows {
+			return utils.NewNotFoundError("Reject")
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := lockStockLedger(ctx, tx); err != nil {
+			return err
+		}
+
+		p.Rejected -= quantity
+		setCured(p)
+		return restockProduction(ctx, tx, p, "Rejects removed")
+	})
+}
+
+// Produced and rejected blocks per month of production between start and end,
+// with the rejects split by reason. Months without production are included.
+func (s *ProductionStore) GetYield(ctx context.Context, start, end time.Time) ([]models.YieldMonth, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	months := []models.YieldMonth{}
+	index := map[string]int{}
+	for m := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, start.Location()); !m.After(end); m = m.AddDate(0, 1, 0) {
+		index[m.Format("2006-01")] = len(months)
+		months = append(months, models.YieldMonth{Month: m.Format("2006-01"), Reasons: []models.YieldReason{}})
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT to_char(production_date, 'YYYY-MM'), SUM(quantity)
+		FROM productions
+		WHERE production_date BETWEEN $1 AND $2
+		GROUP BY 1
+	`, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var month string
+		var produced int
+		if err := rows.Scan(&month, &produced); err != nil {
+			return nil, err
+		}
+		if i, ok := index[month]; ok {
+			months[i].Produced = produced
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	reasonRows, err := s.db.QueryContext(ctx, `
+		SELECT to_char(p.production_date, 'YYYY-MM'), r.reason, SUM(r.quantity)
+		FROM production_rejects r
+		JOIN productions p ON p.id = r.production_id
+		WHERE p.production_date BETWEEN $1 AND $2
+		GROUP BY 1, 2
+		ORDER BY 1, 3 DESC
+	`, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer reasonRows.Close()
+
+	for reasonRows.Next() {
+		var month string
+		var yr models.YieldReason
+		if err := reasonRows.Scan(&month, &yr.Reason, &yr.Quantity); err != nil {
+			return nil, err
+		}
+		if i, ok := index[month]; ok {
+			months[i].Rejected += yr.Quantity
+			months[i].Reasons = append(months[i].Reasons, yr)
+		}
+	}
+	if err := reasonRows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range months {
+		m := &months[i]
+		m.Good = m.Produced - m.Rejected
+		m.RejectRate = rejectRate(m.Rejected, m.Produced)
+		for j := range m.Reasons {
+			m.Reasons[j].RejectRate = rejectRate(m.Reasons[j].Quantity, m.Produced)
+		}
+	}
+
+	return months, nil
+}
+
+// What finishes curing on each of the next days after from, starting from the
+// stock that is already cured
+func (s *ProductionStore) GetCuringSchedule(ctx context.Context, productID string, from time.Time, days int) ([]models.CuringDay, int, error) {
+	query := `
+		SELECT ready_date, COUNT(*) as batch_count, SUM(quantity - COALESCE(rj.rejected, 0)) as quantity
+		FROM productions
+		LEFT JOIN (
+			SELECT production_id, SUM(quantity) as rejected
+			FROM production_rejects
+			GROUP BY production_id
+		) rj ON rj.production_id = productions.id
+		WHERE ready_date > $1::date AND ready_date <= $1::date + $2::int
+			AND ($3 = '' OR product_id = $3)
+		GROUP BY ready_date
+		ORDER BY ready_date ASC
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	var cured int
+	err := s.db.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(m.quantity) FILTER (WHERE `+curedMovement+`), 0)
+		FROM stock_movements m
+		LEFT JOIN productions p ON m.source_type = 'production' AND p.id = m.source_id
+		WHERE $2 = '' OR m.product_id = $2
+	`, from, productID).Scan(&cured)
 	if err != nil {
-		return err
+		return nil, 0, err
 	}
 
-	rows, err := res.RowsAffected()
+	rows, err := s.db.QueryContext(ctx, query, from, days, productID)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	ready := map[string]models.CuringDay{}
+
+	for rows.Next() {
+		var d models.CuringDay
+		if err := rows.Scan(
+			&d.Date,
+			&d.BatchCount,
+			&d.Quantity,
+		); err != nil {
+			return nil, 0, err
+		}
+		ready[d.Date.Format("2006-01-02")] = d
+	}
+	if err = rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	// One entry per day, including the days nothing becomes ready
+	schedule := make([]models.CuringDay, 0, days)
+	available := cured
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
+
+	for i := 1; i <= days; i++ {
+		date := start.AddDate(0, 0, i)
+		d := ready[date.Format("2006-01-02")]
+		d.Date = date
+		available += d.Quantity
+		d.Available = available
+		schedule = append(schedule, d)
+	}
+
+	return schedule, cured, nil
+}
+
+func setCured(p *models.Production) {
+	p.IsCured = !p.ReadyDate.After(time.Now())
+	p.GoodQuantity = p.Quantity - p.Rejected
+}
+
+// Fill in the product (the default one when none is given) and take its curing
+// period unless the batch sets its own
+func resolveProductionProduct(ctx context.Context, q querier, p *models.Production) error {
+	product, err := resolveProduct(ctx, q, p.ProductID)
 	if err != nil {
 		return err
 	}
 
-	if rows == 0 {
-		return utils.NewNotFoundError("Production")
+	p.ProductID, p.Product = product.ID, product.Name
+	if p.CuringDays == 0 {
+		p.CuringDays = product.CuringDays
 	}
 	return nil
+}
+
+// Production per product between start and end
+func getProductionProductSummary(ctx context.Context, q querier, start, end time.Time) ([]models.ProductSummary, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT pr.id, pr.name, COUNT(*), SUM(p.quantity)
+		FROM productions p
+		JOIN products pr ON pr.id = p.product_id
+		WHERE p.production_date BETWEEN $1 AND $2
+		GROUP BY pr.id, pr.name
+		ORDER BY pr.name ASC
+	`, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := []models.ProductSummary{}
+	for rows.Next() {
+		var ps models.ProductSummary
+		if err := rows.Scan(&ps.ProductID, &ps.ProductName, &ps.Count, &ps.Quantity); err != nil {
+			return products, err
+		}
+		products = append(products, ps)
+	}
+
+	return products, rows.Err()
+}
+
+
+// Production row locked against concurrent reject changes
+func getProductionForUpdate(ctx context.Context, q querier, pID string) (*models.Production, error) {
+	var p models.Production
+	err := q.QueryRowContext(ctx, `
+		SELECT productions.id, product_id, pr.name, quantity, production_date, ready_date
+		FROM productions
+		JOIN products pr ON pr.id = productions.product_id
+		WHERE productions.id = $1
+		FOR UPDATE OF productions
+	`, pID).Scan(&p.ID, &p.ProductID, &p.Product, &p.Quantity, &p.ProductionDate, &p.ReadyDate)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("Production")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = q.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(quantity), 0) FROM production_rejects WHERE production_id = $1
+	`, pID).Scan(&p.Rejected)
+	if err != nil {
+		return nil, err
+	}
+
+	setCured(&p)
+	return &p, nil
+}
+
+// Replace what a batch put into stock with its current good quantity. The
+// caller holds the stock ledger lock.
+func restockProduction(ctx context.Context, q querier, p *models.Production, note string) error {
+	if err := reverseStockMovements(ctx, q, models.StockSourceProduction, p.ID, note); err != nil {
+		return err
+	}
+
+	return postStockMovement(ctx, q, &models.StockMovement{
+		ProductID: p.ProductID,
+		MovementDate: p.ProductionDate,
+		SourceType: models.StockSourceProduction,
+		SourceID: p.ID,
+		Quantity: p.GoodQuantity,
+		Note: "Production",
+	})
+}
+
+func loadProductionRejects(ctx context.Context, q querier, p *models.Production) error {
+	rows, err := q.QueryContext(ctx, `
+		SELECT id, production_id, reason, quantity, reject_date, note, created_at
+		FROM production_rejects
+		WHERE production_id = $1
+		ORDER BY reject_date ASC, created_at ASC
+	`, p.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	p.Rejects = []models.ProductionReject{}
+	for rows.Next() {
+		var r models.ProductionReject
+		if err := rows.Scan(&r.ID, &r.ProductionID, &r.Reason, &r.Quantity, &r.RejectDate, &r.Note, &r.CreatedAt); err != nil {
+			return err
+		}
+		p.Rejects = append(p.Rejects, r)
+	}
+
+	return rows.Err()
+}
+
+func rejectRate(rejected, produced int) float64 {
+	if produced == 0 {
+		return 0
+	}
+	return float64(rejected) / float64(produced) * 100
 }
\ No newline at end of file
@@ -0,0 +1,525 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kevinbrivio/batako-backend/internal/models"
+	"github.com/kevinbrivio/batako-backend/internal/utils"
+)
+
+type PurchaseOrderStore struct {
+	db *sql.DB
+}
+
+func (s *PurchaseOrderStore) Create(ctx context.Context, po *models.PurchaseOrder) error {
+	po.ID = uuid.New().String()
+	po.Status = models.PurchaseOrderOpen
+
+	query := `
+		INSERT INTO purchase_orders (id, supplier_id, order_date, status, note)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at, updated_at
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(
+			ctx,
+			query,
+			po.ID,
+			po.SupplierID,
+			po.OrderDate,
+			po.Status,
+			po.Note,
+		).Scan(
+			&po.CreatedAt,
+			&po.UpdatedAt,
+		)
+
+		if isForeignKeyViolation(err) {
+			return utils.NewBadRequestError("Supplier does not exist")
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if err := tx.QueryRowContext(ctx, `SELECT name FROM suppliers WHERE id = $1`, po.SupplierID).Scan(&po.SupplierName); err != nil {
+			return err
+		}
+
+		return replacePurchaseOrderItems(ctx, tx, po)
+	})
+}
+
+func (s *PurchaseOrderStore) GetAll(ctx context.Context, status string, limit, offset int) ([]models.PurchaseOrder, int, error) {
+	query := `
+		SELECT
+			po.id,
+			po.supplier_id,
+			sp.name,
+			po.order_date,
+			po.status,
+			po.note,
+			po.total_amount,
+			COUNT(*) OVER() as total_count,
+			po.created_at,
+			po.updated_at
+		FROM purchase_orders po
+		JOIN suppliers sp ON sp.id = po.supplier_id
+		WHERE $1 = '' OR po.status = $1
+		ORDER BY po.order_date DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, status, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	orders := []models.PurchaseOrder{}
+	var totalCount int
+
+	for rows.Next() {
+		var po models.PurchaseOrder
+		if err := rows.Scan(
+			&po.ID,
+			&po.SupplierID,
+			&po.SupplierName,
+			&po.OrderDate,
+			&po.Status,
+			&po.Note,
+			&po.TotalAmount,
+			&totalCount,
+			&po.CreatedAt,
+			&po.UpdatedAt,
+		); err != nil {
+			return orders, 0, err
+		}
+		orders = append(orders, po)
+	}
+	if err = rows.Err(); err != nil {
+		return orders, 0, err
+	}
+
+	return orders, totalCount, nil
+}
+
+func (s *PurchaseOrderStore) GetByID(ctx context.Context, poID string) (*models.PurchaseOrder, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	po, err := getPurchaseOrder(ctx, s.db, poID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := loadPurchaseOrderItems(ctx, s.db, po); err != nil {
+		return nil, err
+	}
+
+	return po, nil
+}
+
+// Change supplier, date, note and items of an order nothing has been received against yet
+func (s *PurchaseOrderStore) Update(ctx context.Context, po *models.PurchaseOrder) error {
+	query := `
+		UPDATE purchase_orders
+		SET supplier_id = $2, order_date = $3, note = $4, updated_at = NOW()
+		WHERE id = $1
+		RETURNING status, created_at, updated_at
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		current, err := getPurchaseOrderForUpdate(ctx, tx, po.ID)
+		if err != nil {
+			return err
+		}
+
+		if current.Status != models.PurchaseOrderOpen {
+			return utils.NewConflictError("Only open purchase orders can be changed")
+		}
+
+		err = tx.QueryRowContext(
+			ctx,
+			query,
+			po.ID,
+			po.SupplierID,
+			po.OrderDate,
+			po.Note,
+		).Scan(
+			&po.Status,
+			&po.CreatedAt,
+			&po.UpdatedAt,
+		)
+
+		if isForeignKeyViolation(err) {
+			return utils.NewBadRequestError("Supplier does not exist")
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if err := tx.QueryRowContext(ctx, `SELECT name FROM suppliers WHERE id = $1`, po.SupplierID).Scan(&po.SupplierName); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM purchase_order_items WHERE purchase_order_id = $1`, po.ID); err != nil {
+			return err
+		}
+
+		return replacePurchaseOrderItems(ctx, tx, po)
+	})
+}
+
+// Stop waiting for whatever has not been delivered yet
+func (s *PurchaseOrderStore) Cancel(ctx context.Context, poID string) (*models.PurchaseOrder, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	var po *models.PurchaseOrder
+
+	err := withTx(ctx, s.db, func(tx *sql.Tx) error {
+		current, err := getPurchaseOrderForUpdate(ctx, tx, poID)
+		if err != nil {
+			return err
+		}
+
+		if current.Status == models.PurchaseOrderReceived || current.Status == models.PurchaseOrderCancelled {
+			return utils.NewConflictError("Purchase order is already " + current.Status)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE purchase_orders
+			SET status = $2, updated_at = NOW()
+			WHERE id = $1
+		`, poID, models.PurchaseOrderCancelled)
+		if err != nil {
+			return err
+		}
+
+		po, err = getPurchaseOrder(ctx, tx, poID)
+		if err != nil {
+			return err
+		}
+
+		return loadPurchaseOrderItems(ctx, tx, po)
+	})
+
+	return po, err
+}
+
+func (s *PurchaseOrderStore) Delete(ctx context.Context, poID string) error {
+	query := `
+		DELETE FROM purchase_orders
+		WHERE id = $1;
+	`
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, poID)
+	if isForeignKeyViolation(err) {
+		return utils.NewConflictError("Goods were already received against this purchase order, cancel it instead")
+	}
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return utils.NewNotFoundError("Purchase order")
+	}
+	return nil
+}
+
+// Book delivered goods into raw material stock at the ordered unit cost
+func (s *PurchaseOrderStore) Receive(ctx context.Context, poID string, gr *models.GoodsReceipt) ([]models.MaterialReceipt, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	receipts := []models.MaterialReceipt{}
+
+	err := withTx(ctx, s.db, func(tx *sql.Tx) error {
+		po, err := getPurchaseOrderForUpdate(ctx, tx, poID)
+		if err != nil {
+			return err
+		}
+
+		if po.Status == models.PurchaseOrderReceived || po.Status == models.PurchaseOrderCancelled {
+			return utils.NewConflictError("Purchase order is already " + po.Status)
+		}
+
+		for _, line := range gr.Lines {
+			var item models.PurchaseOrderItem
+			err := tx.QueryRowContext(ctx, `
+				SELECT id, material_id, quantity, unit_cost, received_quantity
+				FROM purchase_order_items
+				WHERE id = $1 AND purchase_order_id = $2
+			`, line.ItemID, poID).Scan(
+				&item.ID,
+				&item.MaterialID,
+				&item.Quantity,
+				&item.UnitCost,
+				&item.ReceivedQuantity,
+			)
+			if err == sql.ErrNoRows {
+				return utils.NewBadRequestError("Item does not belong to this purchase order")
+			}
+			if err != nil {
+				return err
+			}
+
+			if item.ReceivedQuantity + line.Quantity > item.Quantity {
+				return utils.NewBadRequestError(fmt.Sprintf("Only %g left to receive for item %s", item.Quantity - item.ReceivedQuantity, item.ID))
+			}
+
+			_, err = tx.ExecContext(ctx, `
+				UPDATE purchase_order_items
+				SET received_quantity = received_quantity + $2
+				WHERE id = $1
+			`, item.ID, line.Quantity)
+			if err != nil {
+				return err
+			}
+
+			r := models.MaterialReceipt{
+				MaterialID: item.MaterialID,
+				SupplierID: po.SupplierID,
+				PurchaseOrderItemID: item.ID,
+				Quantity: line.Quantity,
+				UnitCost: item.UnitCost,
+				ReceivedDate: gr.ReceivedDate,
+				Note: gr.Note,
+			}
+			if err := insertMaterialReceipt(ctx, tx, &r); err != nil {
+				return err
+			}
+			receipts = append(receipts, r)
+		}
+
+		// Fully received once every line has arrived
+		_, err = tx.ExecContext(ctx, `
+			UPDATE purchase_orders
+			SET status = CASE
+					WHEN NOT EXISTS (
+						SELECT 1 FROM purchase_order_items
+						WHERE purchase_order_id = $1 AND received_quantity < quantity
+					) THEN $2
+					ELSE $3
+				END,
+				updated_at = NOW()
+			WHERE id = $1
+		`, poID, models.PurchaseOrderReceived, models.PurchaseOrderPartiallyReceived)
+		return err
+	})
+
+	return receipts, err
+}
+
+func (s *PurchaseOrderStore) GetSpendMonthly(ctx context.Context, monthOffset int) ([]models.SupplierSpend, float64, error) {
+	today := time.Now()
+
+	start, end := utils.GetMonthRange(today, monthOffset)
+
+	query := `
+		SELECT
+			sp.id,
+			sp.name,
+			COUNT(r.id) as receipt_count,
+			SUM(r.quantity * r.unit_cost) as total_spend,
+			SUM(SUM(r.quantity * r.unit_cost)) OVER() as grand_total
+		FROM material_receipts r
+		JOIN suppliers sp ON sp.id = r.supplier_id
+		WHERE r.received_date BETWEEN $1 AND $2
+		GROUP BY sp.id, sp.name
+		ORDER BY total_spend DESC
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, start, end)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	spend := []models.SupplierSpend{}
+	var grandTotal float64
+
+	for rows.Next() {
+		var sp models.SupplierSpend
+		if err := rows.Scan(
+			&sp.SupplierID,
+			&sp.SupplierName,
+			&sp.ReceiptCount,
+			&sp.TotalSpend,
+			&grandTotal,
+		); err != nil {
+			return spend, 0, err
+		}
+		spend = append(spend, sp)
+	}
+	if err = rows.Err(); err != nil {
+		return spend, 0, err
+	}
+
+	return spend, grandTotal, nil
+}
+
+func getPurchaseOrder(ctx context.Context, q querier, poID string) (*models.PurchaseOrder, error) {
+	query := `
+		SELECT
+			po.id,
+			po.supplier_id,
+			sp.name,
+			po.order_date,
+			po.status,
+			po.note,
+			po.total_amount,
+			po.created_at,
+			po.updated_at
+		FROM purchase_orders po
+		JOIN suppliers sp ON sp.id = po.supplier_id
+		WHERE po.id = $1
+	`
+
+	var po models.PurchaseOrder
+
+	err := q.QueryRowContext(ctx, query, poID).Scan(
+		&po.ID,
+		&po.SupplierID,
+		&po.SupplierName,
+		&po.OrderDate,
+		&po.Status,
+		&po.Note,
+		&po.TotalAmount,
+		&po.CreatedAt,
+		&po.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("Purchase order")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &po, nil
+}
+
+// Lock the order row so concurrent receipts and edits don't interleave
+func getPurchaseOrderForUpdate(ctx context.Context, q querier, poID string) (*models.PurchaseOrder, error) {
+	var po models.PurchaseOrder
+
+	err := q.QueryRowContext(ctx, `
+		SELECT id, supplier_id, status
+		FROM purchase_orders
+		WHERE id = $1
+		FOR UPDATE
+	`, poID).Scan(
+		&po.ID,
+		&po.SupplierID,
+		&po.Status,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("Purchase order")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &po, nil
+}
+
+// Insert the order's items and store the resulting order total
+func replacePurchaseOrderItems(ctx context.Context, q querier, po *models.PurchaseOrder) error {
+	po.TotalAmount = 0
+
+	for i := range po.Items {
+		item := &po.Items[i]
+		item.ID = uuid.New().String()
+		item.ReceivedQuantity = 0
+
+		err := q.QueryRowContext(ctx, `
+			SELECT name, unit FROM materials WHERE id = $1
+		`, item.MaterialID).Scan(&item.MaterialName, &item.Unit)
+		if err == sql.ErrNoRows {
+			return utils.NewBadRequestError("Material does not exist")
+		}
+		if err != nil {
+			return err
+		}
+
+		_, err = q.ExecContext(ctx, `
+			INSERT INTO purchase_order_items (id, purchase_order_id, material_id, quantity, unit_cost)
+			VALUES ($1, $2, $3, $4, $5)
+		`, item.ID, po.ID, item.MaterialID, item.Quantity, item.UnitCost)
+		if err != nil {
+			return err
+		}
+
+		po.TotalAmount += item.Quantity * item.UnitCost
+	}
+
+	_, err := q.ExecContext(ctx, `
+		UPDATE purchase_orders
+		SET total_amount = $2
+		WHERE id = $1
+	`, po.ID, po.TotalAmount)
+	return err
+}
+
+func loadPurchaseOrderItems(ctx context.Context, q querier, po *models.PurchaseOrder) error {
+	rows, err := q.QueryContext(ctx, `
+		SELECT i.id, i.material_id, m.name, m.unit, i.quantity, i.unit_cost, i.received_quantity
+		FROM purchase_order_items i
+		JOIN materials m ON m.id = i.material_id
+		WHERE i.purchase_order_id = $1
+		ORDER BY m.name ASC
+	`, po.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	po.Items = []models.PurchaseOrderItem{}
+	for rows.Next() {
+		var item models.PurchaseOrderItem
+		if err := rows.Scan(
+			&item.ID,
+			&item.MaterialID,
+			&item.MaterialName,
+			&item.Unit,
+			&item.Quantity,
+			&item.UnitCost,
+			&item.ReceivedQuantity,
+		); err != nil {
+			return err
+		}
+		po.Items = append(po.Items, item)
+	}
+
+	return rows.Err()
+}
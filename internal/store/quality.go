@@ -0,0 +1,284 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kevinbrivio/batako-backend/internal/models"
+	"github.com/kevinbrivio/batako-backend/internal/utils"
+)
+
+type QualityStore struct {
+	db *sql.DB
+}
+
+// Record a test result. Strength is load over the loaded area; the area falls
+// back to the product's length x width when the sample doesn't give one.
+func (s *QualityStore) Create(ctx context.Context, t *models.QualityTest) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	var lengthMM, widthMM int
+	err := s.db.QueryRowContext(ctx, `
+		SELECT $2::date - p.production_date, pr.length_mm, pr.width_mm, pr.strength_class, pr.min_strength
+		FROM productions p
+		JOIN products pr ON pr.id = p.product_id
+		WHERE p.id = $1
+	`, t.ProductionID, t.TestDate).Scan(&t.AgeDays, &lengthMM, &widthMM, &t.StrengthClass, &t.MinStrength)
+	if err == sql.ErrNoRows {
+		return utils.NewNotFoundError("Production")
+	}
+	if err != nil {
+		return err
+	}
+
+	if t.AgeDays < 0 {
+		return utils.NewBadRequestError("Test date cannot be before the production date")
+	}
+
+	if t.MinStrength <= 0 {
+		return utils.NewBadRequestError("Set a minimum strength on the product before recording tests")
+	}
+
+	if t.AreaMM2 == 0 {
+		t.AreaMM2 = float64(lengthMM * widthMM)
+	}
+
+	if t.AreaMM2 <= 0 {
+		return utils.NewBadRequestError("Sample area is required when the product has no dimensions")
+	}
+
+	// kN over mm2 gives N/mm2, i.e. MPa
+	t.StrengthMPa = t.LoadKN * 1000 / t.AreaMM2
+	t.Passed = t.StrengthMPa >= t.MinStrength
+	t.ID = uuid.New().String()
+
+	query := `
+		INSERT INTO quality_tests (id, production_id, sample_code, test_date, age_days, load_kn, area_mm2, strength_mpa, strength_class, min_strength, passed, tested_by, note)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING created_at
+	`
+
+	return s.db.QueryRowContext(
+		ctx,
+		query,
+		t.ID,
+		t.ProductionID,
+		t.SampleCode,
+		t.TestDate,
+		t.AgeDays,
+		t.LoadKN,
+		t.AreaMM2,
+		t.StrengthMPa,
+		t.StrengthClass,
+		t.MinStrength,
+		t.Passed,
+		t.TestedBy,
+		t.Note,
+	).Scan(&t.CreatedAt)
+}
+
+// Test history of a batch, oldest first
+func (s *QualityStore) GetByProduction(ctx context.Context, pID string) (*models.BatchQuality, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	return getBatchQuality(ctx, s.db, pID)
+}
+
+func (s *QualityStore) Delete(ctx context.Context, pID, tID string) error {
+	query := `
+		DELETE FROM quality_tests
+		WHERE id = $1 AND production_id = $2
+	`
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, tID, pID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return utils.NewNotFoundError("Quality test")
+	}
+	return nil
+}
+
+// Attach a tested batch to a sale so its certificate goes out with the invoice
+func (s *QualityStore) Attach(ctx context.Context, tID, pID string) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	var onSale, tested bool
+	err := s.db.QueryRowContext(ctx, `
+		SELECT
+			EXISTS (SELECT 1 FROM transaction_items i WHERE i.transaction_id = $1 AND i.product_id = p.product_id),
+			EXISTS (SELECT 1 FROM quality_tests q WHERE q.production_id = p.id)
+		FROM productions p
+		WHERE p.id = $2
+	`, tID, pID).Scan(&onSale, &tested)
+	if err == sql.ErrNoRows {
+		return utils.NewBadRequestError("Production does not exist")
+	}
+	if err != nil {
+		return err
+	}
+
+	if !tested {
+		return utils.NewBadRequestError("Batch has no quality tests yet")
+	}
+
+	if !onSale {
+		return utils.NewBadRequestError("Batch is not for a product on this sale")
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO transaction_certificates (transaction_id, production_id)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`, tID, pID)
+	if isForeignKeyViolation(err) {
+		return utils.NewNotFoundError("Transaction")
+	}
+	return err
+}
+
+func (s *QualityStore) Detach(ctx context.Context, tID, pID string) error {
+	query := `
+		DELETE FROM transaction_certificates
+		WHERE transaction_id = $1 AND production_id = $2
+	`
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, tID, pID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return utils.NewNotFoundError("Certificate")
+	}
+	return nil
+}
+
+// Batches attached to a sale, in the order they were attached
+func (s *QualityStore) GetByTransaction(ctx context.Context, tID string) ([]models.BatchQuality, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT production_id
+		FROM transaction_certificates
+		WHERE transaction_id = $1
+		ORDER BY created_at ASC
+	`, tID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	batches := []models.BatchQuality{}
+	for _, id := range ids {
+		b, err := getBatchQuality(ctx, s.db, id)
+		if err != nil {
+			return batches, err
+		}
+		batches = append(batches, *b)
+	}
+
+	return batches, nil
+}
+
+func getBatchQuality(ctx context.Context, q querier, pID string) (*models.BatchQuality, error) {
+	var b models.BatchQuality
+	err := q.QueryRowContext(ctx, `
+		SELECT p.id, pr.id, pr.name, p.production_date, p.quantity, pr.strength_class, pr.min_strength
+		FROM productions p
+		JOIN products pr ON pr.id = p.product_id
+		WHERE p.id = $1
+	`, pID).Scan(&b.ProductionID, &b.ProductID, &b.Product, &b.ProductionDate, &b.Quantity, &b.StrengthClass, &b.MinStrength)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("Production")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := q.QueryContext(ctx, `
+		SELECT id, production_id, sample_code, test_date, age_days, load_kn, area_mm2, strength_mpa, strength_class, min_strength, passed, tested_by, note, created_at
+		FROM quality_tests
+		WHERE production_id = $1
+		ORDER BY test_date ASC, created_at ASC
+	`, pID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	b.Tests = []models.QualityTest{}
+	b.Passed = true
+	var total float64
+
+	for rows.Next() {
+		var t models.QualityTest
+		if err := rows.Scan(
+			&t.ID,
+			&t.ProductionID,
+			&t.SampleCode,
+			&t.TestDate,
+			&t.AgeDays,
+			&t.LoadKN,
+			&t.AreaMM2,
+			&t.StrengthMPa,
+			&t.StrengthClass,
+			&t.MinStrength,
+			&t.Passed,
+			&t.TestedBy,
+			&t.Note,
+			&t.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		total += t.StrengthMPa
+		b.Passed = b.Passed && t.Passed
+		b.Tests = append(b.Tests, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	b.TestCount = len(b.Tests)
+	if b.TestCount > 0 {
+		b.AverageStrength = total / float64(b.TestCount)
+	} else {
+		b.Passed = false
+	}
+
+	return &b, nil
+}
\ No newline at end of file
@@ -0,0 +1,476 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/kevinbrivio/batako-backend/internal/models"
+	"github.com/kevinbrivio/batako-backend/internal/utils"
+)
+
+type ReportStore struct {
+	db *sql.DB
+}
+
+// Unpaid balances per customer as they stood at the end of asOf, bucketed by
+// the age of each sale
+func (s *ReportStore) GetReceivablesAging(ctx context.Context, asOf time.Time) ([]models.ReceivableAging, error) {
+	query := `
+		SELECT
+			o.customer_id,
+			c.name,
+			COUNT(*) as transaction_count,
+			COALESCE(SUM(o.balance) FILTER (WHERE o.age_days <= 30), 0) as days_0_30,
+			COALESCE(SUM(o.balance) FILTER (WHERE o.age_days BETWEEN 31 AND 60), 0) as days_31_60,
+			COALESCE(SUM(o.balance) FILTER (WHERE o.age_days BETWEEN 61 AND 90), 0) as days_61_90,
+			COALESCE(SUM(o.balance) FILTER (WHERE o.age_days > 90), 0) as days_over_90,
+			SUM(o.balance) as total
+		FROM (
+			SELECT
+				t.customer_id,
+				t.total_price - COALESCE(p.paid_amount, 0) as balance,
+				$1::date - t.purchase_date as age_days
+			FROM transactions t
+			LEFT JOIN (
+				SELECT transaction_id, SUM(amount) as paid_amount
+				FROM payments
+				WHERE payment_date <= $1::date
+				GROUP BY transaction_id
+			) p ON p.transaction_id = t.id
+			WHERE t.purchase_date <= $1::date
+		) o
+		JOIN customers c ON c.id = o.customer_id
+		WHERE o.balance > 0
+		GROUP BY o.customer_id, c.name
+		ORDER BY total DESC
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, asOf)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	aging := []models.ReceivableAging{}
+
+	for rows.Next() {
+		var a models.ReceivableAging
+		if err := rows.Scan(
+			&a.CustomerID,
+			&a.Customer,
+			&a.TransactionCount,
+			&a.Days0To30,
+			&a.Days31To60,
+			&a.Days61To90,
+			&a.Over90,
+			&a.Total,
+		); err != nil {
+			return aging, err
+		}
+		aging = append(aging, a)
+	}
+	if err = rows.Err(); err != nil {
+		return aging, err
+	}
+
+	return aging, nil
+}
+
+// Outstanding sales of a single customer behind their aging row, oldest first
+func (s *ReportStore) GetCustomerReceivables(ctx context.Context, cID string, asOf time.Time) ([]models.ReceivableItem, error) {
+	query := `
+		SELECT
+			t.id,
+			t.purchase_date,
+			t.quantity,
+			t.total_price,
+			COALESCE(p.paid_amount, 0) as paid_amount,
+			t.total_price - COALESCE(p.paid_amount, 0) as balance,
+			$2::date - t.purchase_date as age_days
+		FROM transactions t
+		LEFT JOIN (
+			SELECT transaction_id, SUM(amount) as paid_amount
+			FROM payments
+			WHERE payment_date <= $2::date
+			GROUP BY transaction_id
+		) p ON p.transaction_id = t.id
+		WHERE t.customer_id = $1
+			AND t.purchase_date <= $2::date
+			AND t.total_price - COALESCE(p.paid_amount, 0) > 0
+		ORDER BY t.purchase_date ASC
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, cID, asOf)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []models.ReceivableItem{}
+
+	for rows.Next() {
+		var i models.ReceivableItem
+		if err := rows.Scan(
+			&i.TransactionID,
+			&i.PurchaseDate,
+			&i.Quantity,
+			&i.TotalPrice,
+			&i.PaidAmount,
+			&i.Balance,
+			&i.AgeDays,
+		); err != nil {
+			return items, err
+		}
+		i.Bucket = agingBucket(i.AgeDays)
+		items = append(items, i)
+	}
+	if err = rows.Err(); err != nil {
+		return items, err
+	}
+
+	return items, nil
+}
+
+// Recipe against actual material use for every batch produced between from
+// and to, plus a monthly roll-up. Cement comes from cement_used, the other
+// materials from what was booked on the production. Batches using more than
+// tolerance percent over the recipe are flagged. Expected amounts use the
+// product's current recipe.
+func (s *ReportStore) GetMaterialVariance(ctx context.Context, from, to time.Time, materialCode string, tolerance float64) ([]models.MaterialVariance, []models.MaterialVarianceMonth, error) {
+	query := `
+		SELECT
+			p.id,
+			p.production_date,
+			pr.id,
+			pr.name,
+			p.quantity,
+			m.id,
+			m.name,
+			m.unit,
+			p.quantity * r.quantity_per_unit as expected,
+			CASE WHEN m.code = $3 THEN p.cement_used ELSE COALESCE(pm.quantity, 0) END as actual
+		FROM productions p
+		JOIN products pr ON pr.id = p.product_id
+		JOIN product_recipes r ON r.product_id = p.product_id
+		JOIN materials m ON m.id = r.material_id
+		LEFT JOIN (
+			SELECT production_id, material_id, SUM(quantity) as quantity
+			FROM production_materials
+			GROUP BY production_id, material_id
+		) pm ON pm.production_id = p.id AND pm.material_id = r.material_id
+		WHERE p.production_date BETWEEN $1::date AND $2::date
+			AND ($4 = '' OR m.code = $4)
+		ORDER BY p.production_date ASC, p.created_at ASC, m.name ASC
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, from, to, models.MaterialCodeCement, materialCode)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	batches := []models.MaterialVariance{}
+
+	for rows.Next() {
+		var v models.MaterialVariance
+		if err := rows.Scan(
+			&v.ProductionID,
+			&v.ProductionDate,
+			&v.ProductID,
+			&v.Product,
+			&v.Quantity,
+			&v.MaterialID,
+			&v.Material,
+			&v.Unit,
+			&v.Expected,
+			&v.Actual,
+		); err != nil {
+			return batches, nil, err
+		}
+		v.Variance = v.Actual - v.Expected
+		v.VariancePercent = variancePercent(v.Variance, v.Expected)
+		v.Flagged = v.VariancePercent > tolerance
+		batches = append(batches, v)
+	}
+	if err = rows.Err(); err != nil {
+		return batches, nil, err
+	}
+
+	months := []models.MaterialVarianceMonth{}
+	index := map[string]int{}
+	for _, v := range batches {
+		key := v.ProductionDate.Format("2006-01") + "/" + v.MaterialID
+		i, ok := index[key]
+		if !ok {
+			months = append(months, models.MaterialVarianceMonth{
+				Month: v.ProductionDate.Format("2006-01"),
+				MaterialID: v.MaterialID,
+				Material: v.Material,
+				Unit: v.Unit,
+			})
+			i = len(months) - 1
+			index[key] = i
+		}
+
+		m := &months[i]
+		m.ProductionCount++
+		if v.Flagged {
+			m.FlaggedCount++
+		}
+		m.Expected += v.Expected
+		m.Actual += v.Actual
+	}
+
+	for i := range months {
+		months[i].Variance = months[i].Actual - months[i].Expected
+		months[i].VariancePercent = variancePercent(months[i].Variance, months[i].Expected)
+	}
+
+	return batches, months, nil
+}
+
+func variancePercent(variance, expected float64) float64 {
+	if expected == 0 {
+		return 0
+	}
+	return variance / expected * 100
+}
+
+func agingBucket(ageDays int) string {
+	switch {
+	case ageDays <= 30:
+		return models.AgingBucket0To30
+	case ageDays <= 60:
+		return models.AgingBucket31To60
+	case ageDays <= 90:
+		return models.AgingBucket61To90
+	default:
+		return models.AgingBucketOver90
+	}
+}
+
+
+// Profit and loss per month between from and to, plus the totals. Materials are
+// valued at the average purchase cost of their receipts up to the end of the
+// month they were used in; materials never bought at a cost are listed apart.
+// Labour is the piece pay and wages the payroll computes for each month, so
+// expenses booked to the Wages account are left out rather than counted twice.
+func (s *ReportStore) GetProfitLoss(ctx context.Context, from, to time.Time) ([]models.ProfitLossMonth, models.ProfitLossMonth, []string, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 10)
+	defer cancel()
+
+	_, end := utils.GetDayRange(to)
+
+	months := []models.ProfitLossMonth{}
+	index := map[string]int{}
+	for m := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, from.Location()); !m.After(to); m = m.AddDate(0, 1, 0) {
+		index[m.Format("2006-01")] = len(months)
+		months = append(months, models.ProfitLossMonth{Month: m.Format("2006-01"), ExpenseCategories: []models.ExpenseCategorySummary{}})
+
+		// Only the part of the first and last month inside the range
+		start, monthEnd := utils.GetMonthRange(m, 0)
+		if start.Before(from) {
+			start = from
+		}
+		if monthEnd.After(end) {
+			monthEnd = end
+		}
+
+		run, err := computePayroll(ctx, s.db, m.Format("2006-01"), start, monthEnd)
+		if err != nil {
+			return nil, models.ProfitLossMonth{}, nil, err
+		}
+		months[len(months) - 1].Labour = run.Total
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT to_char(purchase_date, 'YYYY-MM'), SUM(subtotal), SUM(quantity)
+		FROM transactions
+		WHERE purchase_date BETWEEN $1::date AND $2::date
+		GROUP BY 1
+	`, from, to)
+	if err != nil {
+		return nil, models.ProfitLossMonth{}, nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var month string
+		var revenue float64
+		var sold int
+		if err := rows.Scan(&month, &revenue, &sold); err != nil {
+			return nil, models.ProfitLossMonth{}, nil, err
+		}
+		if i, ok := index[month]; ok {
+			months[i].Revenue, months[i].BlocksSold = revenue, sold
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, models.ProfitLossMonth{}, nil, err
+	}
+
+	materialRows, err := s.db.QueryContext(ctx, `
+		SELECT to_char(c.month_start, 'YYYY-MM'), m.name, m.code = $3, c.quantity, COALESCE(cost.unit_cost, 0), cost.unit_cost IS NULL
+		FROM (
+			SELECT date_trunc('month', p.production_date) as month_start, mv.material_id, SUM(-mv.quantity) as quantity
+			FROM material_movements mv
+			JOIN productions p ON p.id = mv.source_id
+			WHERE mv.source_type = 'production' AND p.production_date BETWEEN $1::date AND $2::date
+			GROUP BY 1, 2
+		) c
+		JOIN materials m ON m.id = c.material_id
+		LEFT JOIN LATERAL (
+			SELECT SUM(r.quantity * r.unit_cost) / NULLIF(SUM(r.quantity), 0) as unit_cost
+			FROM material_receipts r
+			WHERE r.material_id = c.material_id AND r.unit_cost > 0
+				AND r.received_date < c.month_start + interval '1 month'
+		) cost ON TRUE
+		WHERE c.quantity <> 0
+	`, from, to, models.MaterialCodeCement)
+	if err != nil {
+		return nil, models.ProfitLossMonth{}, nil, err
+	}
+	defer materialRows.Close()
+
+	unpriced := []string{}
+	seen := map[string]bool{}
+	for materialRows.Next() {
+		var month, material string
+		var isCement, noCost bool
+		var quantity, unitCost float64
+		if err := materialRows.Scan(&month, &material, &isCement, &quantity, &unitCost, &noCost); err != nil {
+			return nil, models.ProfitLossMonth{}, nil, err
+		}
+
+		if noCost && !seen[material] {
+			seen[material] = true
+			unpriced = append(unpriced, material)
+		}
+
+		i, ok := index[month]
+		if !ok {
+			continue
+		}
+		if isCement {
+			months[i].CementCost += quantity * unitCost
+		} else {
+			months[i].MaterialCost += quantity * unitCost
+		}
+	}
+	if err := materialRows.Err(); err != nil {
+		return nil, models.ProfitLossMonth{}, nil, err
+	}
+
+	producedRows, err := s.db.QueryContext(ctx, `
+		SELECT to_char(p.production_date, 'YYYY-MM'), SUM(p.quantity - COALESCE(rj.rejected, 0))
+		FROM productions p
+		LEFT JOIN (
+			SELECT production_id, SUM(quantity) as rejected
+			FROM production_rejects
+			GROUP BY production_id
+		) rj ON rj.production_id = p.id
+		WHERE p.production_date BETWEEN $1::date AND $2::date
+		GROUP BY 1
+	`, from, to)
+	if err != nil {
+		return nil, models.ProfitLossMonth{}, nil, err
+	}
+	defer producedRows.Close()
+
+	for producedRows.Next() {
+		var month string
+		var produced int
+		if err := producedRows.Scan(&month, &produced); err != nil {
+			return nil, models.ProfitLossMonth{}, nil, err
+		}
+		if i, ok := index[month]; ok {
+			months[i].BlocksProduced = produced
+		}
+	}
+	if err := producedRows.Err(); err != nil {
+		return nil, models.ProfitLossMonth{}, nil, err
+	}
+
+	expenseRows, err := s.db.QueryContext(ctx, `
+		SELECT to_char(x.expense_date, 'YYYY-MM'), c.id, c.name, COUNT(*), SUM(x.amount)
+		FROM expenses x
+		JOIN expense_categories c ON c.id = x.category_id
+		LEFT JOIN accounts a ON a.id = c.account_id
+		WHERE x.expense_date BETWEEN $1::date AND $2::date AND a.code IS DISTINCT FROM $3
+		GROUP BY 1, c.id, c.name
+		ORDER BY 1, c.name ASC
+	`, from, to, models.AccountCodeWages)
+	if err != nil {
+		return nil, models.ProfitLossMonth{}, nil, err
+	}
+	defer expenseRows.Close()
+
+	total := models.ProfitLossMonth{ExpenseCategories: []models.ExpenseCategorySummary{}}
+	totalCategories := map[string]int{}
+	for expenseRows.Next() {
+		var month string
+		var c models.ExpenseCategorySummary
+		if err := expenseRows.Scan(&month, &c.CategoryID, &c.Category, &c.Count, &c.Amount); err != nil {
+			return nil, models.ProfitLossMonth{}, nil, err
+		}
+
+		i, ok := index[month]
+		if !ok {
+			continue
+		}
+		months[i].Expenses += c.Amount
+		months[i].ExpenseCategories = append(months[i].ExpenseCategories, c)
+
+		j, ok := totalCategories[c.CategoryID]
+		if !ok {
+			j = len(total.ExpenseCategories)
+			totalCategories[c.CategoryID] = j
+			total.ExpenseCategories = append(total.ExpenseCategories, models.ExpenseCategorySummary{CategoryID: c.CategoryID, Category: c.Category})
+		}
+		total.ExpenseCategories[j].Count += c.Count
+		total.ExpenseCategories[j].Amount += c.Amount
+	}
+	if err := expenseRows.Err(); err != nil {
+		return nil, models.ProfitLossMonth{}, nil, err
+	}
+
+	for i := range months {
+		m := &months[i]
+		total.Revenue += m.Revenue
+		total.BlocksSold += m.BlocksSold
+		total.CementCost += m.CementCost
+		total.MaterialCost += m.MaterialCost
+		total.Labour += m.Labour
+		total.Expenses += m.Expenses
+		total.BlocksProduced += m.BlocksProduced
+		setProfitLoss(m)
+	}
+	setProfitLoss(&total)
+
+	return months, total, unpriced, nil
+}
+
+func setProfitLoss(m *models.ProfitLossMonth) {
+	production := m.CementCost + m.MaterialCost + m.Labour
+
+	m.GrossProfit = m.Revenue - production
+	m.NetProfit = m.GrossProfit - m.Expenses
+	m.GrossMargin = variancePercent(m.GrossProfit, m.Revenue)
+	m.NetMargin = variancePercent(m.NetProfit, m.Revenue)
+
+	m.UnitCost, m.FullUnitCost = 0, 0
+	if m.BlocksProduced > 0 {
+		m.UnitCost = production / float64(m.BlocksProduced)
+		m.FullUnitCost = (production + m.Expenses) / float64(m.BlocksProduced)
+	}
+}
\ No newline at end of file
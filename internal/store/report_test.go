@@ -0,0 +1,66 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/kevinbrivio/batako-backend/internal/models"
+)
+
+func TestAgingBucket(t *testing.T) {
+	tests := []struct {
+		ageDays int
+		want    string
+	}{
+		{0, models.AgingBucket0To30},
+		{30, models.AgingBucket0To30},
+		{31, models.AgingBucket31To60},
+		{60, models.AgingBucket31To60},
+		{61, models.AgingBucket61To90},
+		{90, models.AgingBucket61To90},
+		{91, models.AgingBucketOver90},
+		{400, models.AgingBucketOver90},
+	}
+
+	for _, tt := range tests {
+		if got := agingBucket(tt.ageDays); got != tt.want {
+			t.Errorf("agingBucket(%d) = %q, want %q", tt.ageDays, got, tt.want)
+		}
+	}
+}
+
+func TestSetProfitLoss(t *testing.T) {
+	tests := []struct {
+		name string
+		in   models.ProfitLossMonth
+		want models.ProfitLossMonth
+	}{
+		{
+			name: "profitable month",
+			in:   models.ProfitLossMonth{Revenue: 10000000, CementCost: 3000000, MaterialCost: 1000000, Labour: 2000000, Expenses: 1000000, BlocksProduced: 2000},
+			want: models.ProfitLossMonth{GrossProfit: 4000000, NetProfit: 3000000, GrossMargin: 40, NetMargin: 30, UnitCost: 3000, FullUnitCost: 3500},
+		},
+		{
+			name: "no sales",
+			in:   models.ProfitLossMonth{CementCost: 500000, Labour: 500000, Expenses: 200000, BlocksProduced: 500},
+			want: models.ProfitLossMonth{GrossProfit: -1000000, NetProfit: -1200000, UnitCost: 2000, FullUnitCost: 2400},
+		},
+		{
+			name: "nothing produced",
+			in:   models.ProfitLossMonth{Revenue: 1000000, Expenses: 100000, UnitCost: 99, FullUnitCost: 99},
+			want: models.ProfitLossMonth{GrossProfit: 1000000, NetProfit: 900000, GrossMargin: 100, NetMargin: 90},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := tt.in
+			setProfitLoss(&m)
+
+			got := [6]float64{m.GrossProfit, m.NetProfit, m.GrossMargin, m.NetMargin, m.UnitCost, m.FullUnitCost}
+			want := [6]float64{tt.want.GrossProfit, tt.want.NetProfit, tt.want.GrossMargin, tt.want.NetMargin, tt.want.UnitCost, tt.want.FullUnitCost}
+			if got != want {
+				t.Errorf("gross profit, net profit, margins and unit costs = %v, want %v", got, want)
+			}
+		})
+	}
+}
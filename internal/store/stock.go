@@ -0,0 +1,238 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kevinbrivio/batako-backend/internal/models"
+	"github.com/kevinbrivio/batako-backend/internal/utils"
+)
+
+type StockStore struct {
+	db *sql.DB
+}
+
+// Stock of every product, including the ones with nothing in the yard
+func (s *StockStore) GetLevels(ctx context.Context) ([]models.StockLevel, error) {
+	query := `
+		SELECT
+			pr.id,
+			pr.name,
+			COALESCE(SUM(m.quantity), 0) as on_hand,
+			COALESCE(SUM(m.quantity) FILTER (WHERE ` + curedMovement + `), 0) as cured,
+			COALESCE(SUM(m.quantity) FILTER (WHERE m.quantity > 0), 0) as total_in,
+			COALESCE(-SUM(m.quantity) FILTER (WHERE m.quantity < 0), 0) as total_out,
+			MAX(m.created_at) as last_movement_at
+		FROM products pr
+		LEFT JOIN stock_movements m ON m.product_id = pr.id
+		LEFT JOIN productions p ON m.source_type = 'production' AND p.id = m.source_id
+		GROUP BY pr.id, pr.name
+		ORDER BY pr.name ASC
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	levels := []models.StockLevel{}
+
+	for rows.Next() {
+		var l models.StockLevel
+		var lastMovementAt sql.NullTime
+		if err := rows.Scan(
+			&l.ProductID,
+			&l.ProductName,
+			&l.OnHand,
+			&l.Cured,
+			&l.TotalIn,
+			&l.TotalOut,
+			&lastMovementAt,
+		); err != nil {
+			return levels, err
+		}
+
+		l.Curing = l.OnHand - l.Cured
+
+		if lastMovementAt.Valid {
+			l.LastMovementAt = &lastMovementAt.Time
+		}
+		levels = append(levels, l)
+	}
+	if err = rows.Err(); err != nil {
+		return levels, err
+	}
+
+	return levels, nil
+}
+
+func (s *StockStore) GetMovements(ctx context.Context, limit, offset int) ([]models.StockMovement, int, error) {
+	query := `
+		SELECT
+			m.id,
+			m.product_id,
+			pr.name,
+			m.movement_date,
+			m.movement_type,
+			m.source_type,
+			m.source_id,
+			m.quantity,
+			m.note,
+			COUNT(*) OVER() as total_count,
+			m.created_at
+		FROM stock_movements m
+		JOIN products pr ON pr.id = m.product_id
+		ORDER BY m.created_at DESC
+		LIMIT $1 OFFSET $2
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	movements := []models.StockMovement{}
+	var totalCount int
+
+	for rows.Next() {
+		var m models.StockMovement
+		if err := rows.Scan(
+			&m.ID,
+			&m.ProductID,
+			&m.ProductName,
+			&m.MovementDate,
+			&m.MovementType,
+			&m.SourceType,
+			&m.SourceID,
+			&m.Quantity,
+			&m.Note,
+			&totalCount,
+			&m.CreatedAt,
+		); err != nil {
+			return movements, 0, err
+		}
+		movements = append(movements, m)
+	}
+	if err = rows.Err(); err != nil {
+		return movements, 0, err
+	}
+
+	return movements, totalCount, nil
+}
+
+// Serialise stock-changing transactions so availability checks can't race each other
+func lockStockLedger(ctx context.Context, q querier) error {
+	_, err := q.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext('stock_movements'))`)
+	return err
+}
+
+func postStockMovement(ctx context.Context, q querier, m *models.StockMovement) error {
+	m.ID = uuid.New().String()
+
+	m.MovementType = models.StockIn
+	if m.Quantity < 0 {
+		m.MovementType = models.StockOut
+	}
+
+	query := `
+		INSERT INTO stock_movements (id, product_id, movement_date, movement_type, source_type, source_id, quantity, note)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING created_at
+	`
+
+	return q.QueryRowContext(
+		ctx,
+		query,
+		m.ID,
+		m.ProductID,
+		m.MovementDate,
+		m.MovementType,
+		m.SourceType,
+		m.SourceID,
+		m.Quantity,
+		m.Note,
+	).Scan(&m.CreatedAt)
+}
+
+// Post movements cancelling whatever a source has contributed so far, per product.
+// The ledger is append-only, so edits and deletes show up in the history instead
+// of rewriting it.
+func reverseStockMovements(ctx context.Context, q querier, sourceType, sourceID, note string) error {
+	rows, err := q.QueryContext(ctx, `
+		SELECT product_id, SUM(quantity)
+		FROM stock_movements
+		WHERE source_type = $1 AND source_id = $2
+		GROUP BY product_id
+		HAVING SUM(quantity) <> 0
+	`, sourceType, sourceID)
+	if err != nil {
+		return err
+	}
+
+	reversals := []models.StockMovement{}
+	for rows.Next() {
+		m := models.StockMovement{
+			MovementDate: time.Now(),
+			SourceType: sourceType,
+			SourceID: sourceID,
+			Note: note,
+		}
+		var net int
+		if err := rows.Scan(&m.ProductID, &net); err != nil {
+			rows.Close()
+			return err
+		}
+		m.Quantity = -net
+		reversals = append(reversals, m)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for i := range reversals {
+		if err := postStockMovement(ctx, q, &reversals[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Blocks can only be sold once cured, so production counts from its ready date
+// on. Everything else (sales, reversals of deleted batches) counts right away.
+const curedMovement = `p.id IS NULL OR p.ready_date <= $1::date`
+
+func ensureStockAvailable(ctx context.Context, q querier, productID string, quantity int) error {
+	var onHand, cured int
+	err := q.QueryRowContext(ctx, `
+		SELECT
+			COALESCE(SUM(m.quantity), 0),
+			COALESCE(SUM(m.quantity) FILTER (WHERE `+curedMovement+`), 0)
+		FROM stock_movements m
+		LEFT JOIN productions p ON m.source_type = 'production' AND p.id = m.source_id
+		WHERE m.product_id = $2
+	`, time.Now(), productID).Scan(&onHand, &cured)
+	if err != nil {
+		return err
+	}
+
+	if quantity > onHand {
+		return utils.NewConflictError(fmt.Sprintf("Insufficient stock: %d available, %d requested", onHand, quantity))
+	}
+
+	if quantity > cured {
+		return utils.NewConflictError(fmt.Sprintf("Insufficient cured stock: %d cured, %d requested, %d still curing", cured, quantity, onHand - cured))
+	}
+	return nil
+}
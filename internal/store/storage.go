@@ -3,36 +3,288 @@ package store
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"time"
 
 	"github.com/kevinbrivio/batako-backend/internal/models"
+	"github.com/lib/pq"
 )
 
 type Storage struct {
 	Production interface {
 		Create(context.Context, *models.Production) error
 		GetAll(context.Context, int, int) ([]models.Production, int, error)
-		GetAllMonthly(context.Context, int) ([]models.Production, int, int, error)
+		GetAllMonthly(context.Context, int) ([]models.Production, models.ProductionSummary, error)
 		GetByID(context.Context, string) (*models.Production, error)
 		Update(context.Context, *models.Production) error
 		Delete(context.Context, string) error
+		GetCuringSchedule(context.Context, string, time.Time, int) ([]models.CuringDay, int, error)
+		AddReject(context.Context, *models.ProductionReject) error
+		DeleteReject(context.Context, string, string) error
+		GetYield(context.Context, time.Time, time.Time) ([]models.YieldMonth, error)
 	}
 	Transaction interface {
 		Create(context.Context, *models.Transaction) error
 		GetAll(context.Context, int, int) ([]models.Transaction, int, error)
 		GetAllWeekly(context.Context, int) ([]models.Transaction, int, error)
-		GetAllDaily(context.Context, time.Time) ([]models.Transaction, int, int, float64, error)
-		GetAllMonthly(context.Context, int) ([]models.Transaction, int, int, float64, error)
+		GetAllDaily(context.Context, time.Time) ([]models.Transaction, models.TransactionSummary, error)
+		GetAllMonthly(context.Context, int) ([]models.Transaction, models.TransactionSummary, error)
 		GetByID(context.Context, string) (*models.Transaction, error)
 		Update(context.Context, *models.Transaction) error
 		Delete(context.Context, string) error
 		GetTotalWeeks(ctx context.Context) (int, error)
 	}
+	PriceList interface {
+		Create(context.Context, *models.PriceList) error
+		GetAll(context.Context, string, int, int) ([]models.PriceList, int, error)
+		GetByID(context.Context, string) (*models.PriceList, error)
+		GetEffective(context.Context, string, time.Time) (*models.PriceList, error)
+		Update(context.Context, *models.PriceList) error
+		Delete(context.Context, string) error
+	}
+	TaxRate interface {
+		Create(context.Context, *models.TaxRate) error
+		GetAll(context.Context, int, int) ([]models.TaxRate, int, error)
+		GetByID(context.Context, string) (*models.TaxRate, error)
+		GetEffective(context.Context, time.Time) (*models.TaxRate, error)
+		Update(context.Context, *models.TaxRate) error
+		Delete(context.Context, string) error
+	}
+	Customer interface {
+		Create(context.Context, *models.Customer) error
+		GetAll(context.Context, string, int, int) ([]models.Customer, int, error)
+		GetByID(context.Context, string) (*models.Customer, error)
+		Update(context.Context, *models.Customer) error
+		Delete(context.Context, string) error
+		AddAddress(context.Context, *models.CustomerAddress) error
+		UpdateAddress(context.Context, *models.CustomerAddress) error
+		DeleteAddress(context.Context, string, string) error
+		AddPhone(context.Context, *models.CustomerPhone) error
+		DeletePhone(context.Context, string, string) error
+	}
+	Stock interface {
+		GetLevels(context.Context) ([]models.StockLevel, error)
+		GetMovements(context.Context, int, int) ([]models.StockMovement, int, error)
+	}
+	Material interface {
+		Create(context.Context, *models.Material) error
+		GetAll(context.Context, int, int) ([]models.Material, int, error)
+		GetLowStock(context.Context) ([]models.Material, error)
+		GetByID(context.Context, string) (*models.Material, error)
+		Update(context.Context, *models.Material) error
+		Delete(context.Context, string) error
+		CreateReceipt(context.Context, *models.MaterialReceipt) error
+		GetReceipts(context.Context, string, int, int) ([]models.MaterialReceipt, int, error)
+		GetMovements(context.Context, string, int, int) ([]models.MaterialMovement, int, error)
+	}
+	Supplier interface {
+		Create(context.Context, *models.Supplier) error
+		GetAll(context.Context, int, int) ([]models.Supplier, int, error)
+		GetByID(context.Context, string) (*models.Supplier, error)
+		Update(context.Context, *models.Supplier) error
+		Delete(context.Context, string) error
+	}
+	PurchaseOrder interface {
+		Create(context.Context, *models.PurchaseOrder) error
+		GetAll(context.Context, string, int, int) ([]models.PurchaseOrder, int, error)
+		GetByID(context.Context, string) (*models.PurchaseOrder, error)
+		Update(context.Context, *models.PurchaseOrder) error
+		Cancel(context.Context, string) (*models.PurchaseOrder, error)
+		Delete(context.Context, string) error
+		Receive(context.Context, string, *models.GoodsReceipt) ([]models.MaterialReceipt, error)
+		GetSpendMonthly(context.Context, int) ([]models.SupplierSpend, float64, error)
+	}
+	Payment interface {
+		Create(context.Context, *models.Payment) error
+		GetByTransaction(context.Context, string) ([]models.Payment, error)
+		Delete(context.Context, string, string) error
+	}
+	Report interface {
+		GetReceivablesAging(context.Context, time.Time) ([]models.ReceivableAging, error)
+		GetCustomerReceivables(context.Context, string, time.Time) ([]models.ReceivableItem, error)
+		GetMaterialVariance(context.Context, time.Time, time.Time, string, float64) ([]models.MaterialVariance, []models.MaterialVarianceMonth, error)
+		GetProfitLoss(context.Context, time.Time, time.Time) ([]models.ProfitLossMonth, models.ProfitLossMonth, []string, error)
+	}
+	Quality interface {
+		Create(context.Context, *models.QualityTest) error
+		GetByProduction(context.Context, string) (*models.BatchQuality, error)
+		Delete(context.Context, string, string) error
+		Attach(context.Context, string, string) error
+		Detach(context.Context, string, string) error
+		GetByTransaction(context.Context, string) ([]models.BatchQuality, error)
+	}
+	Invoice interface {
+		Issue(context.Context, string) (*models.Invoice, error)
+		GetByTransaction(context.Context, string) (*models.Invoice, error)
+	}
+	Delivery interface {
+		Create(context.Context, *models.Delivery) error
+		GetAll(context.Context, string, string, int, int) ([]models.Delivery, int, error)
+		GetByTransaction(context.Context, string) ([]models.Delivery, error)
+		GetByID(context.Context, string) (*models.Delivery, error)
+		Update(context.Context, *models.Delivery) error
+		UpdateStatus(context.Context, string, *models.DeliveryStatusUpdate) (*models.Delivery, error)
+		Delete(context.Context, string) error
+	}
+	DeliveryProof interface {
+		Create(context.Context, *models.DeliveryProof) error
+		GetByTransaction(context.Context, string) ([]models.DeliveryProof, error)
+		GetByID(context.Context, string, string) (*models.DeliveryProof, error)
+		Delete(context.Context, string, string) (*models.DeliveryProof, error)
+	}
+	Product interface {
+		Create(context.Context, *models.Product) error
+		GetAll(context.Context, int, int) ([]models.Product, int, error)
+		GetByID(context.Context, string) (*models.Product, error)
+		Update(context.Context, *models.Product) error
+		Delete(context.Context, string) error
+		CreatePieceRate(context.Context, *models.PieceRate) error
+		GetPieceRates(context.Context, string) ([]models.PieceRate, error)
+		DeletePieceRate(context.Context, string, string) error
+	}
+	Employee interface {
+		Create(context.Context, *models.Employee) error
+		GetAll(context.Context, bool, int, int) ([]models.Employee, int, error)
+		GetByID(context.Context, string) (*models.Employee, error)
+		Update(context.Context, *models.Employee) error
+		Delete(context.Context, string) error
+	}
+	Attendance interface {
+		CheckIn(context.Context, *models.Attendance) error
+		CheckOut(context.Context, string, time.Time) (*models.Attendance, error)
+		Update(context.Context, *models.Attendance) error
+		Delete(context.Context, string) error
+		GetDaily(context.Context, time.Time) ([]models.Attendance, models.AttendanceSummary, error)
+		GetMonthly(context.Context, int) ([]models.AttendanceMonth, error)
+	}
+	Equipment interface {
+		Create(context.Context, *models.Equipment) error
+		GetAll(context.Context, int, int) ([]models.Equipment, int, error)
+		GetByID(context.Context, string) (*models.Equipment, error)
+		Update(context.Context, *models.Equipment) error
+		Delete(context.Context, string) error
+		CreateSchedule(context.Context, *models.MaintenanceSchedule) error
+		UpdateSchedule(context.Context, *models.MaintenanceSchedule) error
+		DeleteSchedule(context.Context, string, string) error
+		LogMaintenance(context.Context, *models.MaintenanceLog) error
+		GetMaintenanceLogs(context.Context, string, int, int) ([]models.MaintenanceLog, int, error)
+		GetMaintenanceDue(context.Context, time.Time, int) ([]models.MaintenanceSchedule, error)
+	}
+	Expense interface {
+		Create(context.Context, *models.Expense) error
+		GetAll(context.Context, string, int, int) ([]models.Expense, int, error)
+		GetAllDaily(context.Context, time.Time) ([]models.Expense, models.ExpenseSummary, error)
+		GetAllWeekly(context.Context, int) ([]models.Expense, models.ExpenseSummary, error)
+		GetAllMonthly(context.Context, int) ([]models.Expense, models.ExpenseSummary, error)
+		GetByID(context.Context, string) (*models.Expense, error)
+		Update(context.Context, *models.Expense) error
+		Delete(context.Context, string) (*models.Expense, error)
+		SetReceipt(context.Context, *models.Expense) (string, error)
+		ClearReceipt(context.Context, string) (string, error)
+		CreateCategory(context.Context, *models.ExpenseCategory) error
+		GetCategories(context.Context) ([]models.ExpenseCategory, error)
+		UpdateCategory(context.Context, *models.ExpenseCategory) error
+		DeleteCategory(context.Context, string) error
+	}
+	Payroll interface {
+		GetPeriod(context.Context, string, time.Time, time.Time) (*models.PayrollRun, error)
+		Approve(context.Context, string, time.Time, time.Time) (*models.PayrollRun, error)
+		MarkPaid(context.Context, string) (*models.PayrollRun, error)
+		Reopen(context.Context, string) error
+	}
+	Ledger interface {
+		CreateAccount(context.Context, *models.Account) error
+		GetAccounts(context.Context) ([]models.Account, error)
+		UpdateAccount(context.Context, *models.Account) error
+		DeleteAccount(context.Context, string) error
+		CreateEntry(context.Context, *models.JournalEntry) error
+		GetEntries(context.Context, time.Time, time.Time, string, int, int) ([]models.JournalEntry, int, error)
+		GetEntryByID(context.Context, string) (*models.JournalEntry, error)
+		ReverseEntry(context.Context, string) (*models.JournalEntry, error)
+		GetTrialBalance(context.Context, time.Time) (*models.TrialBalance, error)
+		GetAccountLedger(context.Context, string, time.Time, time.Time) (*models.AccountLedger, error)
+		GetBalanceSheet(context.Context, time.Time) (*models.BalanceSheet, error)
+	}
+	Cash interface {
+		Open(context.Context, *models.CashSession) error
+		GetAll(context.Context, int, int) ([]models.CashSession, int, error)
+		GetByDate(context.Context, time.Time) (*models.CashSession, error)
+		GetClosing(context.Context, time.Time) (*models.CashSession, error)
+		Close(context.Context, time.Time, float64, string) (*models.CashSession, error)
+		Reopen(context.Context, time.Time) (*models.CashSession, error)
+		Delete(context.Context, time.Time) error
+	}
+	Bank interface {
+		Import(context.Context, *models.BankStatement, []models.BankStatementLine) error
+		GetStatements(context.Context, int, int) ([]models.BankStatement, int, error)
+		GetStatement(context.Context, string) (*models.BankStatement, error)
+		DeleteStatement(context.Context, string) error
+		GetLines(context.Context, string, int, int) ([]models.BankStatementLine, int, error)
+		GetSuggestions(context.Context, string) ([]models.BankMatchSuggestion, error)
+		ConfirmMatch(context.Context, string, string) (*models.BankStatementLine, error)
+		RejectMatch(context.Context, string, string) error
+		Ignore(context.Context, string) (*models.BankStatementLine, error)
+		Unmatch(context.Context, string) (*models.BankStatementLine, error)
+	}
+}
+
+// Shared by *sql.DB and *sql.Tx so helpers can run inside or outside a transaction
+type querier interface {
+	ExecContext(context.Context, string, ...any) (sql.Result, error)
+	QueryContext(context.Context, string, ...any) (*sql.Rows, error)
+	QueryRowContext(context.Context, string, ...any) *sql.Row
 }
 
 func NewStorage(db *sql.DB) Storage {
 	return Storage{
 		Production: &ProductionStore{db: db},
 		Transaction: &TransactionStore{db: db},
+		PriceList: &PriceListStore{db: db},
+		TaxRate: &TaxRateStore{db: db},
+		Customer: &CustomerStore{db: db},
+		Stock: &StockStore{db: db},
+		Material: &MaterialStore{db: db},
+		Supplier: &SupplierStore{db: db},
+		PurchaseOrder: &PurchaseOrderStore{db: db},
+		Payment: &PaymentStore{db: db},
+		Report: &ReportStore{db: db},
+		Invoice: &InvoiceStore{db: db},
+		Quality: &QualityStore{db: db},
+		Delivery: &DeliveryStore{db: db},
+		DeliveryProof: &DeliveryProofStore{db: db},
+		Product: &ProductStore{db: db},
+		Employee: &EmployeeStore{db: db},
+		Attendance: &AttendanceStore{db: db},
+		Equipment: &EquipmentStore{db: db},
+		Expense: &ExpenseStore{db: db},
+		Payroll: &PayrollStore{db: db},
+		Ledger: &LedgerStore{db: db},
+		Cash: &CashStore{db: db},
+		Bank: &BankStore{db: db},
+	}
+}
+
+// Run fn inside a database transaction, rolling back if it returns an error
+func withTx(ctx context.Context, db *sql.DB, fn func(*sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
 	}
+
+	return tx.Commit()
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+func isForeignKeyViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23503"
 }
@@ -0,0 +1,204 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kevinbrivio/batako-backend/internal/models"
+	"github.com/kevinbrivio/batako-backend/internal/utils"
+)
+
+type SupplierStore struct {
+	db *sql.DB
+}
+
+func (s *SupplierStore) Create(ctx context.Context, sp *models.Supplier) error {
+	sp.ID = uuid.New().String()
+	sp.Name = utils.CleanName(sp.Name)
+
+	query := `
+		INSERT INTO suppliers (id, name, normalized_name, phone, address)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at, updated_at
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	err := s.db.QueryRowContext(
+		ctx,
+		query,
+		sp.ID,
+		sp.Name,
+		utils.NormalizeName(sp.Name),
+		sp.Phone,
+		sp.Address,
+	).Scan(
+		&sp.CreatedAt,
+		&sp.UpdatedAt,
+	)
+
+	if isUniqueViolation(err) {
+		return utils.NewConflictError("Supplier already exists")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *SupplierStore) GetAll(ctx context.Context, limit, offset int) ([]models.Supplier, int, error) {
+	query := `
+		SELECT
+			id,
+			name,
+			phone,
+			address,
+			COUNT(*) OVER() as total_count,
+			created_at,
+			updated_at
+		FROM suppliers
+		ORDER BY name ASC
+		LIMIT $1 OFFSET $2
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	suppliers := []models.Supplier{}
+	var totalCount int
+
+	for rows.Next() {
+		var sp models.Supplier
+		if err := rows.Scan(
+			&sp.ID,
+			&sp.Name,
+			&sp.Phone,
+			&sp.Address,
+			&totalCount,
+			&sp.CreatedAt,
+			&sp.UpdatedAt,
+		); err != nil {
+			return suppliers, 0, err
+		}
+		suppliers = append(suppliers, sp)
+	}
+	if err = rows.Err(); err != nil {
+		return suppliers, 0, err
+	}
+
+	return suppliers, totalCount, nil
+}
+
+func (s *SupplierStore) GetByID(ctx context.Context, sID string) (*models.Supplier, error) {
+	query := `
+		SELECT id, name, phone, address, created_at, updated_at
+		FROM suppliers
+		WHERE id = $1
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	var sp models.Supplier
+
+	err := s.db.QueryRowContext(
+		ctx, query,
+		sID,
+	).Scan(
+		&sp.ID,
+		&sp.Name,
+		&sp.Phone,
+		&sp.Address,
+		&sp.CreatedAt,
+		&sp.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("Supplier")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &sp, nil
+}
+
+func (s *SupplierStore) Update(ctx context.Context, sp *models.Supplier) error {
+	sp.Name = utils.CleanName(sp.Name)
+
+	query := `
+		UPDATE suppliers
+		SET name = $2, normalized_name = $3, phone = $4, address = $5, updated_at = NOW()
+		WHERE id = $1
+		RETURNING created_at, updated_at
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	err := s.db.QueryRowContext(
+		ctx,
+		query,
+		sp.ID,
+		sp.Name,
+		utils.NormalizeName(sp.Name),
+		sp.Phone,
+		sp.Address,
+	).Scan(
+		&sp.CreatedAt,
+		&sp.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return utils.NewNotFoundError("Supplier")
+	}
+
+	if isUniqueViolation(err) {
+		return utils.NewConflictError("Another supplier already has this name")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *SupplierStore) Delete(ctx context.Context, sID string) error {
+	query := `
+		DELETE FROM suppliers
+		WHERE id = $1;
+	`
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, sID)
+	if isForeignKeyViolation(err) {
+		return utils.NewConflictError("Supplier has purchases and cannot be deleted")
+	}
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return utils.NewNotFoundError("Supplier")
+	}
+	return nil
+}
@@ -0,0 +1,331 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kevinbrivio/batako-backend/internal/models"
+	"github.com/kevinbrivio/batako-backend/internal/utils"
+)
+
+type TaxRateStore struct {
+	db *sql.DB
+}
+
+func (s *TaxRateStore) Create(ctx context.Context, r *models.TaxRate) error {
+	r.ID = uuid.New().String()
+
+	query := `
+		INSERT INTO tax_rates (id, name, rate, prices_include_tax, effective_from)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at, updated_at
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	err := s.db.QueryRowContext(
+		ctx,
+		query,
+		r.ID,
+		r.Name,
+		r.Rate,
+		r.PricesIncludeTax,
+		r.EffectiveFrom,
+	).Scan(
+		&r.CreatedAt,
+		&r.UpdatedAt,
+	)
+
+	if isUniqueViolation(err) {
+		return utils.NewConflictError("A tax rate is already effective from this date")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *TaxRateStore) GetAll(ctx context.Context, limit, offset int) ([]models.TaxRate, int, error) {
+	query := `
+		SELECT
+			id,
+			name,
+			rate,
+			prices_include_tax,
+			effective_from,
+			COUNT(*) OVER() as total_count,
+			created_at,
+			updated_at
+		FROM tax_rates
+		ORDER BY effective_from DESC
+		LIMIT $1 OFFSET $2
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	rates := []models.TaxRate{}
+	var totalCount int
+
+	for rows.Next() {
+		var r models.TaxRate
+		if err := rows.Scan(
+			&r.ID,
+			&r.Name,
+			&r.Rate,
+			&r.PricesIncludeTax,
+			&r.EffectiveFrom,
+			&totalCount,
+			&r.CreatedAt,
+			&r.UpdatedAt,
+		); err != nil {
+			return rates, 0, err
+		}
+		rates = append(rates, r)
+	}
+	if err = rows.Err(); err != nil {
+		return rates, 0, err
+	}
+
+	return rates, totalCount, nil
+}
+
+func (s *TaxRateStore) GetByID(ctx context.Context, rID string) (*models.TaxRate, error) {
+	query := `
+		SELECT id, name, rate, prices_include_tax, effective_from, created_at, updated_at
+		FROM tax_rates
+		WHERE id = $1
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	var r models.TaxRate
+
+	err := s.db.QueryRowContext(
+		ctx, query,
+		rID,
+	).Scan(
+		&r.ID,
+		&r.Name,
+		&r.Rate,
+		&r.PricesIncludeTax,
+		&r.EffectiveFrom,
+		&r.CreatedAt,
+		&r.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("Tax rate")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+// Tax rate that applies on the given date, i.e. the latest one whose
+// effective_from is not after it
+func (s *TaxRateStore) GetEffective(ctx context.Context, date time.Time) (*models.TaxRate, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	return getEffectiveTaxRate(ctx, s.db, date)
+}
+
+func (s *TaxRateStore) Update(ctx context.Context, r *models.TaxRate) error {
+	query := `
+		UPDATE tax_rates
+		SET name = $2, rate = $3, prices_include_tax = $4, effective_from = $5, updated_at = NOW()
+		WHERE id = $1
+		RETURNING created_at, updated_at
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	err := s.db.QueryRowContext(
+		ctx,
+		query,
+		r.ID,
+		r.Name,
+		r.Rate,
+		r.PricesIncludeTax,
+		r.EffectiveFrom,
+	).Scan(
+		&r.CreatedAt,
+		&r.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return utils.NewNotFoundError("Tax rate")
+	}
+
+	if isUniqueViolation(err) {
+		return utils.NewConflictError("A tax rate is already effective from this date")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *TaxRateStore) Delete(ctx context.Context, rID string) error {
+	query := `
+		DELETE FROM tax_rates
+		WHERE id = $1;
+	`
+	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, rID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return utils.NewNotFoundError("Tax rate")
+	}
+	return nil
+}
+
+// No tax is charged before the first rate takes effect, so a zero rate is
+// returned rather than an error
+func getEffectiveTaxRate(ctx context.Context, q querier, date time.Time) (*models.TaxRate, error) {
+	query := `
+		SELECT id, name, rate, prices_include_tax, effective_from, created_at, updated_at
+		FROM tax_rates
+		WHERE effective_from <= $1::date
+		ORDER BY effective_from DESC
+		LIMIT 1
+	`
+
+	var r models.TaxRate
+
+	err := q.QueryRowContext(ctx, query, date).Scan(
+		&r.ID,
+		&r.Name,
+		&r.Rate,
+		&r.PricesIncludeTax,
+		&r.EffectiveFrom,
+		&r.CreatedAt,
+		&r.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return &models.TaxRate{Name: "PPN"}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+// Split the priced sale into subtotal, PPN and grand total. Exclusive prices
+// get the tax added on top; inclusive prices already hold it, so it is carved
+// out of the line total. Tax is rounded to whole rupiah and exempt customers
+// pay none.
+func applyTransactionTax(ctx context.Context, q querier, t *models.Transaction) error {
+	gross := t.TotalPrice
+
+	err := q.QueryRowContext(ctx, `
+		SELECT tax_exempt FROM customers WHERE id = $1
+	`, t.CustomerID).Scan(&t.TaxExempt)
+	if err == sql.ErrNoRows {
+		return utils.NewNotFoundError("Customer")
+	}
+	if err != nil {
+		return err
+	}
+
+	rate, err := getEffectiveTaxRate(ctx, q, t.PurchaseDate)
+	if err != nil {
+		return err
+	}
+	t.TaxInclusive = rate.PricesIncludeTax
+
+	t.TaxRate = rate.Rate
+	if t.TaxExempt {
+		t.TaxRate = 0
+	}
+
+	t.Subtotal, t.TaxAmount, t.TotalPrice = splitTax(gross, t.TaxRate, t.TaxInclusive)
+	return nil
+}
+
+// Subtotal, tax and grand total of an amount priced with or without the tax
+// in it, with the tax rounded to whole rupiah
+func splitTax(amount, rate float64, inclusive bool) (float64, float64, float64) {
+	switch {
+	case rate == 0:
+		return amount, 0, amount
+	case inclusive:
+		tax := math.Round(amount * rate / (100 + rate))
+		return amount - tax, tax, amount
+	default:
+		tax := math.Round(amount * rate / 100)
+		return amount, tax, amount + tax
+	}
+}
+
+// Output PPN for sales between start and end, one row per rate charged.
+// Exempt customers and sales before any rate applied are kept apart so the
+// return can report them separately.
+func getTransactionTaxSummary(ctx context.Context, q querier, start, end time.Time) (*models.TaxSummary, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT tax_rate, tax_exempt, COUNT(*), SUM(subtotal), SUM(tax_amount)
+		FROM transactions
+		WHERE purchase_date BETWEEN $1 AND $2
+		GROUP BY tax_rate, tax_exempt
+		ORDER BY tax_rate ASC
+	`, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	summary := &models.TaxSummary{Rates: []models.TaxRateSummary{}}
+	for rows.Next() {
+		var rs models.TaxRateSummary
+		var exempt bool
+		if err := rows.Scan(&rs.Rate, &exempt, &rs.Count, &rs.TaxableAmount, &rs.TaxAmount); err != nil {
+			return summary, err
+		}
+
+		switch {
+		case exempt:
+			summary.ExemptAmount += rs.TaxableAmount
+		case rs.Rate == 0:
+			summary.UntaxedAmount += rs.TaxableAmount
+		default:
+			summary.TaxableAmount += rs.TaxableAmount
+			summary.TaxAmount += rs.TaxAmount
+			summary.Rates = append(summary.Rates, rs)
+		}
+	}
+
+	return summary, rows.Err()
+}
@@ -0,0 +1,38 @@
+package store
+
+import "testing"
+
+func TestSplitTax(t *testing.T) {
+	tests := []struct {
+		name      string
+		amount    float64
+		rate      float64
+		inclusive bool
+		subtotal  float64
+		tax       float64
+		total     float64
+	}{
+		{"no rate", 1000000, 0, false, 1000000, 0, 1000000},
+		{"no rate inclusive", 1000000, 0, true, 1000000, 0, 1000000},
+		{"exclusive", 1000000, 11, false, 1000000, 110000, 1110000},
+		{"inclusive", 1110000, 11, true, 1000000, 110000, 1110000},
+		{"exclusive rounds up", 12345, 11, false, 12345, 1358, 13703},
+		{"exclusive rounds down", 12340, 11, false, 12340, 1357, 13697},
+		{"inclusive rounds", 10000, 11, true, 9009, 991, 10000},
+		{"inclusive at 12 percent", 1120000, 12, true, 1000000, 120000, 1120000},
+		{"fractional rate", 100000, 1.1, false, 100000, 1100, 101100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subtotal, tax, total := splitTax(tt.amount, tt.rate, tt.inclusive)
+			if subtotal != tt.subtotal || tax != tt.tax || total != tt.total {
+				t.Errorf("splitTax(%v, %v, %v) = %v, %v, %v, want %v, %v, %v",
+					tt.amount, tt.rate, tt.inclusive, subtotal, tax, total, tt.subtotal, tt.tax, tt.total)
+			}
+			if subtotal+tax != total {
+				t.Errorf("subtotal %v + tax %v does not add up to total %v", subtotal, tax, total)
+			}
+		})
+	}
+}
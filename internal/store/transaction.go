@@ -3,11 +3,15 @@ package store
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/kevinbrivio/batako-backend/internal/models"
 	"github.com/kevinbrivio/batako-backend/internal/utils"
+	"github.com/lib/pq"
 )
 
 type TransactionStore struct {
@@ -18,52 +22,104 @@ func (s *TransactionStore) Create(ctx context.Context, t *models.Transaction) er
 	t.ID = uuid.New().String()
 
 	query := `
-		INSERT INTO transactions (id, customer, address, quantity, total_price, purchase_date)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO transactions (id, customer_id, customer, address_id, address, quantity, subtotal, tax_rate, tax_amount, total_price, tax_inclusive, tax_exempt, purchase_date)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 		RETURNING created_at, updated_at
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
 	defer cancel()
 
-	// Calculate total price
-	const price = 1600
-	totalPrice := float64(t.Quantity) * price
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		if err := resolveTransactionCustomer(ctx, tx, t); err != nil {
+			return err
+		}
 
-	err := s.db.QueryRowContext(
-		ctx,
-		query,
-		t.ID,
-		t.Customer,
-		t.Address,
-		t.Quantity,
-		totalPrice,
-		t.PurchaseDate,
-	).Scan(
-		&t.CreatedAt,
-		&t.UpdatedAt,
-	)
+		// Prices are locked in from the price list effective on the purchase date
+		if err := priceTransactionItems(ctx, tx, t, nil); err != nil {
+			return err
+		}
 
-	if err != nil {
-		return err
-	}
+		// PPN comes from the rate effective on the purchase date too
+		if err := applyTransactionTax(ctx, tx, t); err != nil {
+			return err
+		}
+		t.PaidAmount = 0
+		setPaymentStatus(t)
 
-	return nil
+		if err := lockStockLedger(ctx, tx); err != nil {
+			return err
+		}
+
+		if err := ensureTransactionStock(ctx, tx, t); err != nil {
+			return err
+		}
+
+		err := tx.QueryRowContext(
+			ctx,
+			query,
+			t.ID,
+			t.CustomerID,
+			t.Customer,
+			t.AddressID,
+			t.Address,
+			t.Quantity,
+			t.Subtotal,
+			t.TaxRate,
+			t.TaxAmount,
+			t.TotalPrice,
+			t.TaxInclusive,
+			t.TaxExempt,
+			t.PurchaseDate,
+		).Scan(
+			&t.CreatedAt,
+			&t.UpdatedAt,
+		)
+
+		if err != nil {
+			return err
+		}
+
+		for i := range t.Items {
+			if err := insertTransactionItem(ctx, tx, t.ID, &t.Items[i]); err != nil {
+				return err
+			}
+		}
+
+		if err := postTransactionStock(ctx, tx, t); err != nil {
+			return err
+		}
+
+		return postTransactionJournal(ctx, tx, t)
+	})
 }
 
 func (s *TransactionStore) GetAll(ctx context.Context, limit, offset int) ([]models.Transaction, int, error) {
 	query := `
 		SELECT 
-			id, 
+			transactions.id, 
+			customer_id,
 			customer, 
+			COALESCE(address_id, ''),
 			address,
 			quantity,
+			subtotal,
+			tax_rate,
+			tax_amount,
 			total_price,
+			tax_inclusive,
+			tax_exempt,
+			COALESCE(p.paid_amount, 0) as paid_amount,
 			COUNT(*) OVER() as total_count,
 			purchase_date,
-			created_at,
-			updated_at
+			transactions.created_at,
+			transactions.updated_at
 		FROM transactions
+		LEFT JOIN (
+			SELECT transaction_id, SUM(amount) as paid_amount
+			FROM payments
+			GROUP BY transaction_id
+		) p ON p.transaction_id = transactions.id
 		ORDER BY purchase_date DESC
 		LIMIT $1 OFFSET $2
 	`
@@ -85,10 +141,18 @@ func (s *TransactionStore) GetAll(ctx context.Context, limit, offset int) ([]mod
 		var t models.Transaction
 		if err := rows.Scan(
 			&t.ID,
+			&t.CustomerID,
 			&t.Customer,
+			&t.AddressID,
 			&t.Address,
 			&t.Quantity,
+			&t.Subtotal,
+			&t.TaxRate,
+			&t.TaxAmount,
 			&t.TotalPrice,
+			&t.TaxInclusive,
+			&t.TaxExempt,
+			&t.PaidAmount,
 			&totalCount, 
 			&t.PurchaseDate,
 			&t.CreatedAt,
@@ -96,12 +160,17 @@ func (s *TransactionStore) GetAll(ctx context.Context, limit, offset int) ([]mod
 		); err != nil {
 			return transactions, 0, err
 		}
+		setPaymentStatus(&t)
 		transactions = append(transactions, t)
 	}
 	if err = rows.Err(); err != nil {
 		return transactions, 0, err
 	}
 
+	if err := loadTransactionItems(ctx, s.db, transactions); err != nil {
+		return transactions, 0, err
+	}
+
 	return transactions, totalCount, nil
 }
 
@@ -111,16 +180,29 @@ func (s *TransactionStore) GetAllWeekly(ctx context.Context, weekOffset int) ([]
 	
 	query := `
 		SELECT 
-			id, 
+			transactions.id, 
+			customer_id,
 			customer, 
+			COALESCE(address_id, ''),
 			address,
 			quantity,
+			subtotal,
+			tax_rate,
+			tax_amount,
 			total_price,
+			tax_inclusive,
+			tax_exempt,
+			COALESCE(p.paid_amount, 0) as paid_amount,
 			COUNT(*) OVER() as total_count,
 			purchase_date,
-			created_at,
-			updated_at
+			transactions.created_at,
+			transactions.updated_at
 		FROM transactions
+		LEFT JOIN (
+			SELECT transaction_id, SUM(amount) as paid_amount
+			FROM payments
+			GROUP BY transaction_id
+		) p ON p.transaction_id = transactions.id
 		WHERE purchase_date BETWEEN $1 and $2
 		ORDER BY purchase_date DESC
 	`
@@ -141,10 +223,18 @@ func (s *TransactionStore) GetAllWeekly(ctx context.Context, weekOffset int) ([]
 		var t models.Transaction
 		if err := rows.Scan(
 			&t.ID,
+			&t.CustomerID,
 			&t.Customer,
+			&t.AddressID,
 			&t.Address,
 			&t.Quantity,
+			&t.Subtotal,
+			&t.TaxRate,
+			&t.TaxAmount,
 			&t.TotalPrice,
+			&t.TaxInclusive,
+			&t.TaxExempt,
+			&t.PaidAmount,
 			&totalCount, 
 			&t.PurchaseDate,
 			&t.CreatedAt,
@@ -152,34 +242,53 @@ func (s *TransactionStore) GetAllWeekly(ctx context.Context, weekOffset int) ([]
 		); err != nil {
 			return transactions, 0, err
 		}
+		setPaymentStatus(&t)
 		transactions = append(transactions, t)
 	}
 	if err = rows.Err(); err != nil {
 		return transactions, 0, err
 	}
 
+	if err := loadTransactionItems(ctx, s.db, transactions); err != nil {
+		return transactions, 0, err
+	}
+
 	return transactions, totalCount, nil
 }
 
-func (s *TransactionStore) GetAllMonthly(ctx context.Context, monthOffset int) ([]models.Transaction, int, int, float64, error) {
+func (s *TransactionStore) GetAllMonthly(ctx context.Context, monthOffset int) ([]models.Transaction, models.TransactionSummary, error) {
 	today := time.Now()
 
 	start, end := utils.GetMonthRange(today, monthOffset)
 	
 	query := `
 		SELECT 
-			id, 
+			transactions.id, 
+			customer_id,
 			customer, 
+			COALESCE(address_id, ''),
 			address,
 			quantity,
+			subtotal,
+			tax_rate,
+			tax_amount,
 			total_price,
+			tax_inclusive,
+			tax_exempt,
+			COALESCE(p.paid_amount, 0) as paid_amount,
 			COUNT(*) OVER() as total_count,
 			SUM(quantity) OVER() as total_quantity,
 			SUM(total_price) OVER() as total_revenue,
+			SUM(COALESCE(p.paid_amount, 0)) OVER() as total_paid,
 			purchase_date,
-			created_at,
-			updated_at
+			transactions.created_at,
+			transactions.updated_at
 		FROM transactions
+		LEFT JOIN (
+			SELECT transaction_id, SUM(amount) as paid_amount
+			FROM payments
+			GROUP BY transaction_id
+		) p ON p.transaction_id = transactions.id
 		WHERE purchase_date BETWEEN $1 and $2
 		ORDER BY purchase_date DESC
 	`
@@ -189,57 +298,96 @@ func (s *TransactionStore) GetAllMonthly(ctx context.Context, monthOffset int) (
 
 	rows, err := s.db.QueryContext(ctx, query, start, end)
 	if err != nil {
-		return nil, 0, 0, 0, err
+		return nil, models.TransactionSummary{}, err
 	}
 	defer rows.Close()
 
 	transactions := []models.Transaction{}
-	var totalCount, totalQuantity int
-	var totalRevenue float64
+	var summary models.TransactionSummary
 
 	for rows.Next() {
 		var t models.Transaction
 		if err := rows.Scan(
 			&t.ID,
+			&t.CustomerID,
 			&t.Customer,
+			&t.AddressID,
 			&t.Address,
 			&t.Quantity,
+			&t.Subtotal,
+			&t.TaxRate,
+			&t.TaxAmount,
 			&t.TotalPrice,
-			&totalCount, 
-			&totalQuantity,
-			&totalRevenue,
+			&t.TaxInclusive,
+			&t.TaxExempt,
+			&t.PaidAmount,
+			&summary.TotalCount, 
+			&summary.TotalQuantity,
+			&summary.TotalRevenue,
+			&summary.TotalPaid,
 			&t.PurchaseDate,
 			&t.CreatedAt,
 			&t.UpdatedAt,
 		); err != nil {
-			return transactions, 0, 0, 0, err
+			return transactions, models.TransactionSummary{}, err
 		}
+		setPaymentStatus(&t)
 		transactions = append(transactions, t)
 	}
 	if err = rows.Err(); err != nil {
-		return transactions, 0, 0, 0, err
+		return transactions, models.TransactionSummary{}, err
+	}
+
+	if err := loadTransactionItems(ctx, s.db, transactions); err != nil {
+		return transactions, models.TransactionSummary{}, err
+	}
+
+	summary.TotalOutstanding = summary.TotalRevenue - summary.TotalPaid
+
+	summary.Products, err = getTransactionProductSummary(ctx, s.db, start, end)
+	if err != nil {
+		return transactions, models.TransactionSummary{}, err
 	}
 
-	return transactions, totalCount, totalQuantity, totalRevenue, nil
+	summary.Tax, err = getTransactionTaxSummary(ctx, s.db, start, end)
+	if err != nil {
+		return transactions, models.TransactionSummary{}, err
+	}
+
+	return transactions, summary, nil
 }
 
-func (s *TransactionStore) GetAllDaily(ctx context.Context, date time.Time) ([]models.Transaction, int, int, float64, error) {
+func (s *TransactionStore) GetAllDaily(ctx context.Context, date time.Time) ([]models.Transaction, models.TransactionSummary, error) {
 	start, end := utils.GetDayRange(date)
 	
 	query := `
 		SELECT 
-			id, 
+			transactions.id, 
+			customer_id,
 			customer, 
+			COALESCE(address_id, ''),
 			address,
 			quantity,
+			subtotal,
+			tax_rate,
+			tax_amount,
 			total_price,
+			tax_inclusive,
+			tax_exempt,
+			COALESCE(p.paid_amount, 0) as paid_amount,
 			COUNT(*) OVER() as total_count,
 			SUM(quantity) OVER() as total_quantity,
 			SUM(total_price) OVER() as total_revenue,
+			SUM(COALESCE(p.paid_amount, 0)) OVER() as total_paid,
 			purchase_date,
-			created_at,
-			updated_at
+			transactions.created_at,
+			transactions.updated_at
 		FROM transactions
+		LEFT JOIN (
+			SELECT transaction_id, SUM(amount) as paid_amount
+			FROM payments
+			GROUP BY transaction_id
+		) p ON p.transaction_id = transactions.id
 		WHERE purchase_date BETWEEN $1 and $2
 		ORDER BY purchase_date DESC
 	`
@@ -249,44 +397,80 @@ func (s *TransactionStore) GetAllDaily(ctx context.Context, date time.Time) ([]m
 
 	rows, err := s.db.QueryContext(ctx, query, start, end)
 	if err != nil {
-		return nil, 0, 0, 0, err
+		return nil, models.TransactionSummary{}, err
 	}
 	defer rows.Close()
 
 	transactions := []models.Transaction{}
-	var totalCount, totalQuantity int
-	var totalRevenue float64
+	var summary models.TransactionSummary
 
 	for rows.Next() {
 		var t models.Transaction
 		if err := rows.Scan(
 			&t.ID,
+			&t.CustomerID,
 			&t.Customer,
+			&t.AddressID,
 			&t.Address,
 			&t.Quantity,
+			&t.Subtotal,
+			&t.TaxRate,
+			&t.TaxAmount,
 			&t.TotalPrice,
-			&totalCount, 
-			&totalQuantity,
-			&totalRevenue,
+			&t.TaxInclusive,
+			&t.TaxExempt,
+			&t.PaidAmount,
+			&summary.TotalCount, 
+			&summary.TotalQuantity,
+			&summary.TotalRevenue,
+			&summary.TotalPaid,
 			&t.PurchaseDate,
 			&t.CreatedAt,
 			&t.UpdatedAt,
 		); err != nil {
-			return transactions, 0, 0, 0, err
+			return transactions, models.TransactionSummary{}, err
 		}
+		setPaymentStatus(&t)
 		transactions = append(transactions, t)
 	}
 	if err = rows.Err(); err != nil {
-		return transactions, 0, 0, 0, err
+		return transactions, models.TransactionSummary{}, err
 	}
 
-	return transactions, totalCount, totalQuantity, totalRevenue, nil
+	if err := loadTransactionItems(ctx, s.db, transactions); err != nil {
+		return transactions, models.TransactionSummary{}, err
+	}
+
+	summary.TotalOutstanding = summary.TotalRevenue - summary.TotalPaid
+	return transactions, summary, nil
 }
 
 func (s *TransactionStore) GetByID(ctx context.Context, pID string) (*models.Transaction, error) {
 	query := `
-		SELECT * FROM transactions
-		WHERE id = $1
+		SELECT
+			transactions.id,
+			customer_id,
+			customer,
+			COALESCE(address_id, ''),
+			address,
+			quantity,
+			subtotal,
+			tax_rate,
+			tax_amount,
+			total_price,
+			tax_inclusive,
+			tax_exempt,
+			COALESCE(p.paid_amount, 0) as paid_amount,
+			purchase_date,
+			transactions.created_at,
+			transactions.updated_at
+		FROM transactions
+		LEFT JOIN (
+			SELECT transaction_id, SUM(amount) as paid_amount
+			FROM payments
+			GROUP BY transaction_id
+		) p ON p.transaction_id = transactions.id
+		WHERE transactions.id = $1
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
@@ -299,10 +483,18 @@ func (s *TransactionStore) GetByID(ctx context.Context, pID string) (*models.Tra
 		pID,
 	).Scan(
 		&t.ID,
+		&t.CustomerID,
 		&t.Customer,
+		&t.AddressID,
 		&t.Address,
 		&t.Quantity,
+		&t.Subtotal,
+		&t.TaxRate,
+		&t.TaxAmount,
 		&t.TotalPrice,
+		&t.TaxInclusive,
+		&t.TaxExempt,
+		&t.PaidAmount,
 		&t.PurchaseDate,
 		&t.CreatedAt,
 		&t.UpdatedAt,
@@ -316,13 +508,20 @@ func (s *TransactionStore) GetByID(ctx context.Context, pID string) (*models.Tra
 		return nil, err
 	}
 
-	return &t, nil
+	setPaymentStatus(&t)
+
+	transactions := []models.Transaction{t}
+	if err := loadTransactionItems(ctx, s.db, transactions); err != nil {
+		return nil, err
+	}
+
+	return &transactions[0], nil
 }
 
 func (s *TransactionStore) Update(ctx context.Context, t *models.Transaction) error {
 	query := `
 		UPDATE transactions
-		SET customer = $2, address = $3, quantity = $4, total_price = $4, purchase_date = $5, updated_at = NOW()
+		SET customer_id = $2, customer = $3, address_id = $4, address = $5, quantity = $6, subtotal = $7, tax_rate = $8, tax_amount = $9, total_price = $10, tax_inclusive = $11, tax_exempt = $12, purchase_date = $13, updated_at = NOW()
 		WHERE id = $1
 		RETURNING created_at, updated_at
 	`
@@ -330,33 +529,113 @@ func (s *TransactionStore) Update(ctx context.Context, t *models.Transaction) er
 	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
 	defer cancel()
 
-	// Calculate total price
-	const price = 1600
-	totalPrice := float64(t.Quantity) * price
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		// Lock the sale first so a payment can't land between reading the paid
+		// amount and saving the new total
+		var exists bool
+		err := tx.QueryRowContext(ctx, `SELECT TRUE FROM transactions WHERE id = $1 FOR UPDATE`, t.ID).Scan(&exists)
+		if err == sql.ErrNoRows {
+			return utils.NewNotFoundError("Transaction")
+		}
+		if err != nil {
+			return err
+		}
 
-	err := s.db.QueryRowContext(
-		ctx,
-		query,
-		t.ID,
-		t.Customer,
-		t.Address,
-		t.Quantity,
-		totalPrice,
-		t.PurchaseDate,
-	).Scan(
-		&t.CreatedAt,
-		&t.UpdatedAt,
-	)
+		// The invoice PDF is drawn from the sale itself, so an issued invoice
+		// freezes its lines and totals
+		var invoiced bool
+		err = tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM invoices WHERE transaction_id = $1)`, t.ID).Scan(&invoiced)
+		if err != nil {
+			return err
+		}
+		if invoiced {
+			return utils.NewConflictError("Transaction has been invoiced and cannot be edited")
+		}
 
-	if err == sql.ErrNoRows {
-		return utils.NewNotFoundError("Transaction")
-	}
+		if err := resolveTransactionCustomer(ctx, tx, t); err != nil {
+			return err
+		}
 
-	if err != nil {
-		return err
-	}
+		// Lines keep the price they were sold at; only a changed product or
+		// purchase date takes the price effective on the new date
+		priced, err := getPricedTransactionItems(ctx, tx, t)
+		if err != nil {
+			return err
+		}
 
-	return nil
+		if err := priceTransactionItems(ctx, tx, t, priced); err != nil {
+			return err
+		}
+
+		// PPN comes from the rate effective on the purchase date too
+		if err := applyTransactionTax(ctx, tx, t); err != nil {
+			return err
+		}
+
+		err = tx.QueryRowContext(ctx, `
+			SELECT COALESCE(SUM(amount), 0) FROM payments WHERE transaction_id = $1
+		`, t.ID).Scan(&t.PaidAmount)
+		if err != nil {
+			return err
+		}
+
+		if t.TotalPrice < t.PaidAmount {
+			return utils.NewBadRequestError("Total price cannot be lower than the amount already paid")
+		}
+		setPaymentStatus(t)
+
+		err = tx.QueryRowContext(
+			ctx,
+			query,
+			t.ID,
+			t.CustomerID,
+			t.Customer,
+			t.AddressID,
+			t.Address,
+			t.Quantity,
+			t.Subtotal,
+			t.TaxRate,
+			t.TaxAmount,
+			t.TotalPrice,
+			t.TaxInclusive,
+			t.TaxExempt,
+			t.PurchaseDate,
+		).Scan(
+			&t.CreatedAt,
+			&t.UpdatedAt,
+		)
+
+		if err == sql.ErrNoRows {
+			return utils.NewNotFoundError("Transaction")
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if err := syncTransactionItems(ctx, tx, t); err != nil {
+			return err
+		}
+
+		// Put the previous quantities back before checking the new ones
+		if err := lockStockLedger(ctx, tx); err != nil {
+			return err
+		}
+
+		if err := reverseStockMovements(ctx, tx, models.StockSourceTransaction, t.ID, "Sale updated"); err != nil {
+			return err
+		}
+
+		if err := ensureTransactionStock(ctx, tx, t); err != nil {
+			return err
+		}
+
+		if err := postTransactionStock(ctx, tx, t); err != nil {
+			return err
+		}
+
+		return postTransactionJournal(ctx, tx, t)
+	})
 }
 
 func (s *TransactionStore) Delete(ctx context.Context, tID string) error {
@@ -367,20 +646,34 @@ func (s *TransactionStore) Delete(ctx context.Context, tID string) error {
 	ctx, cancel := context.WithTimeout(ctx, time.Second * 5)
 	defer cancel()
 
-	res, err := s.db.ExecContext(ctx, query, tID)
-	if err != nil {
-		return err
-	}
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, query, tID)
+		if isForeignKeyViolation(err) {
+			return utils.NewConflictError("Transaction has payments, an invoice or deliveries attached and cannot be deleted")
+		}
+		if err != nil {
+			return err
+		}
 
-	rows, err := res.RowsAffected()
-	if err != nil {
-		return err
-	}
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
 
-	if rows == 0 {
-		return utils.NewNotFoundError("Transaction")
-	}
-	return nil
+		if rows == 0 {
+			return utils.NewNotFoundError("Transaction")
+		}
+
+		if err := lockStockLedger(ctx, tx); err != nil {
+			return err
+		}
+
+		if err := reverseStockMovements(ctx, tx, models.StockSourceTransaction, tID, "Sale deleted"); err != nil {
+			return err
+		}
+
+		return reverseJournalEntries(ctx, tx, models.JournalSourceTransaction, tID, "Sale deleted")
+	})
 }
 
 func (s *TransactionStore) GetTotalWeeks(ctx context.Context) (int, error) {
@@ -398,4 +691,344 @@ func (s *TransactionStore) GetTotalWeeks(ctx context.Context) (int, error) {
     }
 
     return int(totalPages), nil
-}
\ No newline at end of file
+}
+
+func setPaymentStatus(t *models.Transaction) {
+	t.Balance = t.TotalPrice - t.PaidAmount
+
+	switch {
+	case t.PaidAmount <= 0:
+		t.PaymentStatus = models.PaymentUnpaid
+	case t.Balance > 0:
+		t.PaymentStatus = models.PaymentPartial
+	default:
+		t.PaymentStatus = models.PaymentPaid
+	}
+}
+
+// Let an overridden sale through a stock shortfall, keeping the reason as a warning
+func allowStockOverride(t *models.Transaction, err error) error {
+	var stockErr *utils.Error
+	if !t.OverrideStock || !errors.As(err, &stockErr) {
+		return err
+	}
+
+	t.Warnings = append(t.Warnings, stockErr.Message)
+	return nil
+}
+
+// Sales per product between start and end, from the product lines
+func getTransactionProductSummary(ctx context.Context, q querier, start, end time.Time) ([]models.ProductSummary, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT pr.id, pr.name, COUNT(DISTINCT t.id), SUM(i.quantity), SUM(i.line_total)
+		FROM transaction_items i
+		JOIN transactions t ON t.id = i.transaction_id
+		JOIN products pr ON pr.id = i.product_id
+		WHERE t.purchase_date BETWEEN $1 AND $2
+		GROUP BY pr.id, pr.name
+		ORDER BY pr.name ASC
+	`, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := []models.ProductSummary{}
+	for rows.Next() {
+		var ps models.ProductSummary
+		if err := rows.Scan(&ps.ProductID, &ps.ProductName, &ps.Count, &ps.Quantity, &ps.Revenue); err != nil {
+			return products, err
+		}
+		products = append(products, ps)
+	}
+
+	return products, rows.Err()
+}
+
+// Resolve and price every line, then total the sale. Product lines take their
+// unit price from the price list, unless priced holds the price the same line
+// was already sold at; charge lines keep the price they were given.
+func priceTransactionItems(ctx context.Context, q querier, t *models.Transaction, priced map[string]models.TransactionItem) error {
+	t.Quantity = 0
+	t.TotalPrice = 0
+
+	for i := range t.Items {
+		item := &t.Items[i]
+		item.LineNumber = i + 1
+		item.Description = strings.TrimSpace(item.Description)
+
+		if item.ProductID != "" || item.Description == "" {
+			product, err := resolveProduct(ctx, q, item.ProductID)
+			if err != nil {
+				return err
+			}
+			item.ProductID, item.Product = product.ID, product.Name
+
+			if stored, ok := priced[item.ID]; ok && stored.ProductID == item.ProductID {
+				item.UnitPrice = stored.UnitPrice
+			} else {
+				price, err := getEffectivePrice(ctx, q, item.ProductID, t.PurchaseDate)
+				if err != nil {
+					return err
+				}
+				item.UnitPrice = price.UnitPrice
+			}
+			t.Quantity += item.Quantity
+		}
+
+		gross := float64(item.Quantity) * item.UnitPrice
+		if item.Discount > gross {
+			return utils.NewBadRequestError(fmt.Sprintf("Discount on line %d is more than the line amount", item.LineNumber))
+		}
+		item.LineTotal = gross - item.Discount
+		t.TotalPrice += item.LineTotal
+	}
+
+	if t.Quantity == 0 {
+		return utils.NewBadRequestError("A sale needs at least one product line")
+	}
+	return nil
+}
+
+// Stored product lines of a sale keyed by line ID, as long as the purchase date
+// is unchanged. Moving the sale to another date reprices every line.
+func getPricedTransactionItems(ctx context.Context, q querier, t *models.Transaction) (map[string]models.TransactionItem, error) {
+	var sameDate bool
+	err := q.QueryRowContext(ctx, `
+		SELECT purchase_date = $2::date FROM transactions WHERE id = $1
+	`, t.ID, t.PurchaseDate).Scan(&sameDate)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("Transaction")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	priced := map[string]models.TransactionItem{}
+	if !sameDate {
+		return priced, nil
+	}
+
+	rows, err := q.QueryContext(ctx, `
+		SELECT id, product_id, unit_price
+		FROM transaction_items
+		WHERE transaction_id = $1 AND product_id IS NOT NULL
+	`, t.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item models.TransactionItem
+		if err := rows.Scan(&item.ID, &item.ProductID, &item.UnitPrice); err != nil {
+			return nil, err
+		}
+		priced[item.ID] = item
+	}
+
+	return priced, rows.Err()
+}
+
+// Check stock per product, summing lines that sell the same product
+func ensureTransactionStock(ctx context.Context, q querier, t *models.Transaction) error {
+	var productIDs []string
+	quantities := map[string]int{}
+	for _, item := range t.Items {
+		if item.ProductID == "" {
+			continue
+		}
+		if _, ok := quantities[item.ProductID]; !ok {
+			productIDs = append(productIDs, item.ProductID)
+		}
+		quantities[item.ProductID] += item.Quantity
+	}
+
+	for _, pID := range productIDs {
+		if err := ensureStockAvailable(ctx, q, pID, quantities[pID]); err != nil {
+			if err := allowStockOverride(t, err); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func postTransactionStock(ctx context.Context, q querier, t *models.Transaction) error {
+	for _, item := range t.Items {
+		if item.ProductID == "" {
+			continue
+		}
+
+		err := postStockMovement(ctx, q, &models.StockMovement{
+			ProductID: item.ProductID,
+			MovementDate: t.PurchaseDate,
+			SourceType: models.StockSourceTransaction,
+			SourceID: t.ID,
+			Quantity: -item.Quantity,
+			Note: "Sale to " + t.Customer,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func insertTransactionItem(ctx context.Context, q querier, tID string, item *models.TransactionItem) error {
+	item.ID = uuid.New().String()
+
+	_, err := q.ExecContext(ctx, `
+		INSERT INTO transaction_items (id, transaction_id, line_number, product_id, description, quantity, unit_price, discount, line_total)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7, $8, $9)
+	`, item.ID, tID, item.LineNumber, item.ProductID, item.Description, item.Quantity, item.UnitPrice, item.Discount, item.LineTotal)
+	return err
+}
+
+// Bring the stored lines in line with t.Items: lines with a known ID are
+// updated in place so their deliveries stay attached, lines without an ID
+// are added and lines left out are removed.
+func syncTransactionItems(ctx context.Context, q querier, t *models.Transaction) error {
+	rows, err := q.QueryContext(ctx, `
+		SELECT i.id, COALESCE(i.product_id, ''), COALESCE(SUM(d.quantity) FILTER (WHERE d.status <> 'failed'), 0)
+		FROM transaction_items i
+		LEFT JOIN deliveries d ON d.transaction_item_id = i.id
+		WHERE i.transaction_id = $1
+		GROUP BY i.id
+	`, t.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	type storedItem struct {
+		productID string
+		scheduled int
+	}
+	stored := map[string]storedItem{}
+	for rows.Next() {
+		var id string
+		var si storedItem
+		if err := rows.Scan(&id, &si.productID, &si.scheduled); err != nil {
+			return err
+		}
+		stored[id] = si
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	// Park the old line numbers out of the way while lines are renumbered
+	if _, err := q.ExecContext(ctx, `
+		UPDATE transaction_items SET line_number = -line_number WHERE transaction_id = $1
+	`, t.ID); err != nil {
+		return err
+	}
+
+	for i := range t.Items {
+		item := &t.Items[i]
+
+		if item.ID == "" {
+			if err := insertTransactionItem(ctx, q, t.ID, item); err != nil {
+				return err
+			}
+			continue
+		}
+
+		si, ok := stored[item.ID]
+		if !ok {
+			return utils.NewBadRequestError(fmt.Sprintf("Line %d does not belong to this transaction", item.LineNumber))
+		}
+		delete(stored, item.ID)
+
+		if si.scheduled > 0 && si.productID != item.ProductID {
+			return utils.NewBadRequestError(fmt.Sprintf("Line %d has deliveries, its product cannot be changed", item.LineNumber))
+		}
+
+		if item.Quantity < si.scheduled {
+			return utils.NewBadRequestError(fmt.Sprintf("Quantity on line %d cannot be lower than the %d already scheduled for delivery", item.LineNumber, si.scheduled))
+		}
+
+		_, err := q.ExecContext(ctx, `
+			UPDATE transaction_items
+			SET line_number = $2, product_id = NULLIF($3, ''), description = $4, quantity = $5, unit_price = $6, discount = $7, line_total = $8, updated_at = NOW()
+			WHERE id = $1
+		`, item.ID, item.LineNumber, item.ProductID, item.Description, item.Quantity, item.UnitPrice, item.Discount, item.LineTotal)
+		if err != nil {
+			return err
+		}
+	}
+
+	for id := range stored {
+		_, err := q.ExecContext(ctx, `DELETE FROM transaction_items WHERE id = $1`, id)
+		if isForeignKeyViolation(err) {
+			return utils.NewConflictError("A line with deliveries cannot be removed")
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Fill in the lines for a page of transactions with one query
+func loadTransactionItems(ctx context.Context, q querier, transactions []models.Transaction) error {
+	if len(transactions) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(transactions))
+	index := make(map[string]int, len(transactions))
+	for i := range transactions {
+		ids[i] = transactions[i].ID
+		index[transactions[i].ID] = i
+		transactions[i].Items = []models.TransactionItem{}
+	}
+
+	query := `
+		SELECT
+			i.id,
+			i.transaction_id,
+			i.line_number,
+			COALESCE(i.product_id, ''),
+			COALESCE(pr.name, ''),
+			i.description,
+			i.quantity,
+			i.unit_price,
+			i.discount,
+			i.line_total
+		FROM transaction_items i
+		LEFT JOIN products pr ON pr.id = i.product_id
+		WHERE i.transaction_id = ANY($1)
+		ORDER BY i.line_number ASC
+	`
+
+	rows, err := q.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item models.TransactionItem
+		var tID string
+		if err := rows.Scan(
+			&item.ID,
+			&tID,
+			&item.LineNumber,
+			&item.ProductID,
+			&item.Product,
+			&item.Description,
+			&item.Quantity,
+			&item.UnitPrice,
+			&item.Discount,
+			&item.LineTotal,
+		); err != nil {
+			return err
+		}
+		i := index[tID]
+		transactions[i].Items = append(transactions[i].Items, item)
+	}
+
+	return rows.Err()
+}
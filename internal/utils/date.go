@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"fmt"
 	"time"
 )
 func GetDayRange(now time.Time) (time.Time, time.Time) {
@@ -47,4 +48,28 @@ func GetMonthRange(now time.Time, monthOffset int) (time.Time, time.Time) {
     endOfMonth := time.Date(firstOfNextMonth.Year(), firstOfNextMonth.Month(), 0, 23, 59, 59, 0, now.Location())
 
 	return startOfMonth, endOfMonth
+}
+
+// Date range of a payroll period, either a month ("2026-10") or an ISO week ("2026-W42")
+func ParsePeriod(period string, loc *time.Location) (time.Time, time.Time, error) {
+	if month, err := time.ParseInLocation("2006-01", period, loc); err == nil {
+		start, end := GetMonthRange(month, 0)
+		return start, end, nil
+	}
+
+	var year, week int
+	if _, err := fmt.Sscanf(period, "%4d-W%2d", &year, &week); err != nil || len(period) != 8 {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid period %q", period)
+	}
+
+	// 4 January is always in week 1
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, loc)
+	day := jan4.AddDate(0, 0, (week - 1) * 7)
+
+	if y, w := day.ISOWeek(); y != year || w != week {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid period %q", period)
+	}
+
+	start, end := GetWeekRange(day, 0)
+	return start, end, nil
 }
\ No newline at end of file
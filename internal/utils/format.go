@@ -0,0 +1,28 @@
+package utils
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Format an amount the Indonesian way, e.g. 1250000 -> "Rp 1.250.000"
+func FormatRupiah(v float64) string {
+	sign := ""
+	if v < 0 {
+		sign = "-"
+		v = -v
+	}
+
+	digits := strconv.FormatInt(int64(math.Round(v)), 10)
+
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits) - i) % 3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(d)
+	}
+
+	return sign + "Rp " + b.String()
+}
\ No newline at end of file
@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"image"
+	"image/color"
+)
+
+// Scale img down so neither side exceeds maxSide, averaging the source pixels
+// each thumbnail pixel covers. Smaller images are returned as is.
+func Thumbnail(img image.Image, maxSide int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= maxSide && h <= maxSide {
+		return img
+	}
+
+	tw, th := maxSide, h * maxSide / w
+	if h > w {
+		tw, th = w * maxSide / h, maxSide
+	}
+	if tw < 1 {
+		tw = 1
+	}
+	if th < 1 {
+		th = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, tw, th))
+	for y := 0; y < th; y++ {
+		y0, y1 := b.Min.Y + y * h / th, b.Min.Y + (y + 1) * h / th
+		for x := 0; x < tw; x++ {
+			x0, x1 := b.Min.X + x * w / tw, b.Min.X + (x + 1) * w / tw
+
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := img.At(sx, sy).RGBA()
+					r, g, bl, a = r + uint64(cr), g + uint64(cg), bl + uint64(cb), a + uint64(ca)
+					n++
+				}
+			}
+			dst.Set(x, y, color.RGBA64{uint16(r / n), uint16(g / n), uint16(bl / n), uint16(a / n)})
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,13 @@
+package utils
+
+import "strings"
+
+// Collapse repeated and surrounding whitespace, e.g. "  Pak   Budi " -> "Pak Budi"
+func CleanName(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// Key used to match the same name regardless of case and spacing
+func NormalizeName(s string) string {
+	return strings.ToLower(CleanName(s))
+}
\ No newline at end of file